@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"syl-listing/internal/app"
@@ -19,6 +22,13 @@ type genFlags struct {
 	providerArg    string
 	logFileArg     string
 	verboseArg     bool
+	cacheArg       string
+	recordArg      string
+	replayArg      string
+	replayMatchArg string
+	dryRunArg      bool
+	bestArg        string
+	baseArg        string
 }
 
 var loadConfigForUpdate = config.Load
@@ -133,6 +143,179 @@ func NewRootCmd(stdout, stderr *os.File) *cobra.Command {
 	}
 	updateCmd.AddCommand(updateRulesCmd)
 	root.AddCommand(updateCmd)
+
+	composeFlags := &genFlags{}
+	composeCmd := &cobra.Command{
+		Use:           "compose <需求文件> [listing_xxx_en.md ...]",
+		Short:         "组合已有候选中各分段得分最高的版本",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("读取当前目录失败：%w", err)
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return app.Compose(app.ComposeOptions{
+				Context:     ctx,
+				Requirement: args[0],
+				Candidates:  args[1:],
+				ConfigPath:  composeFlags.configArg,
+				OutputDir:   composeFlags.outputDirArg,
+				Provider:    composeFlags.providerArg,
+				MaxRetries:  composeFlags.maxRetriesArg,
+				Cache:       composeFlags.cacheArg,
+				LogFile:     composeFlags.logFileArg,
+				Verbose:     composeFlags.verboseArg,
+				CWD:         cwd,
+				Stdout:      stdout,
+			})
+		},
+	}
+	composeCmd.Flags().StringVar(&composeFlags.configArg, "config", "", "配置文件路径，默认 ~/.syl-listing/config.yaml")
+	composeCmd.Flags().StringVarP(&composeFlags.outputDirArg, "out", "o", "", "候选所在及组合结果输出目录，默认读取配置 output.dir")
+	composeCmd.Flags().StringVar(&composeFlags.providerArg, "provider", "", "覆盖配置中的 provider（deepseek；mock 为离线占位输出）")
+	composeCmd.Flags().IntVar(&composeFlags.maxRetriesArg, "max-retries", 0, "最大重试次数")
+	composeCmd.Flags().StringVar(&composeFlags.cacheArg, "cache", "", "响应缓存模式：off|read|write|readwrite，默认读取配置 cache.mode")
+	composeCmd.Flags().StringVar(&composeFlags.logFileArg, "log-file", "", "NDJSON 日志文件路径")
+	composeCmd.Flags().BoolVar(&composeFlags.verboseArg, "verbose", false, "输出详细 NDJSON（机器友好）")
+	root.AddCommand(composeCmd)
+
+	regenFlags := &genFlags{}
+	regenReq, regenSection, regenNote := "", "", ""
+	regenCmd := &cobra.Command{
+		Use:           "regen <listing_xxx_en.md> --section <分段[:N]> --req <需求文件>",
+		Short:         "只重新生成已有输出中的一个分段或一条，原地改写中英文件（原文件备份为 .bak）",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("读取当前目录失败：%w", err)
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return app.Regen(app.RegenOptions{
+				ListingFileOptions: regenFlags.listingFileOptions(ctx, args[0], regenReq, cwd, stdout),
+				Section:            regenSection,
+				Note:               regenNote,
+			})
+		},
+	}
+	regenCmd.Flags().StringVar(&regenSection, "section", "", "要重新生成的分段，可带条目序号：description、bullets:3")
+	regenCmd.Flags().StringVar(&regenNote, "note", "", "附加要求，注入重新生成的提示词")
+	addListingFileFlags(regenCmd, regenFlags, &regenReq)
+	_ = regenCmd.MarkFlagRequired("section")
+	root.AddCommand(regenCmd)
+
+	inspectFlags := &genFlags{}
+	inspectReq := ""
+	inspectCmd := &cobra.Command{
+		Use:           "inspect <listing_xxx_en.md> --req <需求文件>",
+		Short:         "在终端逐段审阅已有输出：接受、附加要求重新生成或手工编辑，确认后改写中英文件（原文件备份为 .bak）",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("读取当前目录失败：%w", err)
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return app.Inspect(app.InspectOptions{
+				ListingFileOptions: inspectFlags.listingFileOptions(ctx, args[0], inspectReq, cwd, stdout),
+				Stdin:              cmd.InOrStdin(),
+			})
+		},
+	}
+	addListingFileFlags(inspectCmd, inspectFlags, &inspectReq)
+	root.AddCommand(inspectCmd)
+
+	tmCmd := &cobra.Command{
+		Use:           "tm",
+		Short:         "管理本地翻译记忆",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	tmFormat := ""
+	newTMCmd := func(use, short string, run func(app.TMOptions) error) *cobra.Command {
+		c := &cobra.Command{
+			Use:           use,
+			Short:         short,
+			Args:          cobra.ExactArgs(1),
+			SilenceUsage:  true,
+			SilenceErrors: true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return fmt.Errorf("读取当前目录失败：%w", err)
+				}
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				return run(app.TMOptions{Context: ctx, ConfigPath: flags.configArg, File: args[0], Format: tmFormat, CWD: cwd, Stdout: stdout})
+			},
+		}
+		c.Flags().StringVar(&flags.configArg, "config", "", "配置文件路径，默认 ~/.syl-listing/config.yaml")
+		c.Flags().StringVar(&tmFormat, "format", "", "交换格式：tmx|csv，默认按文件扩展名判断")
+		return c
+	}
+	tmCmd.AddCommand(newTMCmd("export <file.tmx|file.csv>", "导出翻译记忆，供团队共享", app.ExportTM))
+	tmCmd.AddCommand(newTMCmd("import <file.tmx|file.csv>", "导入翻译记忆，同一原文以导入的译文为准", app.ImportTM))
+	root.AddCommand(tmCmd)
+
+	libraryCmd := &cobra.Command{
+		Use:           "library",
+		Short:         "管理已审核范例库（生成时按分类与关键词选取范例写进提示词）",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	libraryBrand := ""
+	libraryAddCmd := &cobra.Command{
+		Use:           "add <listing_xxx_en.md>",
+		Short:         "把已审核的英文输出加入范例库",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("读取当前目录失败：%w", err)
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return app.AddToLibrary(app.LibraryOptions{Context: ctx, ConfigPath: flags.configArg, File: args[0], Brand: libraryBrand, CWD: cwd, Stdout: stdout})
+		},
+	}
+	libraryAddCmd.Flags().StringVar(&flags.configArg, "config", "", "配置文件路径，默认 ~/.syl-listing/config.yaml")
+	libraryAddCmd.Flags().StringVar(&libraryBrand, "brand", "", "品牌名，默认读取文件一级标题")
+	libraryCmd.AddCommand(libraryAddCmd)
+	root.AddCommand(libraryCmd)
+
+	lintFix := false
+	lintCmd := &cobra.Command{
+		Use:           "lint <file_or_dir...>",
+		Short:         "检查需求文件（首行标志、必填字段、关键词、占位符、单位），按 file:line 输出问题",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("读取当前目录失败：%w", err)
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			_, err = app.Lint(app.LintOptions{Context: ctx, Inputs: args, ConfigPath: flags.configArg, Fix: lintFix, CWD: cwd, Stdout: stdout})
+			return err
+		},
+	}
+	lintCmd.Flags().StringVar(&flags.configArg, "config", "", "配置文件路径，默认 ~/.syl-listing/config.yaml")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "自动修复机械性问题（首行标志、全角冒号、重复关键词）并写回文件")
+	root.AddCommand(lintCmd)
 	return root
 }
 
@@ -142,9 +325,16 @@ func bindGenFlags(cmd *cobra.Command, flags *genFlags) {
 	cmd.Flags().IntVarP(&flags.numArg, "num", "n", 0, "每个需求文件生成候选数量")
 	cmd.Flags().IntVar(&flags.concurrencyArg, "concurrency", 0, "保留参数（当前版本不限制并发）")
 	cmd.Flags().IntVar(&flags.maxRetriesArg, "max-retries", 0, "最大重试次数")
-	cmd.Flags().StringVar(&flags.providerArg, "provider", "", "覆盖配置中的 provider（当前仅支持 deepseek）")
+	cmd.Flags().StringVar(&flags.providerArg, "provider", "", "覆盖配置中的 provider（deepseek；mock 为离线占位输出）")
 	cmd.Flags().StringVar(&flags.logFileArg, "log-file", "", "NDJSON 日志文件路径")
 	cmd.Flags().BoolVar(&flags.verboseArg, "verbose", false, "输出详细 NDJSON（机器友好）")
+	cmd.Flags().StringVar(&flags.cacheArg, "cache", "", "响应缓存模式：off|read|write|readwrite，默认读取配置 cache.mode")
+	cmd.Flags().StringVar(&flags.recordArg, "record", "", "把本次运行的全部 HTTP 往返录制到指定 JSON 文件")
+	cmd.Flags().StringVar(&flags.replayArg, "replay", "", "从录像文件回放 HTTP 响应，不访问网络")
+	cmd.Flags().BoolVar(&flags.dryRunArg, "dry-run", false, "按配置的 provider 构造提示词、由内置 mock 应答，离线跑完整流程并导出每个分段的 system/user 提示词，不同步规则中心，不写 listing 文件")
+	cmd.Flags().StringVar(&flags.replayMatchArg, "replay-match", "", "回放匹配方式：request（默认，按请求内容）|order（按录制顺序）")
+	cmd.Flags().StringVar(&flags.bestArg, "best", "", "多候选时把最高分候选输出为 <需求名>_best_en.md/_best_cn.md：copy|symlink|off，默认读取配置 output.best")
+	cmd.Flags().StringVar(&flags.baseArg, "base", "", "编辑模式：以现有 listing_*_en.md 为基准逐段最小改动修订，并输出 listing_<id>_diff.md 对比")
 }
 
 func runGen(stdout, stderr *os.File, flags *genFlags, subcommand bool, showVersion *bool) func(*cobra.Command, []string) error {
@@ -164,7 +354,11 @@ func runGen(stdout, stderr *os.File, flags *genFlags, subcommand bool, showVersi
 			return fmt.Errorf("读取当前目录失败：%w", err)
 		}
 
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		res, err := app.Run(app.Options{
+			Context:         ctx,
 			Inputs:          args,
 			ConfigPath:      flags.configArg,
 			OutputDir:       flags.outputDirArg,
@@ -174,25 +368,26 @@ func runGen(stdout, stderr *os.File, flags *genFlags, subcommand bool, showVersi
 			Provider:        flags.providerArg,
 			LogFile:         flags.logFileArg,
 			Verbose:         flags.verboseArg,
+			Cache:           flags.cacheArg,
+			Record:          flags.recordArg,
+			Replay:          flags.replayArg,
+			ReplayMatch:     flags.replayMatchArg,
+			DryRun:          flags.dryRunArg,
+			Best:            flags.bestArg,
 			CWD:             cwd,
 			Stdout:          stdout,
 			Stderr:          stderr,
 			Stdin:           os.Stdin,
 			InvokedSubcmd:   subcommand,
 			NormalizedInput: normalizeArgs(os.Args[1:]),
+			Base:            flags.baseArg,
 		})
 		if err != nil {
 			return err
 		}
 
-		finalLine := fmt.Sprintf(
-			"任务完成：成功 %d，失败 %d，总耗时 %s，余额：%s",
-			res.Succeeded,
-			res.Failed,
-			formatDurationMS(res.ElapsedMS),
-			formatSummaryBalance(res.Balance),
-		)
-		if res.Failed > 0 {
+		finalLine := formatSummaryLine(res)
+		if res.Failed > 0 || res.Cancelled > 0 {
 			return fmt.Errorf(finalLine)
 		}
 		if !flags.verboseArg {
@@ -202,6 +397,21 @@ func runGen(stdout, stderr *os.File, flags *genFlags, subcommand bool, showVersi
 	}
 }
 
+func formatSummaryLine(res app.Result) string {
+	cancelled := ""
+	if res.Cancelled > 0 {
+		cancelled = fmt.Sprintf("，取消 %d", res.Cancelled)
+	}
+	return fmt.Sprintf(
+		"任务完成：成功 %d，失败 %d%s，总耗时 %s，余额：%s",
+		res.Succeeded,
+		res.Failed,
+		cancelled,
+		formatDurationMS(res.ElapsedMS),
+		formatSummaryBalance(res.Balance),
+	)
+}
+
 func formatDurationMS(ms int64) string {
 	if ms < 0 {
 		ms = 0
@@ -233,7 +443,7 @@ func normalizeArgs(args []string) []string {
 	}
 	first := args[0]
 	switch first {
-	case "gen", "help", "completion", "version", "set", "update":
+	case "gen", "help", "completion", "version", "set", "update", "compose", "regen", "inspect", "tm", "library", "lint":
 		return args
 	}
 	if first == "-h" || first == "--help" || first == "-v" || first == "--version" {
@@ -293,17 +503,38 @@ func extractRulesTag(msg string) string {
 	return ""
 }
 
+// valueFlags 是需要携带取值的生成参数，判断位置参数时需跳过其取值。
+var valueFlags = []string{"--config", "--out", "-o", "--num", "-n", "--concurrency", "--max-retries", "--provider", "--log-file", "--cache", "--record", "--replay", "--replay-match", "--best", "--base"}
+
+func isValueFlag(arg string) bool {
+	for _, f := range valueFlags {
+		if arg == f {
+			return true
+		}
+	}
+	return false
+}
+
+func hasValueFlagPrefix(arg string) bool {
+	for _, f := range valueFlags {
+		if strings.HasPrefix(f, "--") && strings.HasPrefix(arg, f+"=") {
+			return true
+		}
+	}
+	return false
+}
+
 func containsPositionalSource(args []string) bool {
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		if arg == "--" {
 			return i+1 < len(args)
 		}
-		if arg == "--config" || arg == "--out" || arg == "-o" || arg == "--num" || arg == "-n" || arg == "--concurrency" || arg == "--max-retries" || arg == "--provider" || arg == "--log-file" {
+		if isValueFlag(arg) {
 			i++
 			continue
 		}
-		if strings.HasPrefix(arg, "--config=") || strings.HasPrefix(arg, "--out=") || strings.HasPrefix(arg, "--num=") || strings.HasPrefix(arg, "--concurrency=") || strings.HasPrefix(arg, "--max-retries=") || strings.HasPrefix(arg, "--provider=") || strings.HasPrefix(arg, "--log-file=") {
+		if hasValueFlagPrefix(arg) {
 			continue
 		}
 		if strings.HasPrefix(arg, "-") {
@@ -313,3 +544,31 @@ func containsPositionalSource(args []string) bool {
 	}
 	return false
 }
+
+// addListingFileFlags 注册 regen、inspect 等改写已有输出的子命令共用的参数。
+func addListingFileFlags(cmd *cobra.Command, f *genFlags, req *string) {
+	cmd.Flags().StringVar(req, "req", "", "对应的需求文件")
+	cmd.Flags().StringVar(&f.configArg, "config", "", "配置文件路径，默认 ~/.syl-listing/config.yaml")
+	cmd.Flags().StringVar(&f.providerArg, "provider", "", "覆盖配置中的 provider（deepseek；mock 为离线占位输出）")
+	cmd.Flags().IntVar(&f.maxRetriesArg, "max-retries", 0, "最大重试次数")
+	cmd.Flags().StringVar(&f.cacheArg, "cache", "", "响应缓存模式：off|read|write|readwrite，默认读取配置 cache.mode")
+	cmd.Flags().StringVar(&f.logFileArg, "log-file", "", "NDJSON 日志文件路径")
+	cmd.Flags().BoolVar(&f.verboseArg, "verbose", false, "输出详细 NDJSON（机器友好）")
+	_ = cmd.MarkFlagRequired("req")
+}
+
+func (f *genFlags) listingFileOptions(ctx context.Context, listingPath, req, cwd string, stdout *os.File) app.ListingFileOptions {
+	return app.ListingFileOptions{
+		Context:     ctx,
+		Listing:     listingPath,
+		Requirement: req,
+		ConfigPath:  f.configArg,
+		Provider:    f.providerArg,
+		MaxRetries:  f.maxRetriesArg,
+		Cache:       f.cacheArg,
+		LogFile:     f.logFileArg,
+		Verbose:     f.verboseArg,
+		CWD:         cwd,
+		Stdout:      stdout,
+	}
+}
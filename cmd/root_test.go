@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"syl-listing/internal/app"
 )
 
 func TestFormatDurationMS(t *testing.T) {
@@ -29,6 +31,17 @@ func TestFormatSummaryBalance(t *testing.T) {
 	}
 }
 
+func TestFormatSummaryLineCancelled(t *testing.T) {
+	got := formatSummaryLine(app.Result{Succeeded: 1, Failed: 0, ElapsedMS: 500, Balance: "1 元"})
+	if got != "任务完成：成功 1，失败 0，总耗时 500ms，余额：1 元" {
+		t.Fatalf("unexpected: %s", got)
+	}
+	got = formatSummaryLine(app.Result{Succeeded: 1, Failed: 1, Cancelled: 2, ElapsedMS: 500, Balance: "1 元"})
+	if !strings.Contains(got, "失败 1，取消 2，") {
+		t.Fatalf("unexpected: %s", got)
+	}
+}
+
 func TestExtractRulesTag(t *testing.T) {
 	cases := map[string]string{
 		"规则中心更新成功（rules-v2026.02.25-2）": "rules-v2026.02.25-2",
@@ -61,6 +74,24 @@ func TestNormalizeArgs(t *testing.T) {
 	if got := normalizeArgs([]string{"update", "rules"}); !reflect.DeepEqual(got, []string{"update", "rules"}) {
 		t.Fatalf("unexpected: %#v", got)
 	}
+	if got := normalizeArgs([]string{"compose", "a.md"}); !reflect.DeepEqual(got, []string{"compose", "a.md"}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+	if got := normalizeArgs([]string{"regen", "listing_a_en.md", "--section", "bullets:3"}); !reflect.DeepEqual(got, []string{"regen", "listing_a_en.md", "--section", "bullets:3"}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+	if got := normalizeArgs([]string{"inspect", "listing_a_en.md", "--req", "a.md"}); !reflect.DeepEqual(got, []string{"inspect", "listing_a_en.md", "--req", "a.md"}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+	if got := normalizeArgs([]string{"library", "add", "listing_a_en.md"}); !reflect.DeepEqual(got, []string{"library", "add", "listing_a_en.md"}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+	if got := normalizeArgs([]string{"tm", "export", "team.tmx"}); !reflect.DeepEqual(got, []string{"tm", "export", "team.tmx"}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
+	if got := normalizeArgs([]string{"lint", "reqs", "--fix"}); !reflect.DeepEqual(got, []string{"lint", "reqs", "--fix"}) {
+		t.Fatalf("unexpected: %#v", got)
+	}
 }
 
 func TestContainsPositionalSource(t *testing.T) {
@@ -73,6 +104,9 @@ func TestContainsPositionalSource(t *testing.T) {
 	if !containsPositionalSource([]string{"--", "a.md"}) {
 		t.Fatalf("expected true")
 	}
+	if containsPositionalSource([]string{"--cache", "readwrite"}) {
+		t.Fatalf("cache value should not count as source")
+	}
 }
 
 func TestVersionText(t *testing.T) {
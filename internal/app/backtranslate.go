@@ -0,0 +1,262 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/textsim"
+	"syl-listing/internal/translator"
+)
+
+// translationQASettings 是一次运行的回译质检设置；nil 表示关闭。
+type translationQASettings struct {
+	MinScore          float64
+	MaxRetranslations int
+}
+
+func newTranslationQASettings(cfg config.TranslationQAConfig) *translationQASettings {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &translationQASettings{MinScore: cfg.MinScore, MaxRetranslations: cfg.MaxRetranslations}
+}
+
+// translationQAReport 是回译质检结果，写入 listing_<id>_qa.json。
+type translationQAReport struct {
+	MinScore float64     `json:"min_score"`
+	Overall  float64     `json:"overall"`
+	Sections []sectionQA `json:"sections"`
+}
+
+// sectionQA 的 Score 取分段内最低的条目得分：任一条漏译都会拉低整段。
+// Failed 表示回译调用失败（Score 记为 0）或重新翻译后的中文未通过校验（保留原译文及其得分），原因见 Issue。
+type sectionQA struct {
+	Section string   `json:"section"`
+	Score   float64  `json:"score"`
+	Items   []itemQA `json:"items"`
+	Failed  bool     `json:"failed,omitempty"`
+	Issue   string   `json:"issue,omitempty"`
+}
+
+type itemQA struct {
+	Name            string  `json:"name"`
+	BackTranslation string  `json:"back_translation"`
+	Similarity      float64 `json:"similarity"`
+	// Keywords 与 Numbers 为英文原文中的关键词、数值在回译中保留的比例；原文没有时记为 1，不计入得分。
+	Keywords float64  `json:"keywords"`
+	Numbers  float64  `json:"numbers"`
+	Score    float64  `json:"score"`
+	Issues   []string `json:"issues,omitempty"`
+	// Retranslated 表示该条已带差异重新翻译，分数为重新翻译后的质检结果。
+	Retranslated bool `json:"retranslated,omitempty"`
+}
+
+func (r *translationQAReport) summary() string {
+	var low, failed []string
+	for _, s := range r.Sections {
+		if s.Failed {
+			failed = append(failed, s.Section)
+		}
+		if s.Score < r.MinScore {
+			low = append(low, s.Section)
+		}
+	}
+	msg := fmt.Sprintf("总分 %.2f（及格线 %.2f）", r.Overall, r.MinScore)
+	if len(low) > 0 {
+		msg += "；低分分段：" + strings.Join(low, "、")
+	}
+	if len(failed) > 0 {
+		msg += "；质检失败分段：" + strings.Join(failed, "、")
+	}
+	return msg
+}
+
+// scoreBackTranslation 比较英文原文与回译：词相似度占一半权重，关键词与数值保留率各占四分之一。
+func scoreBackTranslation(req listing.Requirement, en, back string) itemQA {
+	q := itemQA{BackTranslation: back, Similarity: round2(textsim.NgramJaccard(en, back, 1)), Keywords: 1, Numbers: 1}
+	sum, weights := 2*q.Similarity, 2.0
+
+	enLower, backLower := strings.ToLower(en), strings.ToLower(back)
+	var total int
+	var missing []string
+	for _, kw := range req.Keywords {
+		k := strings.ToLower(strings.TrimSpace(kw))
+		if k == "" || !strings.Contains(enLower, k) {
+			continue
+		}
+		total++
+		if !strings.Contains(backLower, k) {
+			missing = append(missing, strings.TrimSpace(kw))
+		}
+	}
+	if total > 0 {
+		q.Keywords = round2(float64(total-len(missing)) / float64(total))
+		sum, weights = sum+q.Keywords, weights+1
+		if len(missing) > 0 {
+			q.Issues = append(q.Issues, "回译缺少关键词："+strings.Join(missing, "、"))
+		}
+	}
+
+	backFacts := &factSheet{facts: extractNumericFacts(back)}
+	seen := map[string]bool{}
+	total, missing = 0, nil
+	for _, f := range extractNumericFacts(en) {
+		if seen[f.label()] {
+			continue
+		}
+		seen[f.label()] = true
+		total++
+		if !backFacts.known(f) {
+			missing = append(missing, f.label())
+		}
+	}
+	if total > 0 {
+		q.Numbers = round2(float64(total-len(missing)) / float64(total))
+		sum, weights = sum+q.Numbers, weights+1
+		if len(missing) > 0 {
+			q.Issues = append(q.Issues, "回译缺少数值："+strings.Join(missing, "、"))
+		}
+	}
+	q.Score = round2(sum / weights)
+	return q
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// buildRetranslateInstruction 把回译差异写成重新翻译的附加要求。
+func buildRetranslateInstruction(prev string, q itemQA) string {
+	var b strings.Builder
+	b.WriteString("上一版译文回译为英文后与原文不一致，请重新翻译，完整保留原文的全部信息、数值与关键词含义，不要增删或改写。\n")
+	b.WriteString("上一版译文：")
+	b.WriteString(prev)
+	b.WriteString("\n上一版回译：")
+	b.WriteString(q.BackTranslation)
+	b.WriteString("\n差异：\n")
+	for _, issue := range q.Issues {
+		b.WriteString("- ")
+		b.WriteString(issue)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// checkBackTranslation 把中文各分段逐条回译为英文并与英文原文比较；低分条目带差异重新翻译，
+// 最多 MaxRetranslations 次，取得分更高的版本。翻译 provider 不支持附加要求时只报告不重译。
+// 回译失败只告警，保留原译文。
+func checkBackTranslation(opts bilingualGenerateOptions, settings *translationQASettings, enDoc, cnDoc ListingDocument) (ListingDocument, *translationQAReport) {
+	if settings == nil {
+		return cnDoc, nil
+	}
+	base := opts.translateSectionOptions()
+	provider := strings.TrimSpace(base.Provider)
+	if provider == "" {
+		provider = "deepseek"
+	}
+	canRetranslate := translator.SupportsInstruction(provider)
+	report := &translationQAReport{MinScore: settings.MinScore}
+
+	score := func(en, cn, name string) (itemQA, error) {
+		topts := base
+		topts.Section, topts.SourceText, topts.Target = "back_"+name, cn, "en"
+		back, _, err := translateSectionWithRetry(topts)
+		if err != nil {
+			return itemQA{}, err
+		}
+		q := scoreBackTranslation(opts.Req, en, strings.TrimSpace(back))
+		q.Name = name
+		if q.Score < settings.MinScore {
+			q.Issues = append(q.Issues, fmt.Sprintf("回译与原文词相似度 %.2f", q.Similarity))
+		}
+		return q, nil
+	}
+
+	var total float64
+	for _, sec := range opts.Rules.Sections() {
+		enItems, cnItems := enDoc.SectionItems(sec.Name), cnDoc.SectionItems(sec.Name)
+		if _, native := opts.cnNativeRule(sec.Name); native || len(enItems) == 0 || len(enItems) != len(cnItems) {
+			// 中文按原生规则生成的分段不是译文，不做回译比较。
+			continue
+		}
+		clean := translatedCleaner(sec.Name)
+		out := append([]string{}, cnItems...)
+		sq := sectionQA{Section: sec.Name, Score: 1}
+		// orig 记录各条原译文的质检结果，重新翻译的版本未通过校验时回退到它。
+		var orig []itemQA
+		for i, en := range enItems {
+			name := translateSectionName(sec, i, len(enItems))
+			q, err := score(en, out[i], name)
+			if err != nil {
+				opts.Logger.Emit(logging.Event{Level: "warn", Event: "translation_qa_failed", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Error: fmt.Sprintf("%s：%s", name, err.Error())})
+				sq.Failed, sq.Issue, sq.Score = true, fmt.Sprintf("%s 回译失败：%s", name, err.Error()), 0
+				break
+			}
+			orig = append(orig, q)
+			for attempt := 1; canRetranslate && q.Score < settings.MinScore && attempt <= settings.MaxRetranslations; attempt++ {
+				opts.Logger.Emit(logging.Event{Level: "warn", Event: "translation_qa_low", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Attempt: attempt, Error: fmt.Sprintf("%s 得分 %.2f：%s", name, q.Score, strings.Join(q.Issues, "；"))})
+				topts := base
+				topts.Section, topts.SourceText = name, en
+				topts.Instruction = buildRetranslateInstruction(out[i], q)
+				translated, _, err := translateSectionWithRetry(topts)
+				if err != nil {
+					break
+				}
+				next := clean(strings.TrimSpace(translated))
+				if next == "" {
+					break
+				}
+				nq, err := score(en, next, name)
+				if err != nil || nq.Score <= q.Score {
+					break
+				}
+				out[i], q = next, nq
+				q.Retranslated = true
+			}
+			if !canRetranslate && q.Score < settings.MinScore {
+				opts.Logger.Emit(logging.Event{Level: "warn", Event: "translation_qa_low", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Error: fmt.Sprintf("%s 得分 %.2f：%s（翻译 provider %s 不支持附加要求，不重新翻译）", name, q.Score, strings.Join(q.Issues, "；"), provider)})
+			}
+			sq.Items = append(sq.Items, q)
+			sq.Score = math.Min(sq.Score, q.Score)
+		}
+		if sq.Failed {
+			report.Sections = append(report.Sections, sq)
+			continue
+		}
+		next := cnDoc
+		next.Extra = append([]SectionContent{}, cnDoc.Extra...)
+		next.setSection(sec, "cn", out)
+		err := validateDocumentBySectionRules("cn", opts.Req, next, opts.Rules)
+		if rule, ok := opts.Rules.CNRule(sec.Name); ok && err == nil {
+			if issues, _ := validateCNItems(sec, out, rule, opts.CharTolerance); len(issues) > 0 {
+				err = fmt.Errorf("中文规则校验失败：%s", strings.Join(issues, "；"))
+			}
+		}
+		if err != nil {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "translation_qa_failed", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Error: fmt.Sprintf("%s：%s", sec.Name, err.Error())})
+			sq.Failed, sq.Issue, sq.Items, sq.Score = true, err.Error(), orig, 1
+			for _, q := range orig {
+				sq.Score = math.Min(sq.Score, q.Score)
+			}
+		} else {
+			cnDoc = next
+		}
+		report.Sections = append(report.Sections, sq)
+		total += sq.Score
+		opts.Logger.Emit(logging.Event{Event: "translation_qa", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Error: fmt.Sprintf("%s %.2f", sec.Name, sq.Score)})
+	}
+	if len(report.Sections) > 0 {
+		report.Overall = round2(total / float64(len(report.Sections)))
+	}
+	opts.Logger.Emit(logging.Event{Event: "translation_qa_done", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Error: report.summary()})
+	return cnDoc, report
+}
+
+// translationQASidecarPath 返回回译质检结果文件路径：listing_<id>_qa.json。
+func translationQASidecarPath(enPath string) string {
+	return strings.TrimSuffix(enPath, "_en.md") + "_qa.json"
+}
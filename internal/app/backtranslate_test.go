@@ -0,0 +1,154 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/translator"
+)
+
+func TestScoreBackTranslation(t *testing.T) {
+	req := listing.Requirement{Keywords: []string{"alpha", "beta", "gamma"}}
+	en := "Alpha beta organizer, pack of 12 pens"
+	good := scoreBackTranslation(req, en, "Alpha Beta organizer in a pack of 12 pens")
+	if good.Keywords != 1 || good.Numbers != 1 || len(good.Issues) != 0 || good.Score < 0.8 {
+		t.Fatalf("faithful back translation should score high: %+v", good)
+	}
+	bad := scoreBackTranslation(req, en, "alpha organizer for pens")
+	if bad.Keywords != 0.5 || bad.Numbers != 0 || bad.Score >= 0.6 {
+		t.Fatalf("dropped keyword and number should score low: %+v", bad)
+	}
+	if strings.Join(bad.Issues, "；") != "回译缺少关键词：beta；回译缺少数值：12 pcs" {
+		t.Fatalf("unexpected issues: %v", bad.Issues)
+	}
+	plain := scoreBackTranslation(req, "Simple tidy box", "Simple tidy box")
+	if plain.Keywords != 1 || plain.Numbers != 1 || plain.Score != 1 {
+		t.Fatalf("missing references should not count: %+v", plain)
+	}
+}
+
+func TestCheckBackTranslationRetranslatesLowItems(t *testing.T) {
+	var instructions int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		system, user := body.Messages[0].Content, body.Messages[1].Content
+		var text string
+		switch {
+		case strings.Contains(system, "从 zh 翻译到 en"):
+			text = "unrelated words"
+			if strings.HasPrefix(user, "译：") {
+				text = strings.TrimPrefix(user, "译：")
+			}
+		default:
+			if strings.Contains(system, "上一版回译：unrelated words") {
+				instructions++
+			}
+			text = "译：" + user
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger, _, err := logging.New(&buf, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	en, cn := composeFixtureDocs()
+	opts := bilingualGenerateOptions{
+		Req:                  listing.Requirement{SourcePath: "/in/demo.md", Keywords: []string{"alpha", "beta"}},
+		TranslateProvider:    "deepseek",
+		TranslateProviderCfg: config.ProviderConfig{BaseURL: ts.URL},
+		APIKey:               "k",
+		Rules:                testRules(),
+		TranslateClient:      translator.NewClient(10 * time.Second),
+		Logger:               logger,
+		Candidate:            1,
+	}
+	settings := newTranslationQASettings(config.TranslationQAConfig{Enabled: true, MinScore: 0.6, MaxRetranslations: 1})
+	gotCN, report := checkBackTranslation(opts, settings, en, cn)
+	if report == nil || report.Overall != 1 || len(report.Sections) != 4 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if instructions != 9 {
+		t.Fatalf("every low item should be retranslated with the discrepancy, got %d", instructions)
+	}
+	if gotCN.Title != "译："+en.Title || gotCN.BulletPoints[4] != "译："+en.BulletPoints[4] || !report.Sections[1].Items[0].Retranslated {
+		t.Fatalf("retranslated text should replace low items: %+v", gotCN)
+	}
+	if !strings.Contains(buf.String(), "回译质检低分：title 得分") {
+		t.Fatalf("missing low score log: %s", buf.String())
+	}
+	if translationQASidecarPath("/out/listing_ab_en.md") != "/out/listing_ab_qa.json" {
+		t.Fatalf("unexpected sidecar path")
+	}
+
+	opts.TranslateProvider = "mock"
+	gotCN, report = checkBackTranslation(opts, settings, en, cn)
+	if gotCN.Title != cn.Title || report.Sections[0].Score >= 0.6 || report.Sections[0].Items[0].Retranslated {
+		t.Fatalf("providers without instructions should only report: %+v %+v", gotCN, report)
+	}
+	if _, report = checkBackTranslation(opts, nil, en, cn); report != nil {
+		t.Fatalf("nil settings should skip qa")
+	}
+}
+
+func TestCheckBackTranslationKeepsFailedSections(t *testing.T) {
+	en, cn := composeFixtureDocs()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		user := body.Messages[1].Content
+		if user == cn.Title {
+			http.Error(w, "boom", http.StatusBadRequest)
+			return
+		}
+		text := strings.TrimSpace(map[string]string{"一": en.BulletPoints[0], "二": en.BulletPoints[1], "三": en.BulletPoints[2], "四": en.BulletPoints[3], "五": en.BulletPoints[4], "第一段。": en.DescriptionParagraphs[0], "第二段。": en.DescriptionParagraphs[1], cn.SearchTerms: en.SearchTerms}[user])
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+
+	logger, _, err := logging.New(&bytes.Buffer{}, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := bilingualGenerateOptions{
+		Req:                  listing.Requirement{SourcePath: "/in/demo.md", Keywords: []string{"alpha", "beta"}},
+		TranslateProvider:    "deepseek",
+		TranslateProviderCfg: config.ProviderConfig{BaseURL: ts.URL},
+		APIKey:               "k",
+		Rules:                testRules(),
+		TranslateClient:      translator.NewClient(10 * time.Second),
+		Logger:               logger,
+		Candidate:            1,
+	}
+	settings := newTranslationQASettings(config.TranslationQAConfig{Enabled: true, MinScore: 0.6})
+	gotCN, report := checkBackTranslation(opts, settings, en, cn)
+	if len(report.Sections) != 4 || report.Sections[0].Section != "title" || !report.Sections[0].Failed || report.Sections[0].Score != 0 {
+		t.Fatalf("failed title should stay in the report: %+v", report)
+	}
+	if !strings.Contains(report.Sections[0].Issue, "回译失败") || report.Overall != 0.75 || gotCN.Title != cn.Title {
+		t.Fatalf("failed title should count toward overall as 0: %+v", report)
+	}
+	if !strings.Contains(report.summary(), "质检失败分段：title") {
+		t.Fatalf("summary should name failed sections: %s", report.summary())
+	}
+}
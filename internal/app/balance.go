@@ -39,7 +39,7 @@ func resolveDeepSeekBalanceKey(envMap map[string]string, apiKey string) string {
 	return ""
 }
 
-func fetchDeepSeekBalanceWithRetry(apiKey string, maxRetries int) (string, error) {
+func fetchDeepSeekBalanceWithRetry(apiKey string, maxRetries int, transport http.RoundTripper) (string, error) {
 	apiKey = strings.TrimSpace(apiKey)
 	if apiKey == "" {
 		return "", fmt.Errorf("未找到 DEEPSEEK_API_KEY")
@@ -52,7 +52,7 @@ func fetchDeepSeekBalanceWithRetry(apiKey string, maxRetries int) (string, error
 		MaxDelay:   5 * time.Second,
 		Jitter:     0.2,
 	}, func(attempt int) error {
-		out, err := fetchDeepSeekBalance(apiKey)
+		out, err := fetchDeepSeekBalance(apiKey, transport)
 		if err != nil {
 			return err
 		}
@@ -65,7 +65,7 @@ func fetchDeepSeekBalanceWithRetry(apiKey string, maxRetries int) (string, error
 	return balance, nil
 }
 
-func fetchDeepSeekBalance(apiKey string) (string, error) {
+func fetchDeepSeekBalance(apiKey string, transport http.RoundTripper) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
@@ -76,7 +76,11 @@ func fetchDeepSeekBalance(apiKey string) (string, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 
-	resp, err := http.DefaultClient.Do(req)
+	client := http.DefaultClient
+	if transport != nil {
+		client = &http.Client{Transport: transport}
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("余额请求失败：%w", err)
 	}
@@ -34,28 +34,28 @@ func TestFetchDeepSeekBalanceErrorBranches(t *testing.T) {
 	withPatchedDeepSeekBalanceEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, strings.Repeat("x", 350), http.StatusInternalServerError)
 	})
-	if _, err := fetchDeepSeekBalance("k"); err == nil || !strings.Contains(err.Error(), "余额接口返回 500") {
+	if _, err := fetchDeepSeekBalance("k", nil); err == nil || !strings.Contains(err.Error(), "余额接口返回 500") {
 		t.Fatalf("expected http status error, got %v", err)
 	}
 
 	withPatchedDeepSeekBalanceEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte("{bad-json"))
 	})
-	if _, err := fetchDeepSeekBalance("k"); err == nil || !strings.Contains(err.Error(), "解析余额响应失败") {
+	if _, err := fetchDeepSeekBalance("k", nil); err == nil || !strings.Contains(err.Error(), "解析余额响应失败") {
 		t.Fatalf("expected json parse error, got %v", err)
 	}
 
 	withPatchedDeepSeekBalanceEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"error":{"message":"bad request"}}`)
 	})
-	if _, err := fetchDeepSeekBalance("k"); err == nil || !strings.Contains(err.Error(), "余额接口错误") {
+	if _, err := fetchDeepSeekBalance("k", nil); err == nil || !strings.Contains(err.Error(), "余额接口错误") {
 		t.Fatalf("expected api error field, got %v", err)
 	}
 
 	withPatchedDeepSeekBalanceEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"is_available":true,"balance_infos":[]}`)
 	})
-	if _, err := fetchDeepSeekBalance("k"); err == nil || !strings.Contains(err.Error(), "余额接口返回为空") {
+	if _, err := fetchDeepSeekBalance("k", nil); err == nil || !strings.Contains(err.Error(), "余额接口返回为空") {
 		t.Fatalf("expected empty balance error, got %v", err)
 	}
 }
@@ -70,7 +70,7 @@ func TestFetchDeepSeekBalanceWithRetryEventuallySuccess(t *testing.T) {
 		}
 		fmt.Fprint(w, `{"is_available":true,"balance_infos":[{"currency":"CNY","total_balance":"7.77"}]}`)
 	})
-	got, err := fetchDeepSeekBalanceWithRetry("k", 1)
+	got, err := fetchDeepSeekBalanceWithRetry("k", 1, nil)
 	if err != nil {
 		t.Fatalf("expected retry success, got %v", err)
 	}
@@ -78,7 +78,7 @@ func TestFetchDeepSeekBalanceHTTP(t *testing.T) {
 		}),
 	}
 
-	balance, err := fetchDeepSeekBalance("abc")
+	balance, err := fetchDeepSeekBalance("abc", nil)
 	if err != nil {
 		t.Fatalf("fetchDeepSeekBalance error: %v", err)
 	}
@@ -88,7 +88,7 @@ func TestFetchDeepSeekBalanceHTTP(t *testing.T) {
 }
 
 func TestFetchDeepSeekBalanceWithRetryEmptyKey(t *testing.T) {
-	_, err := fetchDeepSeekBalanceWithRetry("", 1)
+	_, err := fetchDeepSeekBalanceWithRetry("", 1, nil)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"syl-listing/internal/config"
+	"syl-listing/internal/library"
 	"syl-listing/internal/listing"
 	"syl-listing/internal/llm"
 	"syl-listing/internal/logging"
@@ -16,10 +17,12 @@ import (
 )
 
 type bilingualGenerateOptions struct {
+	Context              context.Context
 	Req                  listing.Requirement
 	CharTolerance        int
 	Provider             string
 	ProviderCfg          config.ProviderConfig
+	TranslateProvider    string
 	TranslateProviderCfg config.ProviderConfig
 	APIKey               string
 	Rules                config.SectionRules
@@ -28,6 +31,19 @@ type bilingualGenerateOptions struct {
 	TranslateClient      *translator.Client
 	Logger               *logging.Logger
 	Candidate            int
+	Prompts              *promptDump
+	Style                *stylePolicy
+	// FactCheck 为 true 时核对英文文案中的数值是否来自需求原文。
+	FactCheck bool
+	// Diversity 非 nil 时为候选分配写作角度，并检查标题与同批候选的相似度。
+	Diversity *candidateDiversity
+	Protect   config.ProtectConfig
+	// ReferenceTitle.InPrompt 为 true 时把需求中的参考标题作为风格参考写进标题提示词。
+	ReferenceTitle config.ReferenceTitleConfig
+	// Library 非 nil 时按分类与关键词选取已审核范例，连同品牌语气写进英文分段提示词。
+	Library *library.Library
+	// Edit 非 nil 时为编辑模式：以现有文案为基准逐段保留或最小改动修订。
+	Edit *editSession
 }
 
 func generateENAndTranslateCNBySections(opts bilingualGenerateOptions) (ListingDocument, ListingDocument, int64, int64, error) {
@@ -42,8 +58,6 @@ func generateENAndTranslateCNBySections(opts bilingualGenerateOptions) (ListingD
 		Category: "",
 	}
 
-	cnBullets := make([]string, opts.Rules.BulletCount())
-	cnDesc := make([]string, opts.Rules.DescriptionParagraphs())
 	cnKeywords := make([]string, len(opts.Req.Keywords))
 	var (
 		translateWG  sync.WaitGroup
@@ -64,17 +78,9 @@ func generateENAndTranslateCNBySections(opts bilingualGenerateOptions) (ListingD
 		translateWG.Add(1)
 		go func() {
 			defer translateWG.Done()
-			translated, _, err := translateSectionWithRetry(translateSectionOptions{
-				Req:                  opts.Req,
-				Section:              section,
-				SourceText:           sourceText,
-				TranslateProviderCfg: opts.TranslateProviderCfg,
-				APIKey:               opts.APIKey,
-				MaxRetries:           opts.MaxRetries,
-				Client:               opts.TranslateClient,
-				Logger:               opts.Logger,
-				Candidate:            opts.Candidate,
-			})
+			topts := opts.translateSectionOptions()
+			topts.Section, topts.SourceText = section, sourceText
+			translated, _, err := translateSectionWithRetry(topts)
 			if err != nil {
 				recordTranslateErr(err)
 				return
@@ -93,111 +99,62 @@ func generateENAndTranslateCNBySections(opts bilingualGenerateOptions) (ListingD
 		})
 	}
 
-	enSectionOpts := sectionGenerateOptions{
-		Req:           opts.Req,
-		Lang:          "en",
-		CharTolerance: opts.CharTolerance,
-		Provider:      opts.Provider,
-		ProviderCfg:   opts.ProviderCfg,
-		APIKey:        opts.APIKey,
-		Rules:         opts.Rules,
-		MaxRetries:    opts.MaxRetries,
-		Client:        opts.Client,
-		Logger:        opts.Logger,
-		Candidate:     opts.Candidate,
-	}
-
-	title, latency, err := generateSectionWithRetry(enSectionOpts, "title", enDoc)
-	_ = latency
-	if err != nil {
-		return ListingDocument{}, ListingDocument{}, 0, 0, err
-	}
-	enDoc.Title = cleanTitleLine(title)
-	scheduleTranslate("title", enDoc.Title, func(v string) { cnDoc.Title = cleanTitleLine(v) })
+	enSectionOpts := opts.enSectionOptions()
+	emitLibrarySelection(enSectionOpts)
 
-	bulletRule, err := opts.Rules.Get("bullets")
-	if err != nil {
-		return ListingDocument{}, ListingDocument{}, 0, 0, err
-	}
-	var enBullets []string
-	bulletPolicy := resolveSectionExecutionPolicy(bulletRule)
-	if bulletPolicy.useJSONLines() {
-		if !providerSupportsJSONMode(opts.Provider) {
-			return ListingDocument{}, ListingDocument{}, 0, 0, fmt.Errorf("provider %s 不支持 json_lines 协议", opts.Provider)
+	// 每段英文生成后立即并发翻译，中文结果按段收集，全部完成后再写入中文文档。
+	sections := opts.Rules.Sections()
+	cnItems := make([][]string, len(sections))
+	for si, sec := range sections {
+		var items []string
+		var err error
+		if opts.Edit != nil {
+			// 编辑模式下标题沿用现有文案，不按同批候选去重。
+			items, _, err = reviseBaseSection(enSectionOpts, opts.Edit, sec, enDoc)
+		} else {
+			items, _, err = generateManifestSection(enSectionOpts, sec, enDoc)
+			if err == nil {
+				items, err = opts.Diversity.ensureDistinctTitle(enSectionOpts, sec, enDoc, items)
+			}
 		}
-		items, itemLatency, itemErr := generateJSONLinesWithRepair(enSectionOpts, "bullets", enDoc, bulletRule)
-		_ = itemLatency
-		if itemErr != nil {
-			return ListingDocument{}, ListingDocument{}, 0, 0, itemErr
+		if err != nil {
+			return ListingDocument{}, ListingDocument{}, 0, 0, err
 		}
-		enBullets = items
-	} else {
-		bulletsText, sectionLatency, sectionErr := generateSectionWithRetry(enSectionOpts, "bullets", enDoc)
-		_ = sectionLatency
-		if sectionErr != nil {
-			return ListingDocument{}, ListingDocument{}, 0, 0, sectionErr
+		enDoc.setSection(sec, "en", items)
+		if _, native := opts.cnNativeRule(sec.Name); native {
+			// 中文按中文规则直接生成，依赖的中文分段译完后再执行。
+			continue
 		}
-		items, parseErr := parseBullets(bulletsText, opts.Rules.BulletCount())
-		if parseErr != nil {
-			return ListingDocument{}, ListingDocument{}, 0, 0, parseErr
+		cn := make([]string, len(items))
+		cnItems[si] = cn
+		for i, it := range items {
+			idx := i
+			clean := translatedCleaner(sec.Name)
+			scheduleTranslate(translateSectionName(sec, i, len(items)), it, func(v string) {
+				cn[idx] = clean(v)
+			})
 		}
-		enBullets = items
 	}
-	enDoc.BulletPoints = enBullets
-	for i, bp := range enBullets {
-		idx := i
-		text := bp
-		scheduleTranslate(fmt.Sprintf("bullet_%d", i+1), text, func(v string) {
-			cnBullets[idx] = strings.TrimSpace(v)
-		})
-	}
-
-	descText, latency, err := generateSectionWithRetry(enSectionOpts, "description", enDoc)
-	_ = latency
-	if err != nil {
-		return ListingDocument{}, ListingDocument{}, 0, 0, err
-	}
-	enDesc, err := parseParagraphs(descText, opts.Rules.DescriptionParagraphs())
-	if err != nil {
-		return ListingDocument{}, ListingDocument{}, 0, 0, err
-	}
-	enDoc.DescriptionParagraphs = enDesc
-	for i, p := range enDesc {
-		idx := i
-		text := p
-		scheduleTranslate(fmt.Sprintf("description_%d", i+1), text, func(v string) {
-			cnDesc[idx] = strings.TrimSpace(v)
-		})
-	}
-
-	search, latency, err := generateSectionWithRetry(enSectionOpts, "search_terms", enDoc)
-	_ = latency
-	if err != nil {
-		return ListingDocument{}, ListingDocument{}, 0, 0, err
-	}
-	enDoc.SearchTerms = cleanSearchTermsLine(search)
 	enElapsedMS = time.Since(startAt).Milliseconds()
-	scheduleTranslate("search_terms", enDoc.SearchTerms, func(v string) { cnDoc.SearchTerms = cleanSearchTermsLine(v) })
+	emitFactCheckTable(enSectionOpts, enDoc)
 
 	translateWG.Wait()
 	if translateErr != nil {
 		return ListingDocument{}, ListingDocument{}, 0, 0, translateErr
 	}
 	cnDoc.Keywords = cnKeywords
-	cnDoc.BulletPoints = cnBullets
-	cnDoc.DescriptionParagraphs = cnDesc
-	for i, bp := range cnDoc.BulletPoints {
-		if strings.TrimSpace(bp) == "" {
-			return ListingDocument{}, ListingDocument{}, 0, 0, fmt.Errorf("cn bullets 校验失败：第%d点为空", i+1)
+	for si, sec := range sections {
+		var items []string
+		var err error
+		if rule, native := opts.cnNativeRule(sec.Name); native {
+			items, err = generateNativeCNSection(opts, sec, rule, cnDoc)
+		} else if err = validateTranslatedItems(sec, cnItems[si]); err == nil {
+			items, err = fitCNSection(opts, sec, enDoc.SectionItems(sec.Name), cnItems[si])
 		}
-	}
-	for i, p := range cnDoc.DescriptionParagraphs {
-		if strings.TrimSpace(p) == "" {
-			return ListingDocument{}, ListingDocument{}, 0, 0, fmt.Errorf("cn description 校验失败：第%d段为空", i+1)
+		if err != nil {
+			return ListingDocument{}, ListingDocument{}, 0, 0, err
 		}
-	}
-	if strings.TrimSpace(cnDoc.Title) == "" {
-		return ListingDocument{}, ListingDocument{}, 0, 0, fmt.Errorf("cn title 校验失败：为空")
+		cnDoc.setSection(sec, "cn", items)
 	}
 	if strings.TrimSpace(cnDoc.Category) == "" {
 		return ListingDocument{}, ListingDocument{}, 0, 0, fmt.Errorf("cn category 校验失败：为空")
@@ -207,9 +164,6 @@ func generateENAndTranslateCNBySections(opts bilingualGenerateOptions) (ListingD
 			return ListingDocument{}, ListingDocument{}, 0, 0, fmt.Errorf("cn keywords 校验失败：第%d项为空", i+1)
 		}
 	}
-	if strings.TrimSpace(cnDoc.SearchTerms) == "" {
-		return ListingDocument{}, ListingDocument{}, 0, 0, fmt.Errorf("cn search_terms 校验失败：为空")
-	}
 
 	if err := validateDocumentBySectionRules("en", opts.Req, enDoc, opts.Rules); err != nil {
 		return ListingDocument{}, ListingDocument{}, 0, 0, err
@@ -220,16 +174,141 @@ func generateENAndTranslateCNBySections(opts bilingualGenerateOptions) (ListingD
 	return enDoc, cnDoc, enElapsedMS, time.Since(startAt).Milliseconds(), nil
 }
 
+// enSectionOptions 返回生成英文分段所用的参数。
+func (opts bilingualGenerateOptions) enSectionOptions() sectionGenerateOptions {
+	out := sectionGenerateOptions{
+		Context:       opts.Context,
+		Req:           opts.Req,
+		Lang:          "en",
+		CharTolerance: opts.CharTolerance,
+		Provider:      opts.Provider,
+		ProviderCfg:   opts.ProviderCfg,
+		APIKey:        opts.APIKey,
+		Rules:         opts.Rules,
+		MaxRetries:    opts.MaxRetries,
+		Client:        opts.Client,
+		Logger:        opts.Logger,
+		Candidate:     opts.Candidate,
+		Prompts:       opts.Prompts,
+		Style:         opts.Style,
+		Direction:     opts.Diversity.direction(opts.Candidate),
+	}
+	if opts.FactCheck {
+		out.Facts = newFactSheet(opts.Req)
+	}
+	if opts.ReferenceTitle.InPrompt {
+		out.Extras.ReferenceTitle = strings.TrimSpace(opts.Req.ReferenceTitle)
+	}
+	out.Extras.Voice = opts.Library.Voice(opts.Req.Brand)
+	out.Extras.Examples = opts.Library.Select(opts.Req.Brand, opts.Req.Category, opts.Req.Keywords)
+	return out
+}
+
+// translateSectionOptions 返回翻译分段所用的参数，Section 与 SourceText 由调用方填写。
+func (opts bilingualGenerateOptions) translateSectionOptions() translateSectionOptions {
+	return translateSectionOptions{
+		Context:              opts.Context,
+		Req:                  opts.Req,
+		Provider:             opts.TranslateProvider,
+		TranslateProviderCfg: opts.TranslateProviderCfg,
+		APIKey:               opts.APIKey,
+		MaxRetries:           opts.MaxRetries,
+		Client:               opts.TranslateClient,
+		Logger:               opts.Logger,
+		Candidate:            opts.Candidate,
+	}.withProtect(opts.Protect)
+}
+
+// translateSectionItems 逐条翻译一个分段并清洗、校验结果。
+func translateSectionItems(base translateSectionOptions, sec config.ManifestSection, items []string) ([]string, error) {
+	out := make([]string, len(items))
+	clean := translatedCleaner(sec.Name)
+	for i, it := range items {
+		topts := base
+		topts.Section = translateSectionName(sec, i, len(items))
+		topts.SourceText = it
+		translated, _, err := translateSectionWithRetry(topts)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = clean(strings.TrimSpace(translated))
+	}
+	return out, validateTranslatedItems(sec, out)
+}
+
+// translateSectionName 返回翻译事件中的分段名：五点与描述沿用 bullet_N、description_N，单行分段用分段名本身。
+func translateSectionName(sec config.ManifestSection, idx, total int) string {
+	switch sec.Name {
+	case "bullets":
+		return fmt.Sprintf("bullet_%d", idx+1)
+	case "description":
+		return fmt.Sprintf("description_%d", idx+1)
+	}
+	if sec.Output == config.OutputLine && total == 1 {
+		return sec.Name
+	}
+	return fmt.Sprintf("%s_%d", sec.Name, idx+1)
+}
+
+func translatedCleaner(section string) func(string) string {
+	switch section {
+	case "title":
+		return cleanTitleLine
+	case "search_terms":
+		return cleanSearchTermsLine
+	default:
+		return strings.TrimSpace
+	}
+}
+
+func validateTranslatedItems(sec config.ManifestSection, items []string) error {
+	for i, it := range items {
+		if strings.TrimSpace(it) != "" {
+			continue
+		}
+		switch {
+		case sec.Name == "bullets":
+			return fmt.Errorf("cn bullets 校验失败：第%d点为空", i+1)
+		case sec.Name == "description":
+			return fmt.Errorf("cn description 校验失败：第%d段为空", i+1)
+		case len(items) == 1:
+			return fmt.Errorf("cn %s 校验失败：为空", sec.Name)
+		default:
+			return fmt.Errorf("cn %s 校验失败：第%d项为空", sec.Name, i+1)
+		}
+	}
+	return nil
+}
+
 type translateSectionOptions struct {
-	Req                  listing.Requirement
-	Section              string
-	SourceText           string
+	Context    context.Context
+	Req        listing.Requirement
+	Section    string
+	SourceText string
+	// Provider 为空时使用 deepseek。
+	Provider             string
 	TranslateProviderCfg config.ProviderConfig
 	APIKey               string
 	MaxRetries           int
 	Client               *translator.Client
 	Logger               *logging.Logger
 	Candidate            int
+	// Target 为 en 时把中文回译为英文（回译质检），否则英译中。
+	Target string
+	// Instruction 为附加翻译要求，仅支持的 provider 生效。
+	Instruction string
+	// Protect 为 true 时品牌、ProtectedTerms、型号与带单位的数值以占位符送翻，译后还原。
+	Protect        bool
+	ProtectedTerms []string
+}
+
+// withProtect 按配置开启占位符保护，需求中的品牌始终是受保护词。
+func (o translateSectionOptions) withProtect(cfg config.ProtectConfig) translateSectionOptions {
+	if cfg.IsEnabled() {
+		o.Protect = true
+		o.ProtectedTerms = append([]string{o.Req.Brand}, cfg.Terms...)
+	}
+	return o
 }
 
 func translateSectionWithRetry(opts translateSectionOptions) (string, int64, error) {
@@ -237,8 +316,17 @@ func translateSectionWithRetry(opts translateSectionOptions) (string, int64, err
 		outText    string
 		outLatency int64
 	)
+	provider := strings.TrimSpace(opts.Provider)
+	if provider == "" {
+		provider = "deepseek"
+	}
+	source, target, lang := "en", "zh", "cn"
+	if strings.TrimSpace(opts.Target) == "en" {
+		source, target, lang = "zh", "en", "en"
+	}
 	lastIssues := ""
 	err := withExponentialBackoff(retryOptions{
+		Context:    opts.Context,
 		MaxRetries: opts.MaxRetries,
 		BaseDelay:  500 * time.Millisecond,
 		MaxDelay:   8 * time.Second,
@@ -249,7 +337,7 @@ func translateSectionWithRetry(opts translateSectionOptions) (string, int64, err
 				Event:     "retry_backoff_translate_" + opts.Section,
 				Input:     opts.Req.SourcePath,
 				Candidate: opts.Candidate,
-				Lang:      "cn",
+				Lang:      lang,
 				Attempt:   attempt,
 				WaitMS:    wait.Milliseconds(),
 				Error:     err.Error(),
@@ -260,8 +348,8 @@ func translateSectionWithRetry(opts translateSectionOptions) (string, int64, err
 			Event:     "api_request_translate_" + opts.Section,
 			Input:     opts.Req.SourcePath,
 			Candidate: opts.Candidate,
-			Lang:      "cn",
-			Provider:  "deepseek",
+			Lang:      lang,
+			Provider:  provider,
 			Model:     opts.TranslateProviderCfg.Model,
 			BaseURL:   opts.TranslateProviderCfg.BaseURL,
 			Attempt:   attempt,
@@ -270,36 +358,52 @@ func translateSectionWithRetry(opts translateSectionOptions) (string, int64, err
 			reqEvent.SourceText = opts.SourceText
 		}
 		opts.Logger.Emit(reqEvent)
-		resp, err := opts.Client.Translate(context.Background(), translator.Request{
-			Provider:   "deepseek",
-			Endpoint:   opts.TranslateProviderCfg.BaseURL,
-			Model:      opts.TranslateProviderCfg.Model,
-			APIKey:     opts.APIKey,
-			Source:     "en",
-			Target:     "zh",
-			UserPrompt: opts.SourceText,
+		resp, err := opts.Client.Translate(contextOrBackground(opts.Context), translator.Request{
+			Provider:       provider,
+			Endpoint:       opts.TranslateProviderCfg.BaseURL,
+			Model:          opts.TranslateProviderCfg.Model,
+			APIKey:         opts.APIKey,
+			Source:         source,
+			Target:         target,
+			UserPrompt:     opts.SourceText,
+			Instruction:    opts.Instruction,
+			Protect:        opts.Protect,
+			ProtectedTerms: opts.ProtectedTerms,
 		})
+		if errors.Is(err, translator.ErrPlaceholderMissing) {
+			lastIssues = "- " + err.Error()
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "validate_error_translate_" + opts.Section, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: lang, Attempt: attempt, Error: err.Error()})
+			return errors.New(lastIssues)
+		}
 		if err != nil {
 			lastIssues = "- 翻译请求失败: " + err.Error()
-			opts.Logger.Emit(logging.Event{Level: "warn", Event: "api_error_translate_" + opts.Section, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Attempt: attempt, Error: err.Error()})
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "api_error_translate_" + opts.Section, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: lang, Attempt: attempt, Error: err.Error()})
 			return errors.New(lastIssues)
 		}
 		text := normalizeModelText(resp.Text)
 		if strings.TrimSpace(text) == "" {
 			lastIssues = "- 翻译结果为空"
-			opts.Logger.Emit(logging.Event{Level: "warn", Event: "validate_error_translate_" + opts.Section, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Attempt: attempt, Error: "翻译结果为空"})
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "validate_error_translate_" + opts.Section, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: lang, Attempt: attempt, Error: "翻译结果为空"})
 			return errors.New(lastIssues)
 		}
 		respEvent := logging.Event{
 			Event:     "api_response_translate_" + opts.Section,
 			Input:     opts.Req.SourcePath,
 			Candidate: opts.Candidate,
-			Lang:      "cn",
-			Provider:  "deepseek",
+			Lang:      lang,
+			Provider:  provider,
 			Model:     opts.TranslateProviderCfg.Model,
 			LatencyMS: resp.LatencyMS,
 			Attempt:   attempt,
 		}
+		switch {
+		case resp.Cached:
+			respEvent.Cache = "hit"
+		case resp.Memory:
+			respEvent.Cache = "tm"
+		case resp.Deduped:
+			respEvent.Cache = "deduped"
+		}
 		if opts.Logger.Verbose() {
 			respEvent.ResponseText = text
 		}
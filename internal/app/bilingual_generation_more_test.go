@@ -168,3 +168,48 @@ func TestGenerateENAndTranslateCNBySections_CNTitleValidationError(t *testing.T)
 		t.Fatalf("expected cn title validation error, got %v", err)
 	}
 }
+
+func TestTranslateSectionWithRetryRestoresProtectedTokens(t *testing.T) {
+	var calls int
+	var first string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		calls++
+		user := req.Messages[1].Content
+		text := "收纳盒" + user
+		if calls == 1 {
+			first = user
+			text = "布兰德收纳盒"
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+
+	opts := translateSectionOptions{
+		Req:                  listing.Requirement{SourcePath: "/in/a.md", Brand: "BrandX"},
+		Section:              "title",
+		SourceText:           "BrandX Organizer, 10x13 inches",
+		Provider:             "deepseek",
+		TranslateProviderCfg: config.ProviderConfig{BaseURL: ts.URL},
+		APIKey:               "k",
+		MaxRetries:           1,
+		Client:               translator.NewClient(5 * time.Second),
+	}.withProtect(config.ProtectConfig{Terms: []string{"Organizer"}})
+	got, _, err := translateSectionWithRetry(opts)
+	if err != nil || got != "收纳盒BrandX Organizer, 10x13 inches" {
+		t.Fatalf("protected tokens should be restored after retry: %q %v", got, err)
+	}
+	if first != "{{P0}} {{P1}}, {{P2}}" || calls != 2 {
+		t.Fatalf("unexpected masked request %q (calls=%d)", first, calls)
+	}
+
+	disabled := false
+	if plain := (translateSectionOptions{}).withProtect(config.ProtectConfig{Enabled: &disabled}); plain.Protect || plain.ProtectedTerms != nil {
+		t.Fatalf("disabled protect should send raw text: %+v", plain)
+	}
+}
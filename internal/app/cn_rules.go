@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/translator"
+)
+
+// translateCNSection 为一个英文分段产出中文：中文规则为 native 时直接从需求生成，否则逐条翻译后按中文规则校验。
+func (opts bilingualGenerateOptions) translateCNSection(sec config.ManifestSection, enItems []string, cnDoc ListingDocument) ([]string, error) {
+	if rule, ok := opts.cnNativeRule(sec.Name); ok {
+		return generateNativeCNSection(opts, sec, rule, cnDoc)
+	}
+	cn, err := translateSectionItems(opts.translateSectionOptions(), sec, enItems)
+	if err != nil {
+		return nil, err
+	}
+	return fitCNSection(opts, sec, enItems, cn)
+}
+
+// cnNativeRule 返回 mode=native 的中文规则；ok=false 表示该分段走翻译。
+func (opts bilingualGenerateOptions) cnNativeRule(name string) (config.SectionRuleFile, bool) {
+	rule, ok := opts.Rules.CNRule(name)
+	if !ok || rule.Parsed.CNMode() != config.CNModeNative {
+		return config.SectionRuleFile{}, false
+	}
+	return rule, true
+}
+
+// generateNativeCNSection 用中文规则替换该分段的英文规则，按需求直接生成中文；上下文为已完成的中文分段。
+func generateNativeCNSection(opts bilingualGenerateOptions, sec config.ManifestSection, rule config.SectionRuleFile, cnDoc ListingDocument) ([]string, error) {
+	cnOpts := opts.enSectionOptions()
+	cnOpts.Lang = "cn"
+	cnOpts.Rules = opts.Rules.WithRule(sec.Name, rule)
+	cnOpts.Style, cnOpts.Facts = nil, nil
+	// 范例库只收英文 listing，品牌语气档案也按英文文案撰写，按中文规则生成时都不注入。
+	cnOpts.Extras.Examples, cnOpts.Extras.Voice = nil, ""
+	items, _, err := generateManifestSection(cnOpts, sec, cnDoc)
+	if err != nil {
+		return nil, fmt.Errorf("cn %s 生成失败：%w", sec.Name, err)
+	}
+	return items, nil
+}
+
+// fitCNSection 按中文规则校验译文；超出长度上限的条目带“译文不超过 N 字”的要求重新翻译，
+// 最多 max(MaxRetries,1) 轮。翻译 provider 不支持附加要求时不重译，仍违反硬约束即失败。
+func fitCNSection(opts bilingualGenerateOptions, sec config.ManifestSection, enItems, items []string) ([]string, error) {
+	rule, ok := opts.Rules.CNRule(sec.Name)
+	if !ok {
+		return items, nil
+	}
+	items = append([]string{}, items...)
+	base := opts.translateSectionOptions()
+	provider := strings.TrimSpace(base.Provider)
+	if provider == "" {
+		provider = "deepseek"
+	}
+	clean := translatedCleaner(sec.Name)
+	issues, warnings := validateCNItems(sec, items, rule, opts.CharTolerance)
+	for attempt := 1; len(issues) > 0 && translator.SupportsInstruction(provider) && attempt <= max(opts.MaxRetries, 1); attempt++ {
+		opts.Logger.Emit(logging.Event{Level: "warn", Event: "validate_error_translate_" + sec.Name, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Attempt: attempt, Error: strings.Join(issues, "; ")})
+		bounds := cnLengthBounds(rule, opts.CharTolerance)
+		changed := false
+		for i, limit := range cnItemLimits(sec, rule, items, bounds.count) {
+			n := bounds.measure(items[i])
+			if limit <= 0 || n <= limit || i >= len(enItems) {
+				continue
+			}
+			topts := base
+			topts.Section, topts.SourceText = translateSectionName(sec, i, len(items)), enItems[i]
+			topts.Instruction = fmt.Sprintf("译文不得超过 %d %s（上一版 %d %s，超出上限）：保留核心卖点、数值与关键词，删减修饰语，不要分行。\n上一版译文：%s", limit, bounds.unit(), n, bounds.unit(), items[i])
+			translated, _, err := translateSectionWithRetry(topts)
+			if err != nil {
+				return nil, err
+			}
+			if next := clean(strings.TrimSpace(translated)); next != "" {
+				items[i], changed = next, true
+			}
+		}
+		if !changed {
+			break
+		}
+		issues, warnings = validateCNItems(sec, items, rule, opts.CharTolerance)
+	}
+	for _, w := range warnings {
+		opts.Logger.Emit(logging.Event{Level: "warn", Event: "validation_warning", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "cn", Error: w})
+	}
+	if len(issues) > 0 {
+		return nil, fmt.Errorf("cn %s 校验失败：%s", sec.Name, strings.Join(issues, "；"))
+	}
+	return items, nil
+}
+
+// validateCNItems 按中文规则校验一个分段：单行分段看 max_chars；多行与段落看每项区间，
+// 并以 max_chars 限制整段总长（如描述 ≤1000 字）。
+func validateCNItems(sec config.ManifestSection, items []string, rule config.SectionRuleFile, tolerance int) ([]string, []string) {
+	parsed := rule.Parsed
+	cons := parsed.Constraints
+	c := &ruleIssueCollector{rule: parsed}
+	label := "中文" + sec.Name
+	if sec.Output == config.OutputLine {
+		checkLengthBounds(c, "max_chars", label, firstItem(items), resolveRuleBounds(parsed, config.RuleIntConstraint{}, cons.MaxChars, tolerance))
+		return c.result()
+	}
+	perItem := resolveRuleBounds(parsed, cons.MinCharsPerLine, cons.MaxCharsPerLine, tolerance)
+	for i, it := range items {
+		checkLengthBounds(c, "max_chars_per_line", fmt.Sprintf("%s第%d项", label, i+1), it, perItem)
+	}
+	if cons.MaxChars.Value > 0 {
+		checkLengthBounds(c, "max_chars", label+"总", strings.Join(items, ""), resolveRuleBounds(parsed, config.RuleIntConstraint{}, cons.MaxChars, tolerance))
+	}
+	return c.result()
+}
+
+func cnLengthBounds(rule config.SectionRuleFile, tolerance int) charBounds {
+	cons := rule.Parsed.Constraints
+	if cons.MaxChars.Value > 0 {
+		return resolveRuleBounds(rule.Parsed, config.RuleIntConstraint{}, cons.MaxChars, tolerance)
+	}
+	return resolveRuleBounds(rule.Parsed, cons.MinCharsPerLine, cons.MaxCharsPerLine, tolerance)
+}
+
+// cnItemLimits 返回每项重译时的长度上限：单行取 max_chars；多行取 max_chars_per_line，
+// 整段超出 max_chars 时按各项现有长度等比分摊，取两者较小值。0 表示不限。
+func cnItemLimits(sec config.ManifestSection, rule config.SectionRuleFile, items []string, count string) []int {
+	cons := rule.Parsed.Constraints
+	limits := make([]int, len(items))
+	if sec.Output == config.OutputLine {
+		if len(limits) > 0 {
+			limits[0] = cons.MaxChars.Value
+		}
+		return limits
+	}
+	total := 0
+	for _, it := range items {
+		total += measureLength(it, count)
+	}
+	for i, it := range items {
+		limit := cons.MaxCharsPerLine.Value
+		if cons.MaxChars.Value > 0 && total > cons.MaxChars.Value {
+			if share := cons.MaxChars.Value * measureLength(it, count) / total; limit <= 0 || share < limit {
+				limit = share
+			}
+		}
+		limits[i] = limit
+	}
+	return limits
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/library"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/translator"
+)
+
+func cnRuleFile(mode string, lines, maxChars, maxPerLine int) config.SectionRuleFile {
+	disableThinking := true
+	return config.SectionRuleFile{Parsed: config.SectionRule{
+		Mode:        mode,
+		Output:      config.RuleOutputSpec{Lines: lines},
+		Constraints: config.RuleConstraints{MaxChars: config.RuleIntConstraint{Value: maxChars}, MaxCharsPerLine: config.RuleIntConstraint{Value: maxPerLine}},
+		Execution: config.RuleExecutionSpec{
+			Generation: config.RuleGenerationSpec{Protocol: "text"},
+			Repair:     config.RuleRepairPolicySpec{Granularity: "whole"},
+			Fallback:   config.RuleFallbackPolicySpec{DisableThinkingOnLengthError: &disableThinking},
+		},
+	}}
+}
+
+func TestValidateCNItemsAndLimits(t *testing.T) {
+	title := config.ManifestSection{Name: "title", Output: config.OutputLine}
+	rule := cnRuleFile("", 1, 10, 0)
+	if issues, _ := validateCNItems(title, []string{"短标题"}, rule, 0); len(issues) != 0 {
+		t.Fatalf("short title should pass: %v", issues)
+	}
+	if issues, _ := validateCNItems(title, []string{"这是一个非常非常长的中文标题"}, rule, 0); len(issues) == 0 || !strings.Contains(issues[0], "中文title") {
+		t.Fatalf("long title should fail: %v", issues)
+	}
+
+	bullets := config.ManifestSection{Name: "bullets", Output: config.OutputLines}
+	rule = cnRuleFile("", 2, 10, 8)
+	items := []string{"一二三四五六", "一二三四五六"}
+	if issues, _ := validateCNItems(bullets, items, rule, 0); len(issues) != 1 || !strings.Contains(issues[0], "中文bullets总") {
+		t.Fatalf("total length should be checked: %v", issues)
+	}
+	if got := cnItemLimits(bullets, rule, items, ""); !reflect.DeepEqual(got, []int{5, 5}) {
+		t.Fatalf("total limit should be shared by length: %v", got)
+	}
+	if got := cnItemLimits(title, cnRuleFile("", 1, 10, 0), []string{"x"}, ""); !reflect.DeepEqual(got, []int{10}) {
+		t.Fatalf("unexpected title limit: %v", got)
+	}
+}
+
+func TestFitCNSectionRetranslatesOverLimitItems(t *testing.T) {
+	var instructed int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text := "这是一个非常非常长的中文标题"
+		if strings.Contains(body.Messages[0].Content, "译文不得超过 10 字") {
+			instructed++
+			text = "短标题"
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger, _, err := logging.New(&buf, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := testRules()
+	rules.CN = map[string]config.SectionRuleFile{"title": cnRuleFile("", 1, 10, 0)}
+	opts := bilingualGenerateOptions{
+		Req:                  listing.Requirement{SourcePath: "/in/demo.md"},
+		TranslateProvider:    "deepseek",
+		TranslateProviderCfg: config.ProviderConfig{BaseURL: ts.URL},
+		APIKey:               "k",
+		Rules:                rules,
+		MaxRetries:           1,
+		TranslateClient:      translator.NewClient(10 * time.Second),
+		Logger:               logger,
+	}
+	title := config.ManifestSection{Name: "title", Output: config.OutputLine}
+	got, err := opts.translateCNSection(title, []string{"Alpha organizer"}, ListingDocument{})
+	if err != nil || !reflect.DeepEqual(got, []string{"短标题"}) || instructed != 1 {
+		t.Fatalf("over-limit title should be retranslated with a length limit: %v %v (%d)", got, err, instructed)
+	}
+	if !strings.Contains(buf.String(), "中文标题翻译 校验失败") {
+		t.Fatalf("missing validate log: %s", buf.String())
+	}
+
+	opts.TranslateProvider = "mock"
+	if _, err := fitCNSection(opts, title, []string{"Alpha"}, []string{"这是一个非常非常长的中文标题"}); err == nil || !strings.Contains(err.Error(), "cn title 校验失败") {
+		t.Fatalf("providers without instructions should fail hard limits: %v", err)
+	}
+	if got, err := fitCNSection(opts, config.ManifestSection{Name: "bullets", Output: config.OutputLines}, nil, []string{"任意"}); err != nil || got[0] != "任意" {
+		t.Fatalf("sections without cn rule should pass through: %v %v", got, err)
+	}
+}
+
+func TestTranslateCNSectionNativeMode(t *testing.T) {
+	rules := testRules()
+	native := cnRuleFile(config.CNModeNative, 1, 60, 0)
+	native.Raw = "section: title\noutput:\n  lines: 1\nconstraints:\n  max_chars:\n    value: 60\n"
+	rules.CN = map[string]config.SectionRuleFile{"title": native}
+	opts := bilingualGenerateOptions{
+		Req:               listing.Requirement{SourcePath: "/in/demo.md", Brand: "BrandX", Keywords: []string{"alpha", "beta"}},
+		Provider:          "mock",
+		ProviderCfg:       config.ProviderConfig{Model: "mock"},
+		TranslateProvider: "mock",
+		Rules:             rules,
+		MaxRetries:        1,
+		Client:            llm.NewClient(5 * time.Second),
+		TranslateClient:   translator.NewClient(5 * time.Second),
+	}
+	libDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(libDir, "voices"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(libDir, "voices", "brandx.md"), []byte("Warm and practical."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lib, err := library.Open(libDir, library.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Library, opts.Prompts = lib, newPromptDump()
+	if opts.enSectionOptions().Extras.Voice == "" {
+		t.Fatalf("english sections should carry the brand voice")
+	}
+	title := config.ManifestSection{Name: "title", Output: config.OutputLine}
+	got, err := opts.translateCNSection(title, []string{"English title"}, ListingDocument{})
+	if err != nil || len(got) != 1 || strings.Contains(got[0], "模拟译文") || len([]rune(got[0])) > 60 {
+		t.Fatalf("native section should be generated from the cn rule: %v %v", got, err)
+	}
+	if dump := opts.Prompts.render(opts.Req.SourcePath); strings.Contains(dump, "【品牌语气】") || !strings.Contains(dump, "生成：title") {
+		t.Fatalf("native cn prompts should not carry the english brand voice:\n%s", dump)
+	}
+}
@@ -0,0 +1,395 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/output"
+	"syl-listing/internal/translator"
+)
+
+// composeOptions 是跨候选组合最佳分段所需的生成与翻译参数。
+type composeOptions struct {
+	Context              context.Context
+	OutDir               string
+	CharTolerance        int
+	Provider             string
+	ProviderCfg          config.ProviderConfig
+	TranslateProvider    string
+	TranslateProviderCfg config.ProviderConfig
+	APIKey               string
+	Rules                config.SectionRules
+	MaxRetries           int
+	Client               *llm.Client
+	TranslateClient      *translator.Client
+	Logger               *logging.Logger
+	Style                *stylePolicy
+	FactCheck            bool
+	Protect              config.ProtectConfig
+}
+
+// bilingualOptions 返回重新生成分段时翻译中文所用的参数。
+func (opts composeOptions) bilingualOptions(req listing.Requirement) bilingualGenerateOptions {
+	return bilingualGenerateOptions{
+		Context:              opts.Context,
+		Req:                  req,
+		CharTolerance:        opts.CharTolerance,
+		Provider:             opts.Provider,
+		ProviderCfg:          opts.ProviderCfg,
+		TranslateProvider:    opts.TranslateProvider,
+		TranslateProviderCfg: opts.TranslateProviderCfg,
+		APIKey:               opts.APIKey,
+		Rules:                opts.Rules,
+		MaxRetries:           opts.MaxRetries,
+		Client:               opts.Client,
+		TranslateClient:      opts.TranslateClient,
+		Logger:               opts.Logger,
+		Protect:              opts.Protect,
+	}
+}
+
+// composedListing 是组合结果；Sources 记录每个分段的来源候选，0 表示重新生成。
+type composedListing struct {
+	Req     listing.Requirement
+	EN      ListingDocument
+	CN      ListingDocument
+	Sources map[string]int
+	Score   documentScore
+}
+
+// summary 按清单顺序列出分段来源，写入日志。
+func (c composedListing) summary(rules config.SectionRules) string {
+	parts := make([]string, 0, len(c.Sources))
+	for _, sec := range rules.Sections() {
+		src, ok := c.Sources[sec.Name]
+		if !ok {
+			continue
+		}
+		if src == 0 {
+			parts = append(parts, sec.Name+"←重新生成")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s←候选%d", sec.Name, src))
+	}
+	return fmt.Sprintf("%s（总分 %.1f）", strings.Join(parts, "，"), c.Score.Total)
+}
+
+// pickSectionSources 为每个分段选出得分最高的候选；同分时优先总分最佳的候选，其次编号小的候选。
+func pickSectionSources(report rankingReport) map[string]int {
+	type pick struct {
+		candidate int
+		score     float64
+		rank      int
+	}
+	best := map[string]pick{}
+	for _, entry := range report.Candidates {
+		for _, s := range entry.Sections {
+			cur, ok := best[s.Name]
+			better := !ok || s.Score > cur.score ||
+				(s.Score == cur.score && entry.Rank < cur.rank)
+			if better {
+				best[s.Name] = pick{candidate: entry.Candidate, score: s.Score, rank: entry.Rank}
+			}
+		}
+	}
+	out := make(map[string]int, len(best))
+	for name, p := range best {
+		out[name] = p.candidate
+	}
+	return out
+}
+
+// needsRegeneration 判断组合后某分段是否要重新生成：段落类与本地拼接分段依赖上下文，
+// 依赖的分段来自其他候选（或已重新生成）时原文不再成立；单行/多行分段按得分直接选用。
+func needsRegeneration(sec config.ManifestSection, rule config.SectionRuleFile, src int, sources map[string]int) bool {
+	if sec.Output != config.OutputParagraphs && !resolveSectionExecutionPolicy(rule).useDeterministic() {
+		return false
+	}
+	for _, dep := range sec.DependsOn {
+		if sources[dep] != src {
+			return true
+		}
+	}
+	return false
+}
+
+// composeCandidates 跨候选组合每个分段的最佳版本；所有分段都来自同一候选时返回 nil（无需组合）。
+func composeCandidates(opts composeOptions, outs []candidateOutput) (*composedListing, error) {
+	if len(outs) < 2 {
+		return nil, nil
+	}
+	req := outs[0].Req
+	report := buildRankingReport(outs, opts.Rules, opts.Style, opts.CharTolerance)
+	picks := pickSectionSources(report)
+	byCandidate := make(map[int]candidateOutput, len(outs))
+	for _, o := range outs {
+		byCandidate[o.Candidate] = o
+	}
+	base := byCandidate[report.Best]
+
+	genOpts := sectionGenerateOptions{
+		Context:       opts.Context,
+		Req:           req,
+		Lang:          "en",
+		CharTolerance: opts.CharTolerance,
+		Provider:      opts.Provider,
+		ProviderCfg:   opts.ProviderCfg,
+		APIKey:        opts.APIKey,
+		Rules:         opts.Rules,
+		MaxRetries:    opts.MaxRetries,
+		Client:        opts.Client,
+		Logger:        opts.Logger,
+		Style:         opts.Style,
+	}
+	if opts.FactCheck {
+		genOpts.Facts = newFactSheet(req)
+	}
+
+	composed := &composedListing{
+		Req:     req,
+		EN:      ListingDocument{Keywords: append([]string{}, req.Keywords...), Category: strings.TrimSpace(req.Category)},
+		CN:      ListingDocument{Keywords: append([]string{}, base.CN.Keywords...), Category: base.CN.Category},
+		Sources: map[string]int{},
+	}
+	mixed := false
+	for _, sec := range opts.Rules.Sections() {
+		rule, err := opts.Rules.Get(sec.Name)
+		if err != nil {
+			return nil, err
+		}
+		src, ok := picks[sec.Name]
+		if !ok {
+			src = base.Candidate
+		}
+		if src != base.Candidate {
+			mixed = true
+		}
+		if needsRegeneration(sec, rule, src, composed.Sources) {
+			items, _, err := generateManifestSection(genOpts, sec, composed.EN)
+			if err != nil {
+				return nil, fmt.Errorf("重新生成 %s 失败：%w", sec.Name, err)
+			}
+			cn, err := opts.bilingualOptions(req).translateCNSection(sec, items, composed.CN)
+			if err != nil {
+				return nil, err
+			}
+			composed.EN.setSection(sec, "en", items)
+			composed.CN.setSection(sec, "cn", cn)
+			composed.Sources[sec.Name] = 0
+			continue
+		}
+		from := byCandidate[src]
+		composed.EN.setSection(sec, "en", append([]string{}, from.EN.SectionItems(sec.Name)...))
+		composed.CN.setSection(sec, "cn", append([]string{}, from.CN.SectionItems(sec.Name)...))
+		composed.Sources[sec.Name] = src
+	}
+	if !mixed {
+		return nil, nil
+	}
+	if err := validateDocumentBySectionRules("en", req, composed.EN, opts.Rules); err != nil {
+		return nil, fmt.Errorf("组合结果校验失败：%w", err)
+	}
+	if err := validateDocumentBySectionRules("cn", req, composed.CN, opts.Rules); err != nil {
+		return nil, fmt.Errorf("组合结果中文校验失败：%w", err)
+	}
+	composed.Score = scoreDocument(req, opts.Rules, composed.EN, opts.Style, opts.CharTolerance)
+	return composed, nil
+}
+
+// composedPaths 返回组合结果的输出路径，按 reportStem 区分。
+func composedPaths(outDir, input string) (en, cn string) {
+	stem := reportStem(input)
+	return filepath.Join(outDir, stem+"_composed_en.md"), filepath.Join(outDir, stem+"_composed_cn.md")
+}
+
+// writeComposed 组合每个需求的候选并写入 <需求名>_composed_en.md/_cn.md；失败只告警，不影响候选输出。
+func writeComposed(opts composeOptions, groups [][]candidateOutput) {
+	for _, outs := range groups {
+		if len(outs) < 2 {
+			continue
+		}
+		if err := composeAndWrite(opts, outs); err != nil {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "compose_failed", Input: outs[0].Req.SourcePath, Error: err.Error()})
+		}
+	}
+}
+
+func composeAndWrite(opts composeOptions, outs []candidateOutput) error {
+	input := outs[0].Req.SourcePath
+	composed, err := composeCandidates(opts, outs)
+	if err != nil {
+		return err
+	}
+	if composed == nil {
+		opts.Logger.Emit(logging.Event{Event: "compose_skipped", Input: input, Error: "各分段最佳版本均来自同一候选"})
+		return nil
+	}
+	enPath, cnPath := composedPaths(opts.OutDir, input)
+	if err := output.WriteFilesAtomic(contextOrBackground(opts.Context),
+		output.File{Path: enPath, Data: []byte(RenderMarkdown("en", composed.Req, composed.EN))},
+		output.File{Path: cnPath, Data: []byte(RenderMarkdown("cn", composed.Req, composed.CN))},
+	); err != nil {
+		return fmt.Errorf("写入组合结果失败：%w", err)
+	}
+	opts.Logger.Emit(logging.Event{Event: "compose_written", Input: input, OutputFile: enPath, Error: composed.summary(opts.Rules)})
+	return nil
+}
+
+// ComposeOptions 是 compose 子命令的参数：对已有候选输出做跨候选组合。
+type ComposeOptions struct {
+	Context     context.Context
+	Requirement string
+	// Candidates 为候选英文文件（listing_xxx_en.md），中文文件按同名 _cn.md 查找；
+	// 为空时读取输出目录下的 <需求名>_ranking.json。
+	Candidates []string
+	ConfigPath string
+	OutputDir  string
+	Provider   string
+	MaxRetries int
+	Cache      string
+	LogFile    string
+	Verbose    bool
+	CWD        string
+	Stdout     io.Writer
+}
+
+// Compose 读取需求文件与已有候选输出，组合各分段最佳版本并写入组合文件。
+func Compose(opts ComposeOptions) error {
+	cwd := strings.TrimSpace(opts.CWD)
+	if cwd == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("读取当前目录失败：%w", err)
+		}
+		cwd = wd
+	}
+	cfg, paths, err := config.Load(opts.ConfigPath, cwd)
+	if err != nil {
+		return err
+	}
+	overrideConfig(cfg, Options{OutputDir: opts.OutputDir, Provider: opts.Provider, MaxRetries: opts.MaxRetries, Cache: opts.Cache})
+	providers, err := resolveProviders(cfg)
+	if err != nil {
+		return err
+	}
+	logger, closer, err := logging.New(opts.Stdout, opts.LogFile, opts.Verbose, false)
+	if err != nil {
+		return fmt.Errorf("初始化日志失败：%w", err)
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	rules, err := syncAndReadRules(cfg, paths, logger, nil)
+	if err != nil {
+		return err
+	}
+	var apiKey string
+	if !providers.Mock {
+		if _, apiKey, err = ensureDeepSeekAPIKey(paths, cfg.APIKeyEnv); err != nil {
+			return err
+		}
+	}
+	reqPath := absPath(cwd, opts.Requirement)
+	req, err := listing.ParseFile(reqPath)
+	if err != nil {
+		return err
+	}
+	params, err := resolveRequirementParams(req, rules, cfg, providers.ProviderCfg)
+	if err != nil {
+		return fmt.Errorf("需求生成参数无效：%w", err)
+	}
+	rules = params.Rules
+	params.emit(logger, reqPath)
+	outDir, err := prepareOutDir(cfg, cwd)
+	if err != nil {
+		return err
+	}
+	outs, err := loadCandidateOutputs(req, rules, outDir, cwd, opts.Candidates)
+	if err != nil {
+		return err
+	}
+	if len(outs) < 2 {
+		return fmt.Errorf("至少需要 2 个候选才能组合，当前 %d 个", len(outs))
+	}
+	client, translateClient, err := newRunClients(cfg, paths, logger, nil)
+	if err != nil {
+		return err
+	}
+	return composeAndWrite(composeOptions{
+		Context:              contextOrBackground(opts.Context),
+		OutDir:               outDir,
+		CharTolerance:        params.CharTolerance,
+		Provider:             cfg.Provider,
+		ProviderCfg:          params.ProviderCfg,
+		TranslateProvider:    providers.TranslateProvider,
+		TranslateProviderCfg: providers.TranslateProviderCfg,
+		APIKey:               apiKey,
+		Rules:                rules,
+		MaxRetries:           cfg.MaxRetries,
+		Client:               client,
+		TranslateClient:      translateClient,
+		Logger:               logger,
+		Style:                newStylePolicy(cfg.Style),
+		FactCheck:            cfg.FactCheckEnabled(),
+		Protect:              cfg.Protect,
+	}, outs)
+}
+
+// loadCandidateOutputs 读取候选的中英文件；未指定文件时按评分报告中的候选列表读取。
+func loadCandidateOutputs(req listing.Requirement, rules config.SectionRules, outDir, cwd string, files []string) ([]candidateOutput, error) {
+	type pair struct {
+		candidate int
+		en, cn    string
+	}
+	var pairs []pair
+	if len(files) == 0 {
+		_, jsPath, _, _ := rankingPaths(outDir, req.SourcePath)
+		raw, err := os.ReadFile(jsPath)
+		if err != nil {
+			return nil, fmt.Errorf("未指定候选文件且读取评分报告失败：%w", err)
+		}
+		var report rankingReport
+		if err := json.Unmarshal(raw, &report); err != nil {
+			return nil, fmt.Errorf("解析评分报告失败（%s）：%w", jsPath, err)
+		}
+		for _, c := range report.Candidates {
+			pairs = append(pairs, pair{c.Candidate, filepath.Join(outDir, c.ENFile), filepath.Join(outDir, c.CNFile)})
+		}
+	}
+	for i, f := range files {
+		en := absPath(cwd, f)
+		if !strings.HasSuffix(en, "_en.md") {
+			return nil, fmt.Errorf("候选文件应为 _en.md：%s", f)
+		}
+		pairs = append(pairs, pair{i + 1, en, strings.TrimSuffix(en, "_en.md") + "_cn.md"})
+	}
+	outs := make([]candidateOutput, 0, len(pairs))
+	for _, p := range pairs {
+		out := candidateOutput{Req: req, Candidate: p.candidate, ENPath: p.en, CNPath: p.cn}
+		for _, side := range []struct {
+			lang, path string
+			doc        *ListingDocument
+		}{{"en", p.en, &out.EN}, {"cn", p.cn, &out.CN}} {
+			raw, err := os.ReadFile(side.path)
+			if err != nil {
+				return nil, fmt.Errorf("读取候选文件失败：%w", err)
+			}
+			doc, err := parseListingMarkdown(side.lang, rules, string(raw))
+			if err != nil {
+				return nil, fmt.Errorf("解析候选文件失败（%s）：%w", side.path, err)
+			}
+			*side.doc = doc
+		}
+		outs = append(outs, out)
+	}
+	return outs, nil
+}
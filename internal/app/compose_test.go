@@ -0,0 +1,160 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/translator"
+)
+
+func composeFixtureDocs() (ListingDocument, ListingDocument) {
+	en := ListingDocument{
+		Keywords:              []string{"alpha", "beta"},
+		Category:              "Cat",
+		Title:                 "Alpha Beta Organizer",
+		BulletPoints:          []string{"alpha box holds items", "beta lid closes well", "clear view for all", "easy to clean daily", "fits shelves nicely"},
+		DescriptionParagraphs: []string{"First paragraph.", "Second paragraph."},
+		SearchTerms:           "alpha beta",
+	}
+	cn := ListingDocument{
+		Keywords:              []string{"阿尔法", "贝塔"},
+		Category:              "分类",
+		Title:                 "阿尔法贝塔收纳盒",
+		BulletPoints:          []string{"一", "二", "三", "四", "五"},
+		DescriptionParagraphs: []string{"第一段。", "第二段。"},
+		SearchTerms:           "阿尔法 贝塔",
+	}
+	return en, cn
+}
+
+func TestParseListingMarkdownRoundTrip(t *testing.T) {
+	rules := testRules()
+	req := listing.Requirement{Brand: "BrandX"}
+	en, cn := composeFixtureDocs()
+	for lang, doc := range map[string]ListingDocument{"en": en, "cn": cn} {
+		got, err := parseListingMarkdown(lang, rules, RenderMarkdown(lang, req, doc))
+		if err != nil {
+			t.Fatalf("%s parse failed: %v", lang, err)
+		}
+		got.Order = nil
+		if !reflect.DeepEqual(got, doc) {
+			t.Fatalf("%s round trip mismatch:\n got %+v\nwant %+v", lang, got, doc)
+		}
+	}
+	if _, err := parseListingMarkdown("en", rules, "# BrandX Listing\n\n## Title\nx\n"); err == nil || !strings.Contains(err.Error(), "Bullet Points") {
+		t.Fatalf("missing section should fail: %v", err)
+	}
+}
+
+func TestPickSectionSourcesAndRegeneration(t *testing.T) {
+	report := rankingReport{Best: 2, Candidates: []rankingEntry{
+		{Rank: 1, Candidate: 2, documentScore: documentScore{Sections: []sectionScore{{Name: "title", Score: 70}, {Name: "bullets", Score: 90}}}},
+		{Rank: 2, Candidate: 1, documentScore: documentScore{Sections: []sectionScore{{Name: "title", Score: 80}, {Name: "bullets", Score: 90}}}},
+	}}
+	picks := pickSectionSources(report)
+	if picks["title"] != 1 || picks["bullets"] != 2 {
+		t.Fatalf("unexpected picks: %v", picks)
+	}
+	rules := testRules()
+	desc, _ := rules.Section("description")
+	bullets, _ := rules.Section("bullets")
+	if !needsRegeneration(desc, rules.Description, 2, map[string]int{"title": 1, "bullets": 2}) {
+		t.Fatalf("description on mixed title/bullets should regenerate")
+	}
+	if needsRegeneration(desc, rules.Description, 2, map[string]int{"title": 2, "bullets": 2}) {
+		t.Fatalf("description with same-source deps should be kept")
+	}
+	if needsRegeneration(bullets, rules.Bullets, 2, map[string]int{"title": 1}) {
+		t.Fatalf("line sections are picked as-is")
+	}
+}
+
+func TestComposeCandidatesRegeneratesDescriptionOnly(t *testing.T) {
+	rules := testRules()
+	rules.Description.Raw = "section: description\noutput:\n  paragraphs: 2\n"
+	req := listing.Requirement{SourcePath: "/in/demo.md", Brand: "BrandX", Category: "Cat", Keywords: []string{"alpha", "beta"}}
+	en1, cn1 := composeFixtureDocs()
+	en1.BulletPoints = []string{"x", "y", "z", "w", "v"}
+	cn1.BulletPoints = []string{"候选1一", "候选1二", "候选1三", "候选1四", "候选1五"}
+	en2, cn2 := composeFixtureDocs()
+	en2.Title = "Organizer"
+	cn2.Title = "候选2标题"
+	outs := []candidateOutput{
+		{Req: req, Candidate: 1, EN: en1, CN: cn1},
+		{Req: req, Candidate: 2, EN: en2, CN: cn2},
+	}
+	composed, err := composeCandidates(composeOptions{
+		Provider:          "mock",
+		ProviderCfg:       config.ProviderConfig{Model: "mock"},
+		TranslateProvider: "mock",
+		Rules:             rules,
+		MaxRetries:        1,
+		Client:            llm.NewClient(5 * time.Second),
+		TranslateClient:   translator.NewClient(5 * time.Second),
+	}, outs)
+	if err != nil || composed == nil {
+		t.Fatalf("compose failed: %v %v", composed, err)
+	}
+	// search_terms 在测试规则中走 text 协议，按得分直接选用，不随描述重新生成。
+	want := map[string]int{"title": 1, "bullets": 2, "description": 0, "search_terms": 2}
+	if !reflect.DeepEqual(composed.Sources, want) {
+		t.Fatalf("unexpected sources: %v", composed.Sources)
+	}
+	if composed.EN.Title != en1.Title || composed.CN.Title != cn1.Title || composed.CN.BulletPoints[0] != "一" {
+		t.Fatalf("picked sections should keep source candidate text: %+v / %+v", composed.EN, composed.CN)
+	}
+	if composed.EN.DescriptionParagraphs[0] == en1.DescriptionParagraphs[0] || !strings.Contains(composed.CN.DescriptionParagraphs[0], "模拟译文") {
+		t.Fatalf("description should be regenerated and re-translated: %+v", composed.CN.DescriptionParagraphs)
+	}
+	if !strings.Contains(composed.summary(rules), "title←候选1，bullets←候选2，description←重新生成") {
+		t.Fatalf("unexpected summary: %s", composed.summary(rules))
+	}
+
+	same, err := composeCandidates(composeOptions{Rules: rules}, []candidateOutput{outs[1], {Req: req, Candidate: 3, EN: en2, CN: cn2}})
+	if err != nil || same != nil {
+		t.Fatalf("identical candidates should skip compose: %v %v", same, err)
+	}
+}
+
+func TestComposeSubcommandReadsRankingReport(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	req, err := listing.ParseFile(reqPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := testRules()
+	en1, cn1 := composeFixtureDocs()
+	en1.BulletPoints = []string{"x", "y", "z", "w", "v"}
+	en2, cn2 := composeFixtureDocs()
+	en2.Title = "Organizer"
+	collector := newRankingCollector()
+	for i, pair := range [][2]ListingDocument{{en1, cn1}, {en2, cn2}} {
+		enPath := filepath.Join(workDir, "listing_c"+string(rune('1'+i))+"_en.md")
+		cnPath := strings.TrimSuffix(enPath, "_en.md") + "_cn.md"
+		_ = os.WriteFile(enPath, []byte(RenderMarkdown("en", req, pair[0])), 0o644)
+		_ = os.WriteFile(cnPath, []byte(RenderMarkdown("cn", req, pair[1])), 0o644)
+		collector.record(candidateOutput{Req: req, Candidate: i + 1, ENPath: enPath, CNPath: cnPath, EN: pair[0], CN: pair[1]})
+	}
+	writeRankings(rankingWriteOptions{OutDir: workDir, Rules: rules}, collector.groups())
+
+	if err := Compose(ComposeOptions{Requirement: reqPath, ConfigPath: cfgPath, CWD: workDir, Provider: "mock", Stdout: ioDiscard{}}); err != nil {
+		t.Fatalf("compose: %v", err)
+	}
+	enPath, cnPath := composedPaths(workDir, reqPath)
+	for _, p := range []string{enPath, cnPath} {
+		if _, err := os.Stat(p); err != nil {
+			t.Fatalf("missing composed output %s: %v", p, err)
+		}
+	}
+	if err := Compose(ComposeOptions{Requirement: reqPath, Candidates: []string{"listing_c1_en.md"}, ConfigPath: cfgPath, CWD: workDir, Provider: "mock", Stdout: ioDiscard{}}); err == nil {
+		t.Fatalf("single candidate should fail")
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/textsim"
+)
+
+// candidateDirection 是多候选差异化注入分段用户提示词的写作角度；零值表示不注入。
+type candidateDirection struct {
+	Angle string
+	// AvoidTitles 为同批已生成的相似标题，只在重新生成标题时注入。
+	AvoidTitles []string
+}
+
+func (d candidateDirection) prompt(step string) string {
+	var b strings.Builder
+	if d.Angle != "" {
+		b.WriteString("\n【本候选角度】")
+		b.WriteString(d.Angle)
+		b.WriteString("\n同一需求会生成多个候选，请按此角度组织内容，开头用词与句式不要套用常见模板。\n")
+	}
+	if step == "title" && len(d.AvoidTitles) > 0 {
+		b.WriteString("\n【避免雷同】以下为同批其他候选的标题，本标题必须换用不同的开头与结构：\n")
+		for _, t := range d.AvoidTitles {
+			b.WriteString("- ")
+			b.WriteString(t)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// candidateDiversity 在一次运行内为候选分配写作角度，并登记已生成的标题用于相似度检查；nil 表示关闭。
+type candidateDiversity struct {
+	angles           []string
+	maxSimilarity    float64
+	ngram            int
+	maxRegenerations int
+
+	mu     sync.Mutex
+	titles map[string][]string
+}
+
+func newCandidateDiversity(cfg config.DiversityConfig) *candidateDiversity {
+	if !cfg.IsEnabled() {
+		return nil
+	}
+	return &candidateDiversity{
+		angles:           cfg.Angles,
+		maxSimilarity:    cfg.MaxTitleSimilarity,
+		ngram:            cfg.NGram,
+		maxRegenerations: cfg.RegenerationLimit(),
+		titles:           map[string][]string{},
+	}
+}
+
+// direction 返回候选的写作角度：候选 N 取第 ((N-1) mod len) 个。
+func (d *candidateDiversity) direction(candidate int) candidateDirection {
+	if d == nil || len(d.angles) == 0 || candidate <= 0 {
+		return candidateDirection{}
+	}
+	return candidateDirection{Angle: strings.TrimSpace(d.angles[(candidate-1)%len(d.angles)])}
+}
+
+// claim 在同一把锁内比较并登记标题：与同一需求已登记标题的最高相似度不超过上限，或 force 为 true 时登记 title 并返回 ok=true；
+// 否则不登记，返回最相似的标题及其相似度。检查与登记不分开加锁，并发候选不会同时登记两个相似标题。
+func (d *candidateDiversity) claim(input, title string, force bool) (string, float64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	best, score := "", 0.0
+	for _, t := range d.titles[input] {
+		if s := textsim.NgramJaccard(t, title, d.ngram); s > score {
+			best, score = t, s
+		}
+	}
+	if score > d.maxSimilarity && !force {
+		return best, score, false
+	}
+	d.titles[input] = append(d.titles[input], title)
+	return best, score, true
+}
+
+// ensureDistinctTitle 在标题与同批已生成的标题过于相似时重新生成，最多 maxRegenerations 次；
+// 用尽后保留最后一次结果并告警。最终标题登记后供后续候选比较。
+func (d *candidateDiversity) ensureDistinctTitle(opts sectionGenerateOptions, sec config.ManifestSection, doc ListingDocument, items []string) ([]string, error) {
+	if d == nil || sec.Name != "title" || len(items) == 0 {
+		return items, nil
+	}
+	input := opts.Req.SourcePath
+	for attempt := 1; ; attempt++ {
+		similar, score, ok := d.claim(input, items[0], attempt > d.maxRegenerations)
+		msg := fmt.Sprintf("与已生成标题相似度 %.2f（上限 %.2f）：%s", score, d.maxSimilarity, similar)
+		if ok {
+			if score > d.maxSimilarity {
+				opts.Logger.Emit(logging.Event{Level: "warn", Event: "title_similar_kept", Input: input, Candidate: opts.Candidate, Lang: opts.Lang, Attempt: attempt - 1, Error: msg})
+			}
+			return items, nil
+		}
+		opts.Logger.Emit(logging.Event{Level: "warn", Event: "title_too_similar", Input: input, Candidate: opts.Candidate, Lang: opts.Lang, Attempt: attempt, Error: msg})
+		regen := opts
+		regen.Direction.AvoidTitles = []string{similar}
+		next, _, err := generateManifestSection(regen, sec, doc)
+		if err != nil {
+			return nil, err
+		}
+		items = next
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+)
+
+func TestCandidateDiversityDirection(t *testing.T) {
+	d := newCandidateDiversity(config.DiversityConfig{Angles: []string{"卖点", "场景"}})
+	if d.direction(1).Angle != "卖点" || d.direction(2).Angle != "场景" || d.direction(3).Angle != "卖点" {
+		t.Fatalf("angles should rotate by candidate")
+	}
+	var off *candidateDiversity
+	if off.direction(1).Angle != "" {
+		t.Fatalf("nil diversity should not inject angles")
+	}
+	disabled := false
+	if newCandidateDiversity(config.DiversityConfig{Enabled: &disabled}) != nil {
+		t.Fatalf("disabled diversity should be nil")
+	}
+
+	dir := candidateDirection{Angle: "场景", AvoidTitles: []string{"Old Title"}}
+	req := listing.Requirement{Category: "Cat", Keywords: []string{"alpha"}}
+	title := buildSectionUserPrompt("title", req, ListingDocument{}, dir, promptExtras{})
+	if !strings.Contains(title, "【本候选角度】场景") || !strings.Contains(title, "- Old Title") {
+		t.Fatalf("title prompt missing direction: %s", title)
+	}
+	if strings.Index(title, "【本候选角度】") > strings.Index(title, "【当前任务】") {
+		t.Fatalf("direction should precede the task line")
+	}
+	if bullets := buildSectionUserPrompt("bullets", req, ListingDocument{}, dir, promptExtras{}); strings.Contains(bullets, "Old Title") {
+		t.Fatalf("avoid list should only apply to title: %s", bullets)
+	}
+}
+
+func TestEnsureDistinctTitleRegeneratesSimilarTitle(t *testing.T) {
+	var mu sync.Mutex
+	var prompts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		user := body.Messages[1].Content
+		mu.Lock()
+		prompts = append(prompts, user)
+		mu.Unlock()
+		text := "alpha beta pocket organizer"
+		if strings.Contains(user, "【避免雷同】") {
+			text = "sturdy storage for alpha and beta"
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+
+	req := listing.Requirement{SourcePath: "/tmp/a.md", Category: "Cat", Keywords: []string{"alpha", "beta"}}
+	rules := testRules()
+	sec, _ := rules.Section("title")
+	d := newCandidateDiversity(config.DefaultDiversityConfig())
+	d.claim(req.SourcePath, "Alpha Beta Pocket Organizer", true)
+	opts := sectionGenerateOptions{
+		Req:         req,
+		Lang:        "en",
+		Provider:    "deepseek",
+		ProviderCfg: config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		APIKey:      "k",
+		Rules:       rules,
+		Client:      llm.NewClient(10 * time.Second),
+		Candidate:   2,
+		Direction:   d.direction(2),
+	}
+	doc := ListingDocument{Category: "Cat", Keywords: req.Keywords}
+	items, _, err := generateManifestSection(opts, sec, doc)
+	if err == nil {
+		items, err = d.ensureDistinctTitle(opts, sec, doc, items)
+	}
+	if err != nil {
+		t.Fatalf("generate title: %v", err)
+	}
+	if items[0] != "sturdy storage for alpha and beta" {
+		t.Fatalf("similar title should be regenerated: %q", items[0])
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "【本候选角度】以具体使用场景开头") || !strings.Contains(prompts[1], "- Alpha Beta Pocket Organizer") {
+		t.Fatalf("unexpected prompts: %q", prompts)
+	}
+	if _, score, ok := d.claim(req.SourcePath, "sturdy storage for alpha and beta", false); ok || score != 1 {
+		t.Fatalf("final title should be registered")
+	}
+
+	zero := 0
+	cfg := config.DefaultDiversityConfig()
+	cfg.MaxRegenerations = &zero
+	d = newCandidateDiversity(cfg)
+	d.claim(req.SourcePath, "Alpha Beta Pocket Organizer", true)
+	prompts = nil
+	items, err = d.ensureDistinctTitle(opts, sec, doc, []string{"alpha beta pocket organizer"})
+	if err != nil || items[0] != "alpha beta pocket organizer" || len(prompts) != 0 {
+		t.Fatalf("max_regenerations 0 should keep the title without regenerating: %v %q %d", err, items, len(prompts))
+	}
+	if len(d.titles[req.SourcePath]) != 2 {
+		t.Fatalf("kept title should still be registered: %v", d.titles)
+	}
+}
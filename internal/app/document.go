@@ -2,9 +2,11 @@ package app
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode/utf8"
 
+	"syl-listing/internal/config"
 	"syl-listing/internal/listing"
 )
 
@@ -15,75 +17,266 @@ type ListingDocument struct {
 	BulletPoints          []string
 	DescriptionParagraphs []string
 	SearchTerms           string
+	// Extra 按清单顺序保存内置四段以外的分段。
+	Extra []SectionContent
+	// Order 为分段渲染顺序；为空时按 title → bullets → description → search_terms。
+	Order []string
+}
+
+// SectionContent 是规则清单扩展分段的生成结果。
+type SectionContent struct {
+	Name string
+	// Kind 为 line/lines/paragraphs。
+	Kind string
+	// Heading 为当前语言的 Markdown 二级标题。
+	Heading string
+	Items   []string
+}
+
+var defaultSectionOrder = []string{"title", "bullets", "description", "search_terms"}
+
+// SectionItems 返回分段内容；单行分段返回一个元素。
+func (d ListingDocument) SectionItems(name string) []string {
+	switch name {
+	case "title":
+		if d.Title == "" {
+			return nil
+		}
+		return []string{d.Title}
+	case "bullets":
+		return d.BulletPoints
+	case "description":
+		return d.DescriptionParagraphs
+	case "search_terms":
+		if d.SearchTerms == "" {
+			return nil
+		}
+		return []string{d.SearchTerms}
+	}
+	if c, ok := d.extra(name); ok {
+		return c.Items
+	}
+	return nil
+}
+
+func (d ListingDocument) extra(name string) (SectionContent, bool) {
+	for _, c := range d.Extra {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return SectionContent{}, false
+}
+
+// setSection 按清单项写入分段内容，并记录渲染顺序。
+func (d *ListingDocument) setSection(sec config.ManifestSection, lang string, items []string) {
+	switch sec.Name {
+	case "title":
+		d.Title = firstItem(items)
+	case "bullets":
+		d.BulletPoints = items
+	case "description":
+		d.DescriptionParagraphs = items
+	case "search_terms":
+		d.SearchTerms = firstItem(items)
+	default:
+		content := SectionContent{Name: sec.Name, Kind: sec.Output, Heading: sec.HeadingFor(lang), Items: items}
+		replaced := false
+		for i := range d.Extra {
+			if d.Extra[i].Name == sec.Name {
+				d.Extra[i] = content
+				replaced = true
+			}
+		}
+		if !replaced {
+			d.Extra = append(d.Extra, content)
+		}
+	}
+	for _, name := range d.Order {
+		if name == sec.Name {
+			return
+		}
+	}
+	d.Order = append(d.Order, sec.Name)
+}
+
+// contextFor 返回只保留依赖分段的文档副本，作为生成下一段时的上下文。
+func (d ListingDocument) contextFor(deps []string) ListingDocument {
+	out := ListingDocument{
+		Keywords: d.Keywords,
+		Category: d.Category,
+	}
+	for _, name := range deps {
+		switch name {
+		case "title":
+			out.Title = d.Title
+		case "bullets":
+			out.BulletPoints = d.BulletPoints
+		case "description":
+			out.DescriptionParagraphs = d.DescriptionParagraphs
+		case "search_terms":
+			out.SearchTerms = d.SearchTerms
+		default:
+			if c, ok := d.extra(name); ok {
+				out.Extra = append(out.Extra, c)
+			}
+		}
+	}
+	return out
+}
+
+func (d ListingDocument) renderOrder() []string {
+	if len(d.Order) > 0 {
+		return d.Order
+	}
+	out := append([]string{}, defaultSectionOrder...)
+	for _, c := range d.Extra {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+func firstItem(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return items[0]
 }
 
 func RenderMarkdown(lang string, req listing.Requirement, doc ListingDocument) string {
 	var b strings.Builder
+	blocks := make([]string, 0, 8)
 	if lang == "en" {
 		b.WriteString("# ")
 		b.WriteString(strings.TrimSpace(req.Brand))
 		b.WriteString(" Listing\n\n")
-		b.WriteString("## Keywords\n")
-		for _, kw := range doc.Keywords {
-			b.WriteString(kw)
-			b.WriteString("\n")
-		}
-		b.WriteString("\n## Category\n")
-		b.WriteString(doc.Category)
-		b.WriteString("\n\n## Title\n")
-		b.WriteString(doc.Title)
-		b.WriteString("\n\n## Bullet Points\n")
-		for i, bp := range doc.BulletPoints {
-			b.WriteString(fmt.Sprintf("**Point %d**\n", i+1))
-			b.WriteString(bp)
-			b.WriteString("\n\n")
-		}
-		b.WriteString("## Product Description\n")
-		for i, p := range doc.DescriptionParagraphs {
-			if i > 0 {
-				b.WriteString("\n")
-			}
-			b.WriteString(p)
-			b.WriteString("\n")
-		}
-		b.WriteString("\n## Search Terms\n")
-		b.WriteString(doc.SearchTerms)
-		b.WriteString("\n")
-		return b.String()
-	}
-
-	b.WriteString("# ")
-	b.WriteString(strings.TrimSpace(req.Brand))
-	b.WriteString(" 产品Listing\n\n")
-	b.WriteString("## 关键词\n")
-	for _, kw := range doc.Keywords {
-		b.WriteString(kw)
-		b.WriteString("\n")
-	}
-	b.WriteString("\n## 分类\n")
-	b.WriteString(doc.Category)
-	b.WriteString("\n\n## 标题\n")
-	b.WriteString(doc.Title)
-	b.WriteString("\n\n## 五点描述\n")
-	for i, bp := range doc.BulletPoints {
-		b.WriteString(fmt.Sprintf("**第%d点**\n", i+1))
-		b.WriteString(bp)
-		b.WriteString("\n\n")
-	}
-	b.WriteString("## 产品描述\n")
-	for i, p := range doc.DescriptionParagraphs {
-		if i > 0 {
-			b.WriteString("\n")
-		}
-		b.WriteString(p)
-		b.WriteString("\n")
-	}
-	b.WriteString("\n## 搜索词\n")
-	b.WriteString(doc.SearchTerms)
+		blocks = append(blocks,
+			"## Keywords\n"+strings.Join(doc.Keywords, "\n"),
+			"## Category\n"+doc.Category,
+		)
+	} else {
+		b.WriteString("# ")
+		b.WriteString(strings.TrimSpace(req.Brand))
+		b.WriteString(" 产品Listing\n\n")
+		blocks = append(blocks,
+			"## 关键词\n"+strings.Join(doc.Keywords, "\n"),
+			"## 分类\n"+doc.Category,
+		)
+	}
+	for _, name := range doc.renderOrder() {
+		if block, ok := renderSectionBlock(lang, doc, name); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	b.WriteString(strings.Join(blocks, "\n\n"))
 	b.WriteString("\n")
 	return b.String()
 }
 
+func renderSectionBlock(lang string, doc ListingDocument, name string) (string, bool) {
+	heading := func(name string) string {
+		return "## " + config.BuiltinSection(name).HeadingFor(lang) + "\n"
+	}
+	switch name {
+	case "title":
+		return heading(name) + doc.Title, true
+	case "bullets":
+		points := make([]string, 0, len(doc.BulletPoints))
+		for i, bp := range doc.BulletPoints {
+			label := fmt.Sprintf("**Point %d**", i+1)
+			if lang != "en" {
+				label = fmt.Sprintf("**第%d点**", i+1)
+			}
+			points = append(points, label+"\n"+bp)
+		}
+		return heading(name) + strings.Join(points, "\n\n"), true
+	case "description":
+		return heading(name) + strings.Join(doc.DescriptionParagraphs, "\n\n"), true
+	case "search_terms":
+		return heading(name) + doc.SearchTerms, true
+	}
+	c, ok := doc.extra(name)
+	if !ok {
+		return "", false
+	}
+	sep := "\n"
+	if c.Kind == config.OutputParagraphs {
+		sep = "\n\n"
+	}
+	return "## " + c.Heading + "\n" + strings.Join(c.Items, sep), true
+}
+
+var pointLabelRe = regexp.MustCompile(`^\*\*(Point \d+|第\d+点)\*\*$`)
+
+// parseListingMarkdown 把 RenderMarkdown 的输出还原为文档，用于对已有输出做二次处理。
+// 分段按规则清单的标题识别；清单中的分段缺失时报错。
+func parseListingMarkdown(lang string, rules config.SectionRules, md string) (ListingDocument, error) {
+	blocks := splitMarkdownBlocks(md)
+	kwHeading, catHeading := "Keywords", "Category"
+	if lang != "en" {
+		kwHeading, catHeading = "关键词", "分类"
+	}
+	doc := ListingDocument{Category: blocks[catHeading]}
+	for _, line := range strings.Split(blocks[kwHeading], "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			doc.Keywords = append(doc.Keywords, line)
+		}
+	}
+	for _, sec := range rules.Sections() {
+		heading := sec.HeadingFor(lang)
+		text, ok := blocks[heading]
+		if !ok || text == "" {
+			return ListingDocument{}, fmt.Errorf("缺少分段：%s", heading)
+		}
+		doc.setSection(sec, lang, blockItems(sec, text))
+	}
+	return doc, nil
+}
+
+// splitMarkdownBlocks 按 ## 二级标题切分 Markdown，返回标题到正文的映射。
+func splitMarkdownBlocks(md string) map[string]string {
+	blocks := map[string]string{}
+	var current string
+	var body []string
+	flush := func() {
+		if current != "" {
+			blocks[current] = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "## ") {
+			flush()
+			current, body = strings.TrimSpace(strings.TrimPrefix(line, "## ")), nil
+			continue
+		}
+		if current != "" {
+			body = append(body, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// blockItems 按分段输出类型把正文拆成条目：段落按空行，单行取整块，多行逐行并跳过 **Point N** 标签。
+func blockItems(sec config.ManifestSection, text string) []string {
+	var items []string
+	switch {
+	case sec.Output == config.OutputParagraphs:
+		items = splitByBlankLines(text)
+	case sec.Output == config.OutputLine:
+		items = []string{strings.TrimSpace(text)}
+	default:
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || pointLabelRe.MatchString(line) {
+				continue
+			}
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
 func runeLen(s string) int {
 	return utf8.RuneCountInString(s)
 }
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"syl-listing/internal/config"
 	"syl-listing/internal/listing"
 )
 
@@ -36,3 +37,43 @@ func TestRuneLenAndDedupeIssues(t *testing.T) {
 		t.Fatalf("dedupeIssues: %#v", out)
 	}
 }
+
+func TestRenderMarkdownDefaultLayout(t *testing.T) {
+	req := listing.Requirement{Brand: "BrandX"}
+	doc := ListingDocument{
+		Title:                 "T",
+		Keywords:              []string{"k1", "k2"},
+		Category:              "Cat",
+		BulletPoints:          []string{"b1", "b2"},
+		DescriptionParagraphs: []string{"p1", "p2"},
+		SearchTerms:           "s1",
+	}
+	want := "# BrandX Listing\n\n## Keywords\nk1\nk2\n\n## Category\nCat\n\n## Title\nT\n\n## Bullet Points\n**Point 1**\nb1\n\n**Point 2**\nb2\n\n## Product Description\np1\n\np2\n\n## Search Terms\ns1\n"
+	if got := RenderMarkdown("en", req, doc); got != want {
+		t.Fatalf("layout changed:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRenderMarkdownManifestSections(t *testing.T) {
+	req := listing.Requirement{Brand: "BrandX"}
+	doc := ListingDocument{Keywords: []string{"k1"}, Category: "Cat"}
+	doc.setSection(config.ManifestSection{Name: "title", Output: config.OutputLine}, "cn", []string{"标题文本"})
+	doc.setSection(config.ManifestSection{Name: "product_name", Output: config.OutputLine, Heading: config.SectionHeading{EN: "Product Name", CN: "产品名"}}, "cn", []string{"收纳盒"})
+	doc.setSection(config.ManifestSection{Name: "subject_matter", Output: config.OutputLines}, "cn", []string{"a", "b"})
+	cn := RenderMarkdown("cn", req, doc)
+	for _, want := range []string{"## 标题\n标题文本\n\n## 产品名\n收纳盒\n\n## Subject Matter\na\nb\n"} {
+		if !strings.Contains(cn, want) {
+			t.Fatalf("missing %q in:\n%s", want, cn)
+		}
+	}
+	if strings.Contains(cn, "## 五点描述") {
+		t.Fatalf("sections absent from order should not render:\n%s", cn)
+	}
+	if got := doc.SectionItems("subject_matter"); len(got) != 2 {
+		t.Fatalf("SectionItems mismatch: %v", got)
+	}
+	ctx := doc.contextFor([]string{"product_name"})
+	if ctx.Title != "" || len(ctx.Extra) != 1 || ctx.Extra[0].Name != "product_name" {
+		t.Fatalf("contextFor should keep only deps: %+v", ctx)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"syl-listing/internal/llm"
+)
+
+// promptDump 收集 dry-run 期间每次分段请求的完整消息；nil 表示不收集。
+type promptDump struct {
+	mu      sync.Mutex
+	entries []promptDumpEntry
+}
+
+type promptDumpEntry struct {
+	Step     string
+	Attempt  int
+	Messages []llm.Message
+}
+
+func newPromptDump() *promptDump {
+	return &promptDump{}
+}
+
+func (d *promptDump) record(step string, attempt int, messages []llm.Message) {
+	if d == nil {
+		return
+	}
+	copied := append([]llm.Message{}, messages...)
+	d.mu.Lock()
+	d.entries = append(d.entries, promptDumpEntry{Step: step, Attempt: attempt, Messages: copied})
+	d.mu.Unlock()
+}
+
+func (d *promptDump) render(sourcePath string) string {
+	if d == nil {
+		return ""
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var b strings.Builder
+	b.WriteString("# Dry-run 提示词\n\n")
+	b.WriteString("需求文件：")
+	b.WriteString(filepath.Base(sourcePath))
+	b.WriteString("\n")
+	for _, e := range d.entries {
+		b.WriteString(fmt.Sprintf("\n## %s（第%d次）\n", e.Step, e.Attempt))
+		for _, m := range e.Messages {
+			b.WriteString(fmt.Sprintf("\n### %s\n\n````text\n", m.Role))
+			b.WriteString(strings.TrimRight(m.Content, "\n"))
+			b.WriteString("\n````\n")
+		}
+	}
+	return b.String()
+}
+
+func promptDumpPath(enPath string) string {
+	return strings.TrimSuffix(enPath, "_en.md") + "_prompts.md"
+}
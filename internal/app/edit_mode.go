@@ -0,0 +1,315 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/logging"
+)
+
+// 编辑模式下各分段相对现有文案的状态。
+const (
+	editStatusKept    = "unchanged"
+	editStatusRevised = "revised"
+	editStatusAdded   = "added"
+)
+
+// editSession 是一个候选在编辑模式下的状态：现有文案与各分段未通过的校验；nil 表示从零生成。
+type editSession struct {
+	Base ListingDocument
+
+	mu     sync.Mutex
+	issues map[string][]string
+}
+
+func newEditSession(base *ListingDocument) *editSession {
+	if base == nil {
+		return nil
+	}
+	return &editSession{Base: *base, issues: map[string][]string{}}
+}
+
+func (e *editSession) baseItems(name string) []string {
+	if e == nil {
+		return nil
+	}
+	return e.Base.SectionItems(name)
+}
+
+func (e *editSession) recordIssues(name string, issues []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.issues[name] = issues
+}
+
+func (e *editSession) sectionIssues(name string) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.issues[name]
+}
+
+// loadBaseListing 读取 --base 指定的现有英文 listing（listing_*_en.md 或同结构的 Markdown）。
+func loadBaseListing(path string, rules config.SectionRules) (*ListingDocument, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取现有 Listing 失败（%s）：%w", path, err)
+	}
+	doc, err := parseBaseListing(rules, string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s：%w", path, err)
+	}
+	return &doc, nil
+}
+
+// resolveEditBase 返回需求的编辑基准：--base 优先，其次是需求中的 # 现有Listing 块；都没有时返回 nil。
+func resolveEditBase(flagBase *ListingDocument, req listing.Requirement, rules config.SectionRules) (*ListingDocument, error) {
+	if flagBase != nil {
+		return flagBase, nil
+	}
+	if strings.TrimSpace(req.ExistingListing) == "" {
+		return nil, nil
+	}
+	doc, err := parseBaseListing(rules, req.ExistingListing)
+	if err != nil {
+		return nil, fmt.Errorf("# 现有Listing：%w", err)
+	}
+	return &doc, nil
+}
+
+// parseBaseListing 宽松解析现有文案：分段标题可用英文、中文或分段名（不区分大小写），缺失的分段留空，
+// 编辑时按新需求生成；一个分段都识别不到时报错。
+func parseBaseListing(rules config.SectionRules, md string) (ListingDocument, error) {
+	blocks := map[string]string{}
+	for heading, text := range splitMarkdownBlocks(md) {
+		blocks[strings.ToLower(heading)] = text
+	}
+	var doc ListingDocument
+	found := make([]string, 0, len(rules.Sections()))
+	for _, sec := range rules.Sections() {
+		text := ""
+		for _, heading := range baseHeadings(sec) {
+			if v := blocks[strings.ToLower(heading)]; v != "" {
+				text = v
+				break
+			}
+		}
+		if text == "" {
+			continue
+		}
+		items := cleanBlockItems(sec, text)
+		if len(items) == 0 {
+			continue
+		}
+		doc.setSection(sec, "en", items)
+		found = append(found, sec.Name)
+	}
+	if len(found) == 0 {
+		return ListingDocument{}, fmt.Errorf("现有 Listing 中没有可识别的分段（需要 ## Title、## Bullet Points 等二级标题）")
+	}
+	return doc, nil
+}
+
+// cleanBlockItems 把手写的分段正文拆成条目：去掉列表前缀与空条目。
+func cleanBlockItems(sec config.ManifestSection, text string) []string {
+	var items []string
+	for _, it := range blockItems(sec, text) {
+		if sec.Output == config.OutputLines {
+			it = cleanBulletLine(it)
+		}
+		if it = strings.TrimSpace(it); it != "" {
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+func baseHeadings(sec config.ManifestSection) []string {
+	return []string{sec.HeadingFor("en"), sec.HeadingFor("cn"), sec.Name}
+}
+
+// reviseBaseSection 在编辑模式下产出一个分段：现有文案通过规则、风格与数值校验时原样保留（kept=true，不调用模型），
+// 否则把现有文案与问题注入提示词，按最小改动修订；现有文案缺少该分段时按需求生成。
+func reviseBaseSection(opts sectionGenerateOptions, edit *editSession, sec config.ManifestSection, doc ListingDocument) ([]string, bool, error) {
+	base := edit.baseItems(sec.Name)
+	if len(base) == 0 {
+		items, _, err := generateManifestSection(opts, sec, doc)
+		return items, false, err
+	}
+	rule, err := opts.Rules.Get(sec.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	text, styleIssues := opts.Style.applySectionText(sec.Name, opts.Lang, rule, joinSectionItems(rule, base))
+	issues, _ := validateSectionText(sec.Name, opts.Lang, opts.Req, text, rule, opts.CharTolerance)
+	issues = dedupeIssues(append(append(issues, styleIssues...), opts.Facts.sectionIssues(sec.Name, opts.Lang, text)...))
+	if len(issues) == 0 {
+		items, err := splitSectionOutput(sec.Name, rule, text)
+		if err == nil {
+			opts.Logger.Emit(logging.Event{Event: "edit_section_kept", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: opts.Lang, Error: sec.Name})
+			return items, true, nil
+		}
+		issues = []string{err.Error()}
+	}
+	edit.recordIssues(sec.Name, issues)
+	opts.Logger.Emit(logging.Event{Event: "edit_section_revise", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: opts.Lang, Error: fmt.Sprintf("%s：%s", sec.Name, strings.Join(issues, "; "))})
+	opts.Extras.Existing, opts.Extras.ExistingIssues = base, issues
+	items, _, err := generateManifestSection(opts, sec, doc)
+	return items, false, err
+}
+
+// editSectionDiff 是对比报告中的一个分段。
+type editSectionDiff struct {
+	Name    string
+	Heading string
+	Status  string
+	Issues  []string
+	Old     []string
+	New     []string
+}
+
+// buildEditDiffs 按规则清单逐段对比现有文案与最终英文文档；以最终文档为准，评审修复后的改动也计入。
+func buildEditDiffs(rules config.SectionRules, edit *editSession, enDoc ListingDocument) []editSectionDiff {
+	out := make([]editSectionDiff, 0, len(rules.Sections()))
+	for _, sec := range rules.Sections() {
+		d := editSectionDiff{
+			Name:    sec.Name,
+			Heading: sec.HeadingFor("en"),
+			Old:     edit.baseItems(sec.Name),
+			New:     enDoc.SectionItems(sec.Name),
+		}
+		switch {
+		case len(d.Old) == 0:
+			d.Status = editStatusAdded
+		case sameItems(d.Old, d.New):
+			d.Status = editStatusKept
+		default:
+			d.Status = editStatusRevised
+			d.Issues = edit.sectionIssues(sec.Name)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func sameItems(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.Join(strings.Fields(a[i]), " ") != strings.Join(strings.Fields(b[i]), " ") {
+			return false
+		}
+	}
+	return true
+}
+
+var editStatusLabels = map[string]string{
+	editStatusKept:    "未改动",
+	editStatusRevised: "已修订",
+	editStatusAdded:   "新增",
+}
+
+// renderEditDiff 渲染 listing_<id>_diff.md：先列各分段状态，再逐条给出旧文、新文与词级差异（~~删除~~ **新增**）。
+func renderEditDiff(req listing.Requirement, diffs []editSectionDiff) string {
+	var b strings.Builder
+	b.WriteString("# 现有 Listing 修订对比\n\n")
+	b.WriteString("需求：")
+	b.WriteString(req.SourcePath)
+	b.WriteString("\n\n| 分段 | 状态 | 校验问题 |\n| --- | --- | --- |\n")
+	for _, d := range diffs {
+		b.WriteString(fmt.Sprintf("| %s | %s | %d |\n", d.Heading, editStatusLabels[d.Status], len(d.Issues)))
+	}
+	for _, d := range diffs {
+		b.WriteString(fmt.Sprintf("\n## %s（%s）\n", d.Heading, editStatusLabels[d.Status]))
+		if d.Status == editStatusKept {
+			b.WriteString("\n现有文案已满足规则，原样保留。\n")
+			continue
+		}
+		if len(d.Issues) > 0 {
+			b.WriteString("\n修订原因：\n")
+			for _, issue := range d.Issues {
+				b.WriteString("- ")
+				b.WriteString(issue)
+				b.WriteString("\n")
+			}
+		}
+		for i := 0; i < max(len(d.Old), len(d.New)); i++ {
+			oldText, newText := itemAt(d.Old, i), itemAt(d.New, i)
+			if len(d.Old) > 1 || len(d.New) > 1 {
+				b.WriteString(fmt.Sprintf("\n### 第%d项\n", i+1))
+			}
+			b.WriteString("\n- 旧：")
+			b.WriteString(firstNonEmpty(oldText, "（无）"))
+			b.WriteString("\n- 新：")
+			b.WriteString(firstNonEmpty(newText, "（无）"))
+			b.WriteString("\n- 差异：")
+			b.WriteString(diffWords(oldText, newText))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func itemAt(items []string, i int) string {
+	if i < len(items) {
+		return items[i]
+	}
+	return ""
+}
+
+// diffWords 按单词做最长公共子序列对比，删除的词用 ~~ ~~、新增的词用 ** ** 标出。
+func diffWords(oldText, newText string) string {
+	a, b := strings.Fields(oldText), strings.Fields(newText)
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out, del, ins []string
+	flush := func() {
+		if len(del) > 0 {
+			out = append(out, "~~"+strings.Join(del, " ")+"~~")
+		}
+		if len(ins) > 0 {
+			out = append(out, "**"+strings.Join(ins, " ")+"**")
+		}
+		del, ins = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			flush()
+			out = append(out, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			del = append(del, a[i])
+			i++
+		default:
+			ins = append(ins, b[j])
+			j++
+		}
+	}
+	del = append(del, a[i:]...)
+	ins = append(ins, b[j:]...)
+	flush()
+	return strings.Join(out, " ")
+}
+
+// editDiffSidecarPath 返回修订对比文件路径：listing_<id>_diff.md。
+func editDiffSidecarPath(enPath string) string {
+	return strings.TrimSuffix(enPath, "_en.md") + "_diff.md"
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/listing"
+)
+
+func TestParseBaseListingLenientHeadings(t *testing.T) {
+	rules := testRules()
+	md := strings.Join([]string{
+		"## 标题",
+		"Old Title",
+		"",
+		"## bullet points",
+		"- first point",
+		"**Point 2**",
+		"2. second point",
+		"",
+		"## Something Else",
+		"ignored",
+	}, "\n")
+	doc, err := parseBaseListing(rules, md)
+	if err != nil {
+		t.Fatalf("parseBaseListing error: %v", err)
+	}
+	if doc.Title != "Old Title" {
+		t.Fatalf("unexpected title: %q", doc.Title)
+	}
+	if len(doc.BulletPoints) != 2 || doc.BulletPoints[0] != "first point" || doc.BulletPoints[1] != "second point" {
+		t.Fatalf("unexpected bullets: %#v", doc.BulletPoints)
+	}
+	if doc.DescriptionParagraphs != nil || doc.SearchTerms != "" {
+		t.Fatalf("missing sections should stay empty: %#v", doc)
+	}
+	if _, err := parseBaseListing(rules, "## Notes\nnothing"); err == nil {
+		t.Fatalf("expected error without known sections")
+	}
+}
+
+func TestResolveEditBasePrefersFlag(t *testing.T) {
+	rules := testRules()
+	flagBase := &ListingDocument{Title: "From Flag"}
+	req := listing.Requirement{ExistingListing: "## Title\nFrom Requirement"}
+	if got, err := resolveEditBase(flagBase, req, rules); err != nil || got.Title != "From Flag" {
+		t.Fatalf("flag base should win: %#v %v", got, err)
+	}
+	if got, err := resolveEditBase(nil, req, rules); err != nil || got.Title != "From Requirement" {
+		t.Fatalf("requirement block should be used: %#v %v", got, err)
+	}
+	if got, err := resolveEditBase(nil, listing.Requirement{}, rules); err != nil || got != nil {
+		t.Fatalf("no base expected: %#v %v", got, err)
+	}
+	if _, err := resolveEditBase(nil, listing.Requirement{ExistingListing: "just text"}, rules); err == nil || !strings.Contains(err.Error(), "现有Listing") {
+		t.Fatalf("expected block error, got %v", err)
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	got := diffWords("Soft cotton shirt for men", "Soft organic cotton tee for men")
+	want := "Soft **organic** cotton ~~shirt~~ **tee** for men"
+	if got != want {
+		t.Fatalf("diffWords got %q want %q", got, want)
+	}
+	if got := diffWords("", "new text"); got != "**new text**" {
+		t.Fatalf("unexpected insert-only diff: %q", got)
+	}
+}
+
+func TestBuildEditDiffsStatuses(t *testing.T) {
+	rules := testRules()
+	edit := newEditSession(&ListingDocument{Title: "Old Title", BulletPoints: []string{"a", "b"}})
+	edit.recordIssues("bullets", []string{"五点数量错误：2 != 5"})
+	en, _ := composeFixtureDocs()
+	en.Title = "Old  Title"
+	diffs := buildEditDiffs(rules, edit, en)
+	status := map[string]string{}
+	for _, d := range diffs {
+		status[d.Name] = d.Status
+	}
+	if status["title"] != editStatusKept || status["bullets"] != editStatusRevised || status["description"] != editStatusAdded {
+		t.Fatalf("unexpected statuses: %v", status)
+	}
+	md := renderEditDiff(listing.Requirement{SourcePath: "req.md"}, diffs)
+	for _, want := range []string{"| Title | 未改动 | 0 |", "## Bullet Points（已修订）", "五点数量错误", "### 第5项", "- 旧：（无）"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("diff report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRunEditModeWithBase(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	run := func(base string) Result {
+		t.Helper()
+		res, err := Run(Options{
+			Inputs:     []string{reqPath},
+			ConfigPath: cfgPath,
+			CWD:        workDir,
+			Provider:   "mock",
+			Base:       base,
+			Stdout:     ioDiscard{},
+			Stderr:     ioDiscard{},
+		})
+		if err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+		return res
+	}
+	if res := run(""); res.Succeeded != 1 {
+		t.Fatalf("unexpected first run: %+v", res)
+	}
+	enFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md"))
+	if len(enFiles) != 1 {
+		t.Fatalf("expected one en output, got %v", enFiles)
+	}
+	if diffs, _ := filepath.Glob(filepath.Join(workDir, "listing_*_diff.md")); len(diffs) != 0 {
+		t.Fatalf("generation from scratch should not write diff: %v", diffs)
+	}
+	base := filepath.Join(workDir, "base_en.md")
+	if err := os.Rename(enFiles[0], base); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := run("base_en.md"); res.Succeeded != 1 || res.Failed != 0 {
+		t.Fatalf("unexpected edit run: %+v", res)
+	}
+	diffs, _ := filepath.Glob(filepath.Join(workDir, "listing_*_diff.md"))
+	if len(diffs) != 1 {
+		t.Fatalf("expected one diff report, got %v", diffs)
+	}
+	raw, _ := os.ReadFile(diffs[0])
+	if !strings.Contains(string(raw), "| Title | 未改动 |") || strings.Contains(string(raw), "已修订") {
+		t.Fatalf("valid base sections should be kept:\n%s", raw)
+	}
+	baseRaw, _ := os.ReadFile(base)
+	edited, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md"))
+	editedRaw, _ := os.ReadFile(edited[0])
+	if string(editedRaw) != string(baseRaw) {
+		t.Fatalf("kept listing should match base:\n%s\n---\n%s", editedRaw, baseRaw)
+	}
+}
+
+func TestPromptExtrasExistingPrompt(t *testing.T) {
+	dir := promptExtras{Existing: []string{"old one", "old two"}, ExistingIssues: []string{"五点数量错误：2 != 5"}}
+	got := dir.prompt("bullets")
+	for _, want := range []string{"【现有文案】", "1. old one\n2. old two", "最小改动", "- 五点数量错误：2 != 5"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, got)
+		}
+	}
+	if (promptExtras{}).prompt("bullets") != "" {
+		t.Fatalf("zero extras should add nothing")
+	}
+}
@@ -0,0 +1,309 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/logging"
+)
+
+// numericFact 是从文本中抽取的带单位数值（数量、尺寸、重量、容量、年龄/时长）。
+type numericFact struct {
+	// Raw 为原文片段（小写）。
+	Raw   string
+	Value float64
+	// Unit 为规范单位；空表示未带单位的尺寸数字（如 10x13）。
+	Unit string
+	// Kind 为 length/weight/volume/count/age；空同上。
+	Kind string
+	// Base 为换算到同类基准单位（mm、g、ml、件、月）后的数值。
+	Base float64
+}
+
+func (f numericFact) label() string {
+	if f.Unit == "" {
+		return formatFactNumber(f.Value)
+	}
+	return formatFactNumber(f.Value) + " " + f.Unit
+}
+
+type factUnit struct {
+	canon  string
+	kind   string
+	factor float64
+}
+
+var factUnits = map[string]factUnit{
+	"mm": {"mm", "length", 1}, "millimeter": {"mm", "length", 1}, "millimeters": {"mm", "length", 1}, "毫米": {"mm", "length", 1},
+	"cm": {"cm", "length", 10}, "centimeter": {"cm", "length", 10}, "centimeters": {"cm", "length", 10}, "厘米": {"cm", "length", 10},
+	"m": {"m", "length", 1000}, "meter": {"m", "length", 1000}, "meters": {"m", "length", 1000}, "米": {"m", "length", 1000},
+	"in": {"in", "length", 25.4}, "inch": {"in", "length", 25.4}, "inches": {"in", "length", 25.4}, "\"": {"in", "length", 25.4}, "”": {"in", "length", 25.4}, "英寸": {"in", "length", 25.4},
+	"ft": {"ft", "length", 304.8}, "foot": {"ft", "length", 304.8}, "feet": {"ft", "length", 304.8}, "英尺": {"ft", "length", 304.8},
+	"g": {"g", "weight", 1}, "gram": {"g", "weight", 1}, "grams": {"g", "weight", 1}, "克": {"g", "weight", 1},
+	"kg": {"kg", "weight", 1000}, "kilogram": {"kg", "weight", 1000}, "kilograms": {"kg", "weight", 1000}, "千克": {"kg", "weight", 1000}, "公斤": {"kg", "weight", 1000},
+	"oz": {"oz", "weight", 28.35}, "ounce": {"oz", "weight", 28.35}, "ounces": {"oz", "weight", 28.35}, "盎司": {"oz", "weight", 28.35},
+	"lb": {"lb", "weight", 453.6}, "lbs": {"lb", "weight", 453.6}, "pound": {"lb", "weight", 453.6}, "pounds": {"lb", "weight", 453.6}, "磅": {"lb", "weight", 453.6},
+	"ml": {"ml", "volume", 1}, "milliliter": {"ml", "volume", 1}, "milliliters": {"ml", "volume", 1}, "毫升": {"ml", "volume", 1},
+	"l": {"l", "volume", 1000}, "liter": {"l", "volume", 1000}, "liters": {"l", "volume", 1000}, "litre": {"l", "volume", 1000}, "litres": {"l", "volume", 1000}, "升": {"l", "volume", 1000},
+	"gallon": {"gal", "volume", 3785}, "gallons": {"gal", "volume", 3785}, "gal": {"gal", "volume", 3785}, "加仑": {"gal", "volume", 3785},
+	"pcs": {"pcs", "count", 1}, "pc": {"pcs", "count", 1}, "piece": {"pcs", "count", 1}, "pieces": {"pcs", "count", 1}, "count": {"pcs", "count", 1}, "ct": {"pcs", "count", 1},
+	"pack": {"pcs", "count", 1}, "packs": {"pcs", "count", 1}, "pk": {"pcs", "count", 1}, "sets": {"pcs", "count", 1},
+	"件": {"pcs", "count", 1}, "个": {"pcs", "count", 1}, "片": {"pcs", "count", 1}, "只": {"pcs", "count", 1}, "张": {"pcs", "count", 1}, "支": {"pcs", "count", 1}, "套": {"pcs", "count", 1}, "包": {"pcs", "count", 1}, "枚": {"pcs", "count", 1}, "根": {"pcs", "count", 1}, "条": {"pcs", "count", 1},
+	"year": {"years", "age", 12}, "years": {"years", "age", 12}, "yr": {"years", "age", 12}, "yrs": {"years", "age", 12}, "岁": {"years", "age", 12}, "年": {"years", "age", 12},
+	"month": {"months", "age", 1}, "months": {"months", "age", 1}, "个月": {"months", "age", 1},
+}
+
+const factNumberPattern = `\d+(?:,\d{3})*(?:\.\d+)?`
+
+var (
+	factUnitPattern = func() string {
+		keys := make([]string, 0, len(factUnits))
+		for k := range factUnits {
+			keys = append(keys, k)
+		}
+		// 长单位优先，避免 inches 被 in、厘米 被 米 截断。
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) > len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+		for i, k := range keys {
+			keys[i] = regexp.QuoteMeta(k)
+		}
+		return strings.Join(keys, "|")
+	}()
+	factDimRe    = regexp.MustCompile(`(` + factNumberPattern + `)\s*[x×*]\s*(` + factNumberPattern + `)(?:\s*[x×*]\s*(` + factNumberPattern + `))?(?:\s*(` + factUnitPattern + `))?`)
+	factPrefixRe = regexp.MustCompile(`\b(?:pack|set|box|bundle|case) of (` + factNumberPattern + `)|\bages?\s*(` + factNumberPattern + `)(?:\s*(?:-|–|to)\s*(` + factNumberPattern + `))?`)
+	factQtyRe    = regexp.MustCompile(`(` + factNumberPattern + `)(?:\s*(?:-|–|~|to|至|到)\s*(` + factNumberPattern + `))?(\s*-?\s*)(` + factUnitPattern + `)`)
+)
+
+// extractNumericFacts 抽取文本中的带单位数值：先识别尺寸（10x13 inches），
+// 再识别 pack of 12、ages 3-8 等前置写法，最后识别数值+单位与数值区间+单位。
+func extractNumericFacts(text string) []numericFact {
+	text = strings.ToLower(text)
+	used := make([]bool, len(text))
+	overlaps := func(start, end int) bool {
+		for i := start; i < end; i++ {
+			if used[i] {
+				return true
+			}
+		}
+		return false
+	}
+	mark := func(start, end int) {
+		for i := start; i < end; i++ {
+			used[i] = true
+		}
+	}
+	out := make([]numericFact, 0, 8)
+	add := func(raw, num string, unit factUnit, hasUnit bool) {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(num, ",", ""), 64)
+		if err != nil {
+			return
+		}
+		f := numericFact{Raw: raw, Value: v}
+		if hasUnit {
+			f.Unit, f.Kind, f.Base = unit.canon, unit.kind, v*unit.factor
+		}
+		out = append(out, f)
+	}
+
+	for _, m := range factDimRe.FindAllStringSubmatchIndex(text, -1) {
+		end := m[1]
+		unitText := ""
+		if m[8] >= 0 {
+			unitText = text[m[8]:m[9]]
+			if !factUnitBoundary(text, m[9], unitText) {
+				end = m[8]
+				unitText = ""
+			}
+		}
+		raw := strings.TrimSpace(text[m[0]:end])
+		unit, hasUnit := factUnits[unitText]
+		for g := 1; g <= 3; g++ {
+			if m[2*g] >= 0 {
+				add(raw, text[m[2*g]:m[2*g+1]], unit, hasUnit)
+			}
+		}
+		mark(m[0], end)
+	}
+	for _, m := range factPrefixRe.FindAllStringSubmatchIndex(text, -1) {
+		if overlaps(m[0], m[1]) {
+			continue
+		}
+		raw := text[m[0]:m[1]]
+		if m[2] >= 0 {
+			add(raw, text[m[2]:m[3]], factUnits["pcs"], true)
+		}
+		for g := 2; g <= 3; g++ {
+			if m[2*g] >= 0 {
+				add(raw, text[m[2*g]:m[2*g+1]], factUnits["years"], true)
+			}
+		}
+		mark(m[0], m[1])
+	}
+	for _, m := range factQtyRe.FindAllStringSubmatchIndex(text, -1) {
+		if overlaps(m[0], m[1]) {
+			continue
+		}
+		unitText := text[m[8]:m[9]]
+		if !factUnitBoundary(text, m[9], unitText) {
+			continue
+		}
+		// “2 in 1” 中的 in 是介词，只有紧贴数字时才当英寸。
+		if unitText == "in" && m[6] != m[7] {
+			continue
+		}
+		raw := text[m[0]:m[1]]
+		unit := factUnits[unitText]
+		add(raw, text[m[2]:m[3]], unit, true)
+		if m[4] >= 0 {
+			add(raw, text[m[4]:m[5]], unit, true)
+		}
+		mark(m[0], m[1])
+	}
+	return out
+}
+
+// factUnitBoundary 要求英文单位后面不能紧跟字母，避免把 5 great 里的 g 当成克。
+func factUnitBoundary(text string, end int, unit string) bool {
+	if unit == "" || end >= len(text) {
+		return true
+	}
+	last := unit[len(unit)-1]
+	next := text[end]
+	isASCIILetter := func(b byte) bool { return b >= 'a' && b <= 'z' }
+	return !isASCIILetter(last) || !isASCIILetter(next)
+}
+
+func formatFactNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// factSheet 保存需求原文中的数值事实，用来核对生成文案里的数值；nil 表示关闭核对。
+type factSheet struct {
+	facts []numericFact
+}
+
+func newFactSheet(req listing.Requirement) *factSheet {
+	body := req.BodyAfterMarker
+	if strings.TrimSpace(body) == "" {
+		body = req.Raw
+	}
+	return &factSheet{facts: extractNumericFacts(body)}
+}
+
+// known 判断数值是否出现在需求中：同类单位换算后相差 2% 以内视为一致（10 inches 与 25.4 cm），
+// 任一侧没有单位时只比较数值。
+func (s *factSheet) known(f numericFact) bool {
+	for _, r := range s.facts {
+		if f.Kind == "" || r.Kind == "" {
+			if factClose(f.Value, r.Value) {
+				return true
+			}
+			continue
+		}
+		if f.Kind == r.Kind && factClose(f.Base, r.Base) {
+			return true
+		}
+	}
+	return false
+}
+
+func factClose(a, b float64) bool {
+	return math.Abs(a-b) <= 0.02*math.Max(math.Abs(a), math.Abs(b))+1e-9
+}
+
+// unknownFacts 返回文本中需求里没有的数值，同一数值只报一次。
+func (s *factSheet) unknownFacts(text string) []numericFact {
+	out := make([]numericFact, 0, 2)
+	seen := map[string]bool{}
+	for _, f := range extractNumericFacts(text) {
+		if s.known(f) || seen[f.label()] {
+			continue
+		}
+		seen[f.label()] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+func (s *factSheet) issues(label, text string) []string {
+	unknown := s.unknownFacts(text)
+	if len(unknown) == 0 {
+		return nil
+	}
+	parts := make([]string, 0, len(unknown))
+	for _, f := range unknown {
+		parts = append(parts, fmt.Sprintf("%s（“%s”）", f.label(), f.Raw))
+	}
+	return []string{fmt.Sprintf("%s出现需求中没有的数值：%s；请改用需求中的数值或删除", label, strings.Join(parts, "、"))}
+}
+
+// sectionIssues 核对整段英文文本；关键词与分类不生成，不核对。
+func (s *factSheet) sectionIssues(step, lang, text string) []string {
+	if s == nil || lang != "en" {
+		return nil
+	}
+	return s.issues(sectionLabel(step), text)
+}
+
+// itemIssues 核对逐条生成的英文条目。
+func (s *factSheet) itemIssues(step, lang string, idx int, line string) []string {
+	if s == nil || lang != "en" {
+		return nil
+	}
+	label := fmt.Sprintf("第%d行", idx)
+	if step == "bullets" {
+		label = fmt.Sprintf("第%d条", idx)
+	}
+	return s.issues(label, line)
+}
+
+// table 渲染逐分段的数值核对表，写入 verbose 日志。
+func (s *factSheet) table(doc ListingDocument, sections []config.ManifestSection) string {
+	var b strings.Builder
+	b.WriteString("| 分段 | 原文 | 数值 | 需求中 |\n|---|---|---|---|\n")
+	rows := 0
+	for _, sec := range sections {
+		for i, item := range doc.SectionItems(sec.Name) {
+			name := sec.Name
+			if len(doc.SectionItems(sec.Name)) > 1 {
+				name = fmt.Sprintf("%s_%d", sec.Name, i+1)
+			}
+			for _, f := range extractNumericFacts(item) {
+				status := "✓"
+				if !s.known(f) {
+					status = "✗"
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", name, f.Raw, f.label(), status)
+				rows++
+			}
+		}
+	}
+	if rows == 0 {
+		b.WriteString("| - | - | - | - |\n")
+	}
+	return b.String()
+}
+
+// emitFactCheckTable 在 verbose 模式下输出英文文档的数值核对表。
+func emitFactCheckTable(opts sectionGenerateOptions, doc ListingDocument) {
+	if opts.Facts == nil || !opts.Logger.Verbose() {
+		return
+	}
+	opts.Logger.Emit(logging.Event{
+		Event:        "fact_check",
+		Input:        opts.Req.SourcePath,
+		Candidate:    opts.Candidate,
+		Lang:         opts.Lang,
+		ResponseText: opts.Facts.table(doc, opts.Rules.Sections()),
+	})
+}
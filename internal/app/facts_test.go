@@ -0,0 +1,147 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
+)
+
+func factLabels(facts []numericFact) []string {
+	out := make([]string, 0, len(facts))
+	for _, f := range facts {
+		out = append(out, f.label())
+	}
+	return out
+}
+
+func TestExtractNumericFacts(t *testing.T) {
+	cases := map[string]string{
+		"Pack of 12 dry erase pockets":           "12 pcs",
+		"10x13 inches sheet, 2 in 1 design":      "10 in,13 in",
+		"尺寸：10×13英寸，数量：10件，重量 1.5kg":             "10 in,13 in,10 pcs,1.5 kg",
+		"Suitable for ages 3-8, holds 500 ml":    "3 years,8 years,500 ml",
+		"3-8岁儿童，24 count, 5 great colors":        "3 years,8 years,24 pcs",
+		"measures 12\" wide and 30 cm tall":      "12 in,30 cm",
+		"1,000 sheets of 8.5 x 11 paper, 2-pack": "8.5,11,2 pcs",
+	}
+	for in, want := range cases {
+		if got := strings.Join(factLabels(extractNumericFacts(in)), ","); got != want {
+			t.Fatalf("extract(%q)=%q want %q", in, got, want)
+		}
+	}
+}
+
+func TestFactSheetKnownAndIssues(t *testing.T) {
+	sheet := newFactSheet(listing.Requirement{BodyAfterMarker: "数量：10件\n尺寸：10x13英寸\n适用年龄：3-8岁\n重量：1 lb"})
+	if issues := sheet.sectionIssues("title", "en", "10 Pack Dry Erase Pockets, 10 x 13 Inches (25.4 x 33 cm), Ages 3+, 16 oz"); len(issues) != 0 {
+		t.Fatalf("converted and matching facts should pass: %v", issues)
+	}
+	issues := sheet.sectionIssues("title", "en", "Pack of 12 Pockets, 10x12 Inches")
+	if len(issues) != 1 || !strings.Contains(issues[0], "12 pcs（“pack of 12”）") || !strings.Contains(issues[0], "12 in（“10x12 inches”）") {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+	if !strings.HasPrefix(issues[0], "标题出现需求中没有的数值") {
+		t.Fatalf("issue should carry section label: %v", issues)
+	}
+	if got := sheet.itemIssues("bullets", "en", 2, "Holds 5 kg"); len(got) != 1 || !strings.HasPrefix(got[0], "第2条") {
+		t.Fatalf("unexpected item issues: %v", got)
+	}
+	if sheet.sectionIssues("title", "cn", "12件") != nil {
+		t.Fatalf("cn text should not be checked")
+	}
+	var off *factSheet
+	if off.sectionIssues("title", "en", "Pack of 12") != nil || off.itemIssues("bullets", "en", 1, "12 pcs") != nil {
+		t.Fatalf("nil sheet should be a no-op")
+	}
+}
+
+func TestFactSheetIgnoresExistingListing(t *testing.T) {
+	req, err := listing.Parse("a.md", "===Listing Requirements===\n数量：10件\n# 现有Listing\n## Title\nPack of 12 Pockets\n# 关键词库\n- a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(req.BodyAfterMarker, "Pack of 12") {
+		t.Fatalf("existing listing should not stay in requirement body: %q", req.BodyAfterMarker)
+	}
+	issues := newFactSheet(req).sectionIssues("title", "en", "Pack of 12 Pockets")
+	if len(issues) != 1 || !strings.Contains(issues[0], "12 pcs") {
+		t.Fatalf("stale number from the old listing should be flagged: %v", issues)
+	}
+}
+
+func TestFactCheckTableInVerboseLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _, err := logging.New(&buf, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := ListingDocument{Title: "Pack of 10 Pockets", BulletPoints: []string{"Size: 10x13 inches", "Holds 5 kg"}}
+	emitFactCheckTable(sectionGenerateOptions{
+		Lang:   "en",
+		Rules:  testRules(),
+		Logger: logger,
+		Facts:  newFactSheet(listing.Requirement{BodyAfterMarker: "10件 10x13英寸"}),
+	}, doc)
+	var ev logging.Event
+	if err := json.Unmarshal(buf.Bytes(), &ev); err != nil {
+		t.Fatalf("decode event: %v (%s)", err, buf.String())
+	}
+	for _, want := range []string{"| title | pack of 10 | 10 pcs | ✓ |", "| bullets_1 | 10x13 inches | 13 in | ✓ |", "| bullets_2 | 5 kg | 5 kg | ✗ |"} {
+		if ev.Event != "fact_check" || !strings.Contains(ev.ResponseText, want) {
+			t.Fatalf("missing %q in %s: %s", want, ev.Event, ev.ResponseText)
+		}
+	}
+}
+
+func TestGenerateSectionWithRetryRepairsInventedNumbers(t *testing.T) {
+	var calls int
+	var repairPrompt string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		calls++
+		text := "alpha beta pockets, pack of 12"
+		if calls > 1 {
+			repairPrompt = body.Messages[len(body.Messages)-1].Content
+			text = "alpha beta pockets, pack of 10"
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+	req := listing.Requirement{SourcePath: "/tmp/a.md", BodyAfterMarker: "数量：10件", Category: "Cat", Keywords: []string{"alpha", "beta"}}
+	text, _, err := generateSectionWithRetry(sectionGenerateOptions{
+		Req:         req,
+		Lang:        "en",
+		Provider:    "deepseek",
+		ProviderCfg: config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		APIKey:      "k",
+		Rules:       testRules(),
+		MaxRetries:  1,
+		Client:      llm.NewClient(10 * time.Second),
+		Candidate:   1,
+		Facts:       newFactSheet(req),
+	}, "title", ListingDocument{Category: "Cat", Keywords: req.Keywords})
+	if err != nil {
+		t.Fatalf("generate title: %v", err)
+	}
+	if text != "alpha beta pockets, pack of 10" {
+		t.Fatalf("unexpected title: %q", text)
+	}
+	if !strings.Contains(repairPrompt, "需求中没有的数值：12 pcs") {
+		t.Fatalf("fact issue should reach repair prompt: %q", repairPrompt)
+	}
+}
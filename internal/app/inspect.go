@@ -0,0 +1,443 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"syl-listing/internal/config"
+)
+
+// InspectOptions 是 inspect 子命令的参数：在终端逐段审阅已有输出。
+type InspectOptions struct {
+	ListingFileOptions
+	// Stdin 为交互输入，默认 os.Stdin。
+	Stdin io.Reader
+}
+
+// Inspect 按规则清单逐段展示中英文、长度与关键词，由用户接受、附加要求重新生成或手工编辑；
+// 有改动且确认保存时原地改写中英文件，原文件备份为 .bak。放弃或输入结束时不写盘。
+func Inspect(opts InspectOptions) error {
+	if opts.Stdin == nil {
+		opts.Stdin = os.Stdin
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	s, err := openListingSession(opts.ListingFileOptions)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	r := newSectionInspector(s.Gen.Context, s, opts.Stdin, opts.Stdout)
+	defer r.stop()
+	return r.run()
+}
+
+// sectionInspector 是一次交互审阅：逐行读取输入，改动只作用于会话中的文档，保存时才写盘。
+type sectionInspector struct {
+	ctx     context.Context
+	s       *listingSession
+	out     io.Writer
+	lines   chan string
+	done    chan struct{}
+	changed []string
+}
+
+func newSectionInspector(ctx context.Context, s *listingSession, in io.Reader, out io.Writer) *sectionInspector {
+	r := &sectionInspector{ctx: ctx, s: s, out: out, lines: make(chan string), done: make(chan struct{})}
+	// 单独的 goroutine 读输入，等待输入时也能响应中断。
+	go func() {
+		defer close(r.lines)
+		sc := bufio.NewScanner(in)
+		for sc.Scan() {
+			select {
+			case r.lines <- sc.Text():
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+func (r *sectionInspector) stop() {
+	close(r.done)
+}
+
+// readLine 返回下一行输入；输入结束或被中断时 ok=false。
+func (r *sectionInspector) readLine() (string, bool) {
+	select {
+	case line, ok := <-r.lines:
+		return strings.TrimRight(line, "\r"), ok
+	case <-r.ctx.Done():
+		return "", false
+	}
+}
+
+func (r *sectionInspector) ask(prompt string) (string, bool) {
+	fmt.Fprint(r.out, prompt)
+	line, ok := r.readLine()
+	return strings.TrimSpace(line), ok
+}
+
+func (r *sectionInspector) run() error {
+	for {
+		finish, err := r.pass()
+		if finish != "save" {
+			return err
+		}
+		if len(r.changed) == 0 {
+			fmt.Fprintln(r.out, "没有改动，未改写文件")
+			return nil
+		}
+		if err := r.s.save("inspect_written", strings.Join(r.changed, "、")); err != nil {
+			// 保存失败不退出：会话中的改动仍在，回到第一段继续修改后再保存。
+			fmt.Fprintf(r.out, "保存失败：%v\n改动仍保留在本次审阅中，请修改后再保存\n", err)
+			continue
+		}
+		fmt.Fprintf(r.out, "已保存：%s（原文件备份为 .bak）\n", r.s.ENPath)
+		// 逐段审阅后保存即视为人工确认，此时才记入翻译记忆。
+		recordTranslationMemory(r.s.memory, r.s.Rules, r.s.EN, r.s.CN)
+		saveTranslationMemory(r.s.memory, r.s.logger)
+		return nil
+	}
+}
+
+// pass 逐段审阅一遍；返回 "save" 表示应保存，否则为放弃或输入结束，err 为退出原因。
+func (r *sectionInspector) pass() (string, error) {
+	sections := r.s.Rules.Sections()
+sections:
+	for i, sec := range sections {
+		for {
+			r.show(i+1, len(sections), sec)
+			action, ok := r.action()
+			if !ok {
+				return "", r.abort()
+			}
+			switch action {
+			case "a":
+				continue sections
+			case "r":
+				r.regenerate(sec)
+			case "e":
+				r.edit(sec, "en")
+			case "c":
+				r.edit(sec, "cn")
+			case "s":
+				break sections
+			case "q":
+				fmt.Fprintln(r.out, "已放弃改动，未写入文件")
+				return "", nil
+			}
+		}
+	}
+	return "save", nil
+}
+
+func (r *sectionInspector) abort() error {
+	if err := r.ctx.Err(); err != nil {
+		fmt.Fprintln(r.out)
+		return fmt.Errorf("审阅已中断，未写入文件")
+	}
+	fmt.Fprintln(r.out, "\n输入结束，放弃改动，未写入文件")
+	return nil
+}
+
+// action 读取一个操作，直到输入可识别的操作为止。
+func (r *sectionInspector) action() (string, bool) {
+	for {
+		line, ok := r.ask("操作：[a]接受 [r]重新生成 [e]编辑英文 [c]编辑中文 [s]接受其余并保存 [q]放弃 > ")
+		if !ok {
+			return "", false
+		}
+		switch action := strings.ToLower(line); action {
+		case "a", "r", "e", "c", "s", "q":
+			return action, true
+		case "":
+		default:
+			fmt.Fprintf(r.out, "无法识别的操作：%s\n", line)
+		}
+	}
+}
+
+func (r *sectionInspector) markChanged(name string) {
+	for _, n := range r.changed {
+		if n == name {
+			return
+		}
+	}
+	r.changed = append(r.changed, name)
+}
+
+// regenerate 读取附加要求（多行分段可指定条目）后重新生成，失败时保留原文。
+func (r *sectionInspector) regenerate(sec config.ManifestSection) {
+	target := sectionTarget{Name: sec.Name}
+	if n := len(r.s.EN.SectionItems(sec.Name)); sec.Output == config.OutputLines && n > 1 && providerSupportsJSONMode(r.s.Gen.Provider) {
+		for {
+			line, ok := r.ask(fmt.Sprintf("重新生成第几条（1-%d，回车为整段）> ", n))
+			if !ok {
+				return
+			}
+			if line == "" {
+				break
+			}
+			if item, err := strconv.Atoi(line); err == nil && item >= 1 && item <= n {
+				target.Item = item
+				break
+			}
+			fmt.Fprintf(r.out, "序号应在 1-%d 之间\n", n)
+		}
+	}
+	note, ok := r.ask("附加要求（回车跳过）> ")
+	if !ok {
+		return
+	}
+	fmt.Fprintf(r.out, "正在重新生成 %s ...\n", target)
+	en, cn, err := regenerateSection(r.s.Gen, sec, target, note, r.s.EN, r.s.CN)
+	if err != nil {
+		fmt.Fprintf(r.out, "重新生成失败，保留原文：%v\n", err)
+		return
+	}
+	r.s.EN, r.s.CN = en, cn
+	r.markChanged(sec.Name)
+}
+
+// edit 读取手写的新内容：编辑英文后重新产出中文，编辑中文只替换中文。
+func (r *sectionInspector) edit(sec config.ManifestSection, lang string) {
+	fmt.Fprintln(r.out, "输入新内容：每条一行，段落之间空一行；单独一行 . 结束，单独一行 .q 取消")
+	var lines []string
+	for {
+		line, ok := r.readLine()
+		if !ok || strings.TrimSpace(line) == ".q" {
+			fmt.Fprintln(r.out, "已取消编辑")
+			return
+		}
+		if strings.TrimSpace(line) == "." {
+			break
+		}
+		lines = append(lines, line)
+	}
+	text := strings.Join(lines, "\n")
+	if sec.Output == config.OutputLine {
+		text = normalizeSingleLine(text)
+	}
+	items := cleanBlockItems(sec, text)
+	if len(items) == 0 {
+		fmt.Fprintln(r.out, "未输入内容，保持原样")
+		return
+	}
+	if !r.checkEdit(sec, lang, items) {
+		return
+	}
+	if lang == "cn" {
+		r.s.CN.setSection(sec, "cn", items)
+		r.markChanged(sec.Name)
+		return
+	}
+	fmt.Fprintln(r.out, "正在重新产出中文 ...")
+	cn, err := r.s.Gen.translateCNSection(sec, items, r.s.CN)
+	if err != nil {
+		fmt.Fprintf(r.out, "中文产出失败，英文改动未应用：%v\n", err)
+		return
+	}
+	r.s.EN.setSection(sec, "en", items)
+	r.s.CN.setSection(sec, "cn", cn)
+	r.markChanged(sec.Name)
+}
+
+// checkEdit 在录入时按保存时的文档校验检查手工编辑，不通过时提示问题并不应用改动。
+func (r *sectionInspector) checkEdit(sec config.ManifestSection, lang string, items []string) bool {
+	doc := r.s.EN
+	if lang == "cn" {
+		doc = r.s.CN
+	}
+	next := doc
+	next.Extra = append([]SectionContent{}, doc.Extra...)
+	next.setSection(sec, lang, items)
+	if err := validateDocumentBySectionRules(lang, r.s.Req, next, r.s.Rules); err != nil {
+		fmt.Fprintf(r.out, "编辑未通过校验，改动未应用：%v\n", err)
+		return false
+	}
+	return true
+}
+
+// show 输出一个分段的审阅视图：中英文逐条长度与规则区间、关键词命中与校验问题。
+func (r *sectionInspector) show(idx, total int, sec config.ManifestSection) {
+	s := r.s
+	fmt.Fprintf(r.out, "\n== [%d/%d] %s（%s）==\n", idx, total, sec.HeadingFor("en"), sec.Name)
+	enItems := s.EN.SectionItems(sec.Name)
+	rule, err := s.Rules.Get(sec.Name)
+	if err != nil {
+		fmt.Fprintf(r.out, "读取规则失败：%v\n", err)
+		return
+	}
+	fmt.Fprintln(r.out, "EN：")
+	r.showItems(enItems, inspectItemBounds(sec, rule, s.Gen.CharTolerance), s.Req.Keywords)
+	cnItems := s.CN.SectionItems(sec.Name)
+	cnRule, hasCNRule := s.Rules.CNRule(sec.Name)
+	var cnBounds charBounds
+	if hasCNRule {
+		cnBounds = inspectItemBounds(sec, cnRule, s.Gen.CharTolerance)
+	}
+	fmt.Fprintln(r.out, "CN：")
+	r.showItems(cnItems, cnBounds, nil)
+
+	text := joinSectionItems(rule, enItems)
+	hits := matchedKeywords(s.Req.Keywords, text)
+	_, missing := keywordCoverage(s.Req.Keywords, joinDocumentText(s.Rules, s.EN))
+	fmt.Fprintf(r.out, "关键词：本段命中 %d/%d（%s）；全文未命中：%s\n", len(hits), len(nonEmpty(s.Req.Keywords)), fallbackJoin(hits), fallbackJoin(missing))
+
+	checked, issues := s.Gen.Style.applySectionText(sec.Name, "en", rule, text)
+	errs, warnings := validateSectionText(sec.Name, "en", s.Req, checked, rule, s.Gen.CharTolerance)
+	issues = append(errs, issues...)
+	if hasCNRule {
+		cnErrs, cnWarnings := validateCNItems(sec, cnItems, cnRule, s.Gen.CharTolerance)
+		issues, warnings = append(issues, cnErrs...), append(warnings, cnWarnings...)
+	}
+	issues = dedupeIssues(issues)
+	if len(issues) == 0 && len(warnings) == 0 {
+		fmt.Fprintln(r.out, "校验：通过")
+		return
+	}
+	fmt.Fprintln(r.out, "校验：")
+	for _, issue := range issues {
+		fmt.Fprintf(r.out, "  ✗ %s\n", issue)
+	}
+	for _, w := range dedupeIssues(warnings) {
+		fmt.Fprintf(r.out, "  ! %s\n", w)
+	}
+}
+
+func (r *sectionInspector) showItems(items []string, bounds charBounds, keywords []string) {
+	if len(items) == 0 {
+		fmt.Fprintln(r.out, "  （空）")
+		return
+	}
+	for i, it := range items {
+		fmt.Fprintf(r.out, "  %d. %s\n     %s\n", i+1, highlightKeywords(it, keywords), lengthStatus(bounds, it))
+	}
+}
+
+// inspectItemBounds 返回逐条展示时的长度区间：单行分段看 max_chars，多行与段落看每行区间。
+func inspectItemBounds(sec config.ManifestSection, rule config.SectionRuleFile, tolerance int) charBounds {
+	cons := rule.Parsed.Constraints
+	if sec.Output == config.OutputLine {
+		return resolveRuleBounds(rule.Parsed, config.RuleIntConstraint{}, cons.MaxChars, tolerance)
+	}
+	return resolveRuleBounds(rule.Parsed, cons.MinCharsPerLine, cons.MaxCharsPerLine, tolerance)
+}
+
+// lengthStatus 标出长度相对规则的位置：✓ 在规则内，≈ 只在容差内，✗ 超出容差；没有长度规则时只给长度。
+func lengthStatus(b charBounds, text string) string {
+	n, unit := b.measure(text), b.unit()
+	if !b.hasRule() {
+		return fmt.Sprintf("长度 %d %s", n, unit)
+	}
+	return fmt.Sprintf("长度 %d %s %s 规则 %s 容差 %s", n, unit, lengthMark(b, n), b.ruleText(), b.toleranceText())
+}
+
+func lengthMark(b charBounds, n int) string {
+	switch {
+	case b.inRule(n):
+		return "✓"
+	case b.inTolerance(n):
+		return "≈"
+	default:
+		return "✗"
+	}
+}
+
+// highlightKeywords 用 [ ] 标出文本中的关键词（不区分大小写，长词优先，不重叠）；纯文本，不依赖终端颜色。
+func highlightKeywords(text string, keywords []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		return text
+	}
+	kws := nonEmpty(keywords)
+	sort.SliceStable(kws, func(i, j int) bool { return len(kws[i]) > len(kws[j]) })
+	marked := make([]bool, len(text))
+	type span struct{ start, end int }
+	var spans []span
+	for _, kw := range kws {
+		kw = strings.ToLower(kw)
+		for from := 0; from < len(lower); {
+			i := strings.Index(lower[from:], kw)
+			if i < 0 {
+				break
+			}
+			start, end := from+i, from+i+len(kw)
+			free := true
+			for k := start; k < end; k++ {
+				if marked[k] {
+					free = false
+					break
+				}
+			}
+			if free {
+				for k := start; k < end; k++ {
+					marked[k] = true
+				}
+				spans = append(spans, span{start, end})
+			}
+			from = end
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b strings.Builder
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(text[last:sp.start])
+		b.WriteString("[")
+		b.WriteString(text[sp.start:sp.end])
+		b.WriteString("]")
+		last = sp.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// matchedKeywords 返回出现在文本中的关键词（不区分大小写）。
+func matchedKeywords(keywords []string, text string) []string {
+	lower := strings.ToLower(text)
+	var out []string
+	for _, kw := range nonEmpty(keywords) {
+		if strings.Contains(lower, strings.ToLower(kw)) {
+			out = append(out, kw)
+		}
+	}
+	return out
+}
+
+func joinDocumentText(rules config.SectionRules, doc ListingDocument) string {
+	var parts []string
+	for _, sec := range rules.Sections() {
+		parts = append(parts, doc.SectionItems(sec.Name)...)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func nonEmpty(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func fallbackJoin(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, "、")
+}
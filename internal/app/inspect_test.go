@@ -0,0 +1,140 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+)
+
+func TestHighlightKeywords(t *testing.T) {
+	got := highlightKeywords("Dry Erase Pockets for dry erase boards", []string{"dry erase", "dry erase pockets", " "})
+	want := "[Dry Erase Pockets] for [dry erase] boards"
+	if got != want {
+		t.Fatalf("highlightKeywords got %q want %q", got, want)
+	}
+	if got := highlightKeywords("plain text", nil); got != "plain text" {
+		t.Fatalf("no keywords should keep text: %q", got)
+	}
+}
+
+func TestLengthStatus(t *testing.T) {
+	b := resolveCharBounds(0, 10, 5)
+	for text, mark := range map[string]string{"short": "✓", "twelve chars": "≈", "far too long text!": "✗"} {
+		if got := lengthStatus(b, text); !strings.Contains(got, mark) || !strings.Contains(got, "规则 (-inf,10]") {
+			t.Fatalf("lengthStatus(%q) = %q, want %s", text, got, mark)
+		}
+	}
+	if got := lengthStatus(charBounds{}, "abc"); got != "长度 3 字符" {
+		t.Fatalf("unexpected status without rule: %q", got)
+	}
+}
+
+func inspectFixture(t *testing.T) (ListingFileOptions, string) {
+	t.Helper()
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	if _, err := Run(Options{Inputs: []string{reqPath}, ConfigPath: cfgPath, CWD: workDir, Provider: "mock", Stdout: ioDiscard{}, Stderr: ioDiscard{}}); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	enFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md"))
+	if len(enFiles) != 1 {
+		t.Fatalf("expected one en output, got %v", enFiles)
+	}
+	return ListingFileOptions{Listing: filepath.Base(enFiles[0]), Requirement: reqPath, ConfigPath: cfgPath, Provider: "mock", CWD: workDir}, enFiles[0]
+}
+
+func TestInspectEditsRegeneratesAndSaves(t *testing.T) {
+	opts, enPath := inspectFixture(t)
+	origEN, _ := os.ReadFile(enPath)
+	var out bytes.Buffer
+	opts.Stdout = &out
+	script := strings.Join([]string{
+		"x",
+		"e",
+		"Hand Written alpha beta Title",
+		".",
+		"a",
+		"r",
+		"2",
+		"更口语化",
+		"a",
+		"s",
+	}, "\n") + "\n"
+	if err := Inspect(InspectOptions{ListingFileOptions: opts, Stdin: strings.NewReader(script)}); err != nil {
+		t.Fatalf("inspect error: %v\n%s", err, out.String())
+	}
+	for _, want := range []string{"== [1/", "无法识别的操作：x", "[alpha]", "本段命中", "✓", "正在重新生成 bullets:2", "已保存"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("inspect output missing %q:\n%s", want, out.String())
+		}
+	}
+	if bak, _ := os.ReadFile(enPath + ".bak"); !bytes.Equal(bak, origEN) {
+		t.Fatalf("expected original en backup")
+	}
+	cacheDir, _ := os.UserCacheDir()
+	rules, err := config.ReadSectionRules(filepath.Join(cacheDir, "syl-listing", "rules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := parseListingMarkdown("en", rules, string(origEN))
+	raw, _ := os.ReadFile(enPath)
+	after, err := parseListingMarkdown("en", rules, string(raw))
+	if err != nil {
+		t.Fatalf("saved listing should parse: %v", err)
+	}
+	if after.Title != "Hand Written alpha beta Title" {
+		t.Fatalf("hand edit not saved: %q", after.Title)
+	}
+	for i := range before.BulletPoints {
+		if i != 1 && after.BulletPoints[i] != before.BulletPoints[i] {
+			t.Fatalf("bullet %d should be kept", i+1)
+		}
+	}
+	if after.SearchTerms != before.SearchTerms {
+		t.Fatalf("sections after save point should be kept")
+	}
+}
+
+func TestInspectQuitWithoutWriting(t *testing.T) {
+	for _, script := range []string{"e\nChanged Title\n.\nq\n", "e\nChanged Title\n.\n"} {
+		opts, enPath := inspectFixture(t)
+		orig, _ := os.ReadFile(enPath)
+		var out bytes.Buffer
+		opts.Stdout = &out
+		if err := Inspect(InspectOptions{ListingFileOptions: opts, Stdin: strings.NewReader(script)}); err != nil {
+			t.Fatalf("inspect error: %v", err)
+		}
+		if now, _ := os.ReadFile(enPath); !bytes.Equal(now, orig) {
+			t.Fatalf("listing should not be rewritten for %q", script)
+		}
+		if _, err := os.Stat(enPath + ".bak"); !os.IsNotExist(err) {
+			t.Fatalf("no backup expected for %q", script)
+		}
+		if !strings.Contains(out.String(), "未写入文件") {
+			t.Fatalf("expected discard notice:\n%s", out.String())
+		}
+	}
+}
+
+func TestInspectRejectsInvalidEditOnEntry(t *testing.T) {
+	opts, enPath := inspectFixture(t)
+	orig, _ := os.ReadFile(enPath)
+	var out bytes.Buffer
+	opts.Stdout = &out
+	script := "a\ne\nonly one bullet\nanother bullet\n.\ns\n"
+	if err := Inspect(InspectOptions{ListingFileOptions: opts, Stdin: strings.NewReader(script)}); err != nil {
+		t.Fatalf("inspect error: %v\n%s", err, out.String())
+	}
+	for _, want := range []string{"编辑未通过校验，改动未应用：五点数量错误", "没有改动，未改写文件"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("inspect output missing %q:\n%s", want, out.String())
+		}
+	}
+	if now, _ := os.ReadFile(enPath); !bytes.Equal(now, orig) {
+		t.Fatalf("invalid edit should not be written")
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/library"
+	"syl-listing/internal/logging"
+)
+
+// openLibrary 打开已审核范例库；关闭或读取失败时返回 nil（失败只告警，不阻断生成）。
+func openLibrary(cfg *config.Config, paths *config.Paths, logger *logging.Logger) *library.Library {
+	if !cfg.Library.IsEnabled() {
+		return nil
+	}
+	lib, err := library.Open(paths.LibraryDir, library.Options{Examples: cfg.Library.Examples})
+	if err != nil {
+		logger.Emit(logging.Event{Level: "warn", Event: "library_unavailable", Error: err.Error()})
+		return nil
+	}
+	if lib.Len() > 0 {
+		logger.Emit(logging.Event{Event: "library_loaded", OutputFile: lib.Dir(), Attempt: lib.Len()})
+	}
+	return lib
+}
+
+// emitLibrarySelection 记录本候选注入提示词的范例与品牌语气。
+func emitLibrarySelection(opts sectionGenerateOptions) {
+	dir := opts.Extras
+	if dir.Voice == "" && len(dir.Examples) == 0 {
+		return
+	}
+	sources := make([]string, 0, len(dir.Examples))
+	for _, e := range dir.Examples {
+		sources = append(sources, firstNonEmpty(e.Source, e.Brand))
+	}
+	voice := "无"
+	if dir.Voice != "" {
+		voice = "有"
+	}
+	opts.Logger.Emit(logging.Event{Event: "library_selected", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Attempt: len(dir.Examples), Error: fmt.Sprintf("范例：%s；品牌语气：%s", fallbackJoin(sources), voice)})
+}
+
+// LibraryOptions 是 library add 子命令的参数。
+type LibraryOptions struct {
+	Context    context.Context
+	ConfigPath string
+	// File 为已审核的英文输出（listing_xxx_en.md），相对路径按 CWD 解析。
+	File string
+	// Brand 覆盖从文件一级标题（# <品牌> Listing）读取的品牌名。
+	Brand  string
+	CWD    string
+	Stdout io.Writer
+}
+
+// AddToLibrary 把一份已审核的英文输出按品牌与分类加入范例库；同一品牌下标题相同的范例以新加入的为准。
+func AddToLibrary(opts LibraryOptions) error {
+	if opts.Stdout == nil {
+		opts.Stdout = io.Discard
+	}
+	cfg, paths, err := config.Load(opts.ConfigPath, opts.CWD)
+	if err != nil {
+		return err
+	}
+	logger, closer, err := logging.New(opts.Stdout, "", false, false)
+	if err != nil {
+		return fmt.Errorf("初始化日志失败：%w", err)
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	rules, err := syncAndReadRules(cfg, paths, logger, nil)
+	if err != nil {
+		return err
+	}
+	path := absPath(opts.CWD, opts.File)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取 listing 文件失败：%w", err)
+	}
+	doc, err := parseListingMarkdown("en", rules, string(raw))
+	if err != nil {
+		return fmt.Errorf("解析 listing 文件失败（%s）：%w", path, err)
+	}
+	brand := firstNonEmpty(strings.TrimSpace(opts.Brand), listingBrand(string(raw)))
+	if brand == "" {
+		return fmt.Errorf("无法从 %s 读取品牌名，请用 --brand 指定", path)
+	}
+	lib, err := library.Open(paths.LibraryDir, library.Options{})
+	if err != nil {
+		return err
+	}
+	example := library.Example{Brand: brand, Category: doc.Category, Keywords: doc.Keywords, Source: filepath.Base(path)}
+	for _, sec := range rules.Sections() {
+		if items := doc.SectionItems(sec.Name); len(items) > 0 {
+			example.Sections = append(example.Sections, library.Section{Name: sec.Name, Items: append([]string{}, items...)})
+		}
+	}
+	action := "已加入"
+	if lib.Add(example) {
+		action = "已更新"
+	}
+	if err := lib.Save(opts.Context); err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Stdout, "%s范例（%s / %s）：%s，范例库共 %d 份\n", action, brand, firstNonEmpty(doc.Category, "-"), filepath.Base(path), lib.Len())
+	recordApprovedTranslation(cfg, paths, rules, logger, path, doc)
+	return nil
+}
+
+// recordApprovedTranslation 把与英文输出同名的中文输出（listing_<id>_cn.md）作为已审核译文记入翻译记忆；没有中文文件时跳过。
+func recordApprovedTranslation(cfg *config.Config, paths *config.Paths, rules config.SectionRules, logger *logging.Logger, enPath string, enDoc ListingDocument) {
+	if !strings.HasSuffix(enPath, "_en.md") {
+		return
+	}
+	raw, err := os.ReadFile(strings.TrimSuffix(enPath, "_en.md") + "_cn.md")
+	if err != nil {
+		return
+	}
+	cnDoc, err := parseListingMarkdown("cn", rules, string(raw))
+	if err != nil {
+		return
+	}
+	memory := openTranslationMemory(cfg, paths, "", logger)
+	recordTranslationMemory(memory, rules, enDoc, cnDoc)
+	saveTranslationMemory(memory, logger)
+}
+
+// listingBrand 从英文输出的一级标题 # <品牌> Listing 读取品牌名。
+func listingBrand(md string) string {
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "# "), " Listing"))
+		}
+	}
+	return ""
+}
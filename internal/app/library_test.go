@@ -0,0 +1,84 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/library"
+)
+
+func TestPromptExtrasLibraryPrompt(t *testing.T) {
+	dir := promptExtras{
+		Voice: "Warm and practical.",
+		Examples: []library.Example{
+			{Brand: "A", Sections: []library.Section{{Name: "title", Items: []string{"A Title"}}, {Name: "bullets", Items: []string{"one", "two"}}}},
+			{Brand: "B", Sections: []library.Section{{Name: "title", Items: []string{"B Title"}}}},
+		},
+	}
+	got := dir.prompt("bullets")
+	for _, want := range []string{"【品牌语气】\nWarm and practical.", "【已审核范例】", "范例1：\n- one\n- two"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "范例2") || strings.Contains(got, "A Title") {
+		t.Fatalf("only the current section of matching examples should be injected:\n%s", got)
+	}
+}
+
+func TestListingBrand(t *testing.T) {
+	if got := listingBrand("# BrandX Listing\n\n## Keywords\na"); got != "BrandX" {
+		t.Fatalf("unexpected brand: %q", got)
+	}
+}
+
+func TestAddToLibraryInjectsExamples(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	if _, err := Run(Options{Inputs: []string{reqPath}, ConfigPath: cfgPath, CWD: workDir, Provider: "mock", Stdout: ioDiscard{}, Stderr: ioDiscard{}}); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	enFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md"))
+	if len(enFiles) != 1 {
+		t.Fatalf("expected one en output, got %v", enFiles)
+	}
+	var out strings.Builder
+	for i := 0; i < 2; i++ {
+		if err := AddToLibrary(LibraryOptions{ConfigPath: cfgPath, File: filepath.Base(enFiles[0]), CWD: workDir, Stdout: &out}); err != nil {
+			t.Fatalf("add error: %v", err)
+		}
+	}
+	if !strings.Contains(out.String(), "已加入范例（BrandX / Cat）") || !strings.Contains(out.String(), "已更新范例") {
+		t.Fatalf("unexpected add output:\n%s", out.String())
+	}
+	home, _ := os.UserHomeDir()
+	if raw, err := os.ReadFile(filepath.Join(home, ".syl-listing", "tm.json")); err != nil || !strings.Contains(string(raw), `"source": "Cat"`) {
+		t.Fatalf("library add should record the approved pair in translation memory: %v\n%s", err, raw)
+	}
+	libDir := filepath.Join(home, ".syl-listing", "library")
+	if err := os.MkdirAll(filepath.Join(libDir, "voices"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(libDir, "voices", "brandx.md"), []byte("Confident, concise, teacher-friendly."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range enFiles {
+		os.Remove(f)
+	}
+
+	if _, err := Run(Options{Inputs: []string{reqPath}, ConfigPath: cfgPath, CWD: workDir, DryRun: true, Stdout: ioDiscard{}, Stderr: ioDiscard{}}); err != nil {
+		t.Fatalf("dry run error: %v", err)
+	}
+	dumps, _ := filepath.Glob(filepath.Join(workDir, "listing_*_prompts.md"))
+	if len(dumps) != 1 {
+		t.Fatalf("expected one prompt dump, got %v", dumps)
+	}
+	raw, _ := os.ReadFile(dumps[0])
+	for _, want := range []string{"【品牌语气】\nConfident, concise, teacher-friendly.", "【已审核范例】"} {
+		if !strings.Contains(string(raw), want) {
+			t.Fatalf("prompt dump missing %q", want)
+		}
+	}
+}
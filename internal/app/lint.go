@@ -0,0 +1,569 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/output"
+)
+
+// 需求文件关键词数量的建议范围，与解析时的提示一致。
+const (
+	lintMinKeywords = 15
+	lintMaxKeywords = 20
+	// lintPlaceholder 为需求模板中未填写的占位符。
+	lintPlaceholder = "[必填]"
+)
+
+const (
+	lintError   = "错误"
+	lintWarning = "警告"
+)
+
+type LintOptions struct {
+	Context    context.Context
+	Inputs     []string
+	ConfigPath string
+	// Fix 为 true 时自动修复机械性问题（首行标志、全角冒号、重复关键词）并写回文件。
+	Fix    bool
+	CWD    string
+	Stdout io.Writer
+}
+
+type LintResult struct {
+	Files    int
+	Errors   int
+	Warnings int
+	Fixed    int
+}
+
+// lintDiagnostic 是一条检查结果；Line 从 1 开始，0 表示针对整个文件。
+type lintDiagnostic struct {
+	Line     int
+	Severity string
+	Code     string
+	Message  string
+	// edit 非 nil 表示可由 --fix 自动修复。
+	edit *lintEdit
+}
+
+type lintEditOp int
+
+const (
+	lintReplace lintEditOp = iota
+	lintDelete
+	lintInsertBefore
+)
+
+// lintEdit 按原文行号描述一处修复，所有修复在一次遍历中应用，互不影响行号。
+type lintEdit struct {
+	index int
+	op    lintEditOp
+	text  string
+}
+
+// lintSettings 是检查关键词长度与生成参数所需的规则；rules 不可用时跳过这两项中依赖规则的部分。
+type lintSettings struct {
+	cfg      *config.Config
+	rules    config.SectionRules
+	hasRules bool
+}
+
+// Lint 检查需求文件，按 file:line 输出问题；有错误时返回 error，便于在付费生成前拦截。
+func Lint(opts LintOptions) (LintResult, error) {
+	cwd := strings.TrimSpace(opts.CWD)
+	if cwd == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return LintResult{}, fmt.Errorf("读取当前目录失败：%w", err)
+		}
+		cwd = wd
+	}
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	files, err := lintFiles(cwd, opts.Inputs)
+	if err != nil {
+		return LintResult{}, err
+	}
+	settings := lintSettings{}
+	cfg, paths, err := config.Load(opts.ConfigPath, cwd)
+	if err != nil {
+		return LintResult{}, err
+	}
+	settings.cfg = cfg
+	// 只读本地已同步的规则，不访问规则中心。
+	if rules, err := config.ReadSectionRules(paths.ResolvedRulesDir); err == nil {
+		settings.rules, settings.hasRules = rules, true
+	} else {
+		fmt.Fprintf(stdout, "本地规则不可用，跳过关键词长度与生成参数的规则校验：%v\n", err)
+	}
+
+	var result LintResult
+	for _, path := range files {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return result, fmt.Errorf("读取文件失败（%s）：%w", path, err)
+		}
+		result.Files++
+		diags := lintRequirement(path, string(raw), settings)
+		if opts.Fix {
+			if fixed, n := applyLintEdits(string(raw), diags); n > 0 {
+				if err := output.WriteFilesAtomic(opts.Context, output.File{Path: path, Data: []byte(fixed)}); err != nil {
+					return result, fmt.Errorf("写回修复失败（%s）：%w", path, err)
+				}
+				result.Fixed += n
+				diags = lintRequirement(path, fixed, settings)
+			}
+		}
+		name := displayPath(cwd, path)
+		for _, d := range diags {
+			switch d.Severity {
+			case lintError:
+				result.Errors++
+			default:
+				result.Warnings++
+			}
+			fmt.Fprintln(stdout, d.format(name))
+		}
+	}
+	summary := fmt.Sprintf("检查 %d 个文件：错误 %d，警告 %d", result.Files, result.Errors, result.Warnings)
+	if opts.Fix {
+		summary += fmt.Sprintf("，已修复 %d 处", result.Fixed)
+	}
+	if result.Errors > 0 {
+		return result, fmt.Errorf("%s", summary)
+	}
+	fmt.Fprintln(stdout, summary)
+	return result, nil
+}
+
+func (d lintDiagnostic) format(name string) string {
+	loc := name
+	if d.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", name, d.Line)
+	}
+	out := fmt.Sprintf("%s: %s [%s] %s", loc, d.Severity, d.Code, d.Message)
+	if d.edit != nil {
+		out += "（可 --fix 修复）"
+	}
+	return out
+}
+
+// lintFiles 收集待检查文件：显式给出的文件一律检查；目录只取首行像需求标志的 .md，以便发现写错的标志行。
+func lintFiles(cwd string, inputs []string) ([]string, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("未提供输入路径")
+	}
+	set := map[string]bool{}
+	for _, in := range inputs {
+		if strings.TrimSpace(in) == "" {
+			continue
+		}
+		path := absPath(cwd, in)
+		st, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("输入路径无效（%s）：%w", in, err)
+		}
+		if !st.IsDir() {
+			set[path] = true
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if strings.HasPrefix(d.Name(), ".") && p != path {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.EqualFold(filepath.Ext(p), ".md") {
+				return nil
+			}
+			raw, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			if _, line := firstContentLine(splitRawLines(string(raw))); looksLikeMarker(line) {
+				set[p] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("扫描目录失败（%s）：%w", in, err)
+		}
+	}
+	files := make([]string, 0, len(set))
+	for p := range set {
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("未找到任何 listing 需求文件")
+	}
+	return files, nil
+}
+
+func displayPath(cwd, path string) string {
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func splitRawLines(raw string) []string {
+	lines := strings.Split(strings.TrimPrefix(raw, "\ufeff"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	return lines
+}
+
+func firstContentLine(lines []string) (int, string) {
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return i, strings.TrimSpace(line)
+		}
+	}
+	return -1, ""
+}
+
+// looksLikeMarker 判断一行是否是写法不规范的需求标志（多余空格、等号数量或大小写不同）。
+func looksLikeMarker(line string) bool {
+	core := strings.ReplaceAll(strings.Trim(strings.TrimSpace(line), "= "), " ", "")
+	return strings.EqualFold(core, "ListingRequirements") && strings.Contains(line, "=")
+}
+
+// lintRequirement 返回需求文件的全部问题，按行号排序。
+func lintRequirement(path, raw string, settings lintSettings) []lintDiagnostic {
+	lines := splitRawLines(raw)
+	var diags []lintDiagnostic
+	add := func(line int, severity, code, msg string, edit *lintEdit) {
+		diags = append(diags, lintDiagnostic{Line: line, Severity: severity, Code: code, Message: msg, edit: edit})
+	}
+
+	markerIdx, first := firstContentLine(lines)
+	switch {
+	case first == listing.Marker:
+	case looksLikeMarker(first):
+		add(markerIdx+1, lintError, "marker", fmt.Sprintf("首行标志写法不规范：%s，应为 %s", first, listing.Marker), &lintEdit{index: markerIdx, op: lintReplace, text: listing.Marker})
+	default:
+		markerIdx = -1
+		add(1, lintError, "marker", "缺少首行标志 "+listing.Marker, &lintEdit{index: 0, op: lintInsertBefore, text: listing.Marker})
+	}
+
+	// 按修复后的标志解析，其余检查不受标志行问题影响。
+	body := strings.Join(lines[markerIdx+1:], "\n")
+	req, parseErr := listing.Parse(path, listing.Marker+"\n"+body)
+	if parseErr != nil {
+		add(lintParamsLine(lines, markerIdx), lintError, "params", parseErr.Error(), nil)
+	}
+	titleRules := settings.rules
+	tolerance := 0
+	if settings.cfg != nil {
+		tolerance = settings.cfg.CharTolerance
+	}
+	if parseErr == nil && strings.TrimSpace(req.GenerationParams) != "" {
+		o, err := config.ParseRequirementOverrides(req.GenerationParams)
+		if err == nil && settings.hasRules {
+			titleRules, _, err = settings.rules.WithOverrides(o)
+		}
+		if err != nil {
+			add(lintParamsLine(lines, markerIdx), lintError, "params", err.Error(), nil)
+			titleRules = settings.rules
+		} else if o.CharTolerance != nil {
+			tolerance = *o.CharTolerance
+		}
+	}
+
+	for i := markerIdx + 1; i < len(lines); i++ {
+		if strings.Contains(lines[i], lintPlaceholder) {
+			add(i+1, lintError, "placeholder", "模板占位符 "+lintPlaceholder+" 未填写", nil)
+		}
+	}
+	if parseErr != nil {
+		// 结构错误时解析不出需求内容，正式生成同样会失败，内容检查留到修正后再做。
+		for i := markerIdx + 1; i < len(lines); i++ {
+			if msg := unitConflict(lines[i]); msg != "" {
+				add(i+1, lintWarning, "units", msg, nil)
+			}
+		}
+		sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+		return diags
+	}
+
+	// 取值以 listing.Parse 的结果为准；原文只用于定位行号，并跳过生成参数、参考标题、现有Listing 等不属于需求正文的行。
+	skip := lintSkippedLines(lines, markerIdx)
+	brandLine, brandColon := lintFieldLine(lines, markerIdx, skip, "品牌名")
+	categoryLine, categoryColon := lintFieldLine(lines, markerIdx, skip, "分类")
+	for _, f := range []struct {
+		key       string
+		line      int
+		fullWidth bool
+	}{{"品牌名", brandLine, brandColon}, {"分类", categoryLine, categoryColon}} {
+		if f.fullWidth {
+			line := lines[f.line]
+			add(f.line+1, lintError, "colon", f.key+" 使用了全角冒号，程序无法识别", &lintEdit{index: f.line, op: lintReplace, text: strings.Replace(line, f.key+"：", f.key+":", 1)})
+		}
+	}
+	switch {
+	case brandLine < 0:
+		add(0, lintError, "brand", "品牌名缺失（缺少“品牌名: xxx”行）", nil)
+	case req.Brand == "" && !brandColon:
+		add(brandLine+1, lintError, "brand", "品牌名缺失", nil)
+	}
+	categoryHeading, _, hasCategoryBlock := listing.FindBlock(lines, "分类")
+	switch {
+	case req.Category != "" || categoryColon:
+	case categoryLine >= 0:
+		add(categoryLine+1, lintError, "category", "分类缺失", nil)
+	case hasCategoryBlock:
+		add(categoryHeading, lintError, "category", "分类缺失", nil)
+	default:
+		add(0, lintError, "category", "分类缺失（缺少“分类: xxx”行或 # 分类 块）", nil)
+	}
+
+	keywordStart, keywordEnd, hasKeywords := listing.FindBlock(lines, "关键词库")
+	var (
+		keywords     []string
+		keywordLines []int
+		firstSeen    = map[string]int{}
+		next         int
+	)
+	for i := keywordStart; hasKeywords && i < keywordEnd && next < len(req.Keywords); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		if listing.TrimListMarker(trimmed) == "" {
+			continue
+		}
+		kw := req.Keywords[next]
+		next++
+		if strings.Contains(kw, lintPlaceholder) {
+			continue
+		}
+		key := strings.ToLower(strings.Join(strings.Fields(kw), " "))
+		if prev, ok := firstSeen[key]; ok {
+			add(i+1, lintWarning, "duplicate", fmt.Sprintf("关键词重复：%s（同第 %d 行）", kw, prev+1), &lintEdit{index: i, op: lintDelete})
+			continue
+		}
+		firstSeen[key] = i
+		keywords = append(keywords, kw)
+		keywordLines = append(keywordLines, i)
+	}
+	switch {
+	case !hasKeywords:
+		add(0, lintError, "keywords", "缺少 # 关键词库", nil)
+	case len(keywords) < lintMinKeywords || len(keywords) > lintMaxKeywords:
+		add(keywordStart, lintWarning, "keywords", fmt.Sprintf("去重后关键词 %d 个，不在 %d-%d 范围", len(keywords), lintMinKeywords, lintMaxKeywords), nil)
+	}
+	if settings.hasRules {
+		title := titleRules.Title.Parsed.Constraints.MaxChars
+		if limit := title.Value; limit > 0 {
+			mode := title.CountMode()
+			if mode != config.CountWords {
+				limit += tolerance
+			}
+			for i, kw := range keywords {
+				if n := measureLength(kw, mode); n > limit {
+					add(keywordLines[i]+1, lintError, "keyword_length", fmt.Sprintf("关键词长 %d，超过标题上限 %d（规则 %d + 容差），无法放进标题", n, limit, title.Value), nil)
+				}
+			}
+		}
+	}
+
+	featureStart, featureEnd, hasFeatures := listing.FindBlock(lines, "功能卖点")
+	features := 0
+	for i := featureStart; hasFeatures && i < featureEnd; i++ {
+		if listing.TrimListMarker(lines[i]) != "" {
+			features++
+		}
+	}
+	switch {
+	case !hasFeatures:
+		add(0, lintWarning, "features", "缺少 # 功能卖点，模型只能凭关键词编写卖点", nil)
+	case features == 0:
+		add(featureStart, lintWarning, "features", "功能卖点为空，模型只能凭关键词编写卖点", nil)
+	}
+
+	for i := markerIdx + 1; i < len(lines); i++ {
+		if msg := unitConflict(lines[i]); msg != "" {
+			add(i+1, lintWarning, "units", msg, nil)
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+	return diags
+}
+
+// lintParamsLine 返回生成参数（front matter 或 # 生成参数 块）的起始行，找不到时为 0。
+func lintParamsLine(lines []string, markerIdx int) int {
+	for i := markerIdx + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if strings.TrimSpace(lines[i]) == "---" {
+			return i + 1
+		}
+		break
+	}
+	for i, line := range lines {
+		if name, ok := listing.HeadingName(line); ok && name == "生成参数" {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// lintSkippedLines 标出不属于需求正文的行：front matter、# 生成参数、# 参考标题、# 现有Listing 块（含标题行）。
+func lintSkippedLines(lines []string, markerIdx int) []bool {
+	skip := make([]bool, len(lines))
+	mark := func(from, to int) {
+		for i := from; i < to && i < len(skip); i++ {
+			skip[i] = true
+		}
+	}
+	for i := markerIdx + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if strings.TrimSpace(lines[i]) == "---" {
+			for j := i + 1; j < len(lines); j++ {
+				if t := strings.TrimSpace(lines[j]); t == "---" || t == "..." {
+					mark(i, j+1)
+					break
+				}
+			}
+		}
+		break
+	}
+	for _, name := range []string{"生成参数", "参考标题", "现有Listing"} {
+		if start, end, ok := listing.FindBlock(lines, name); ok {
+			mark(start-1, end)
+		}
+	}
+	return skip
+}
+
+// lintFieldLine 返回需求正文中首个“key: 值”行的下标，fullWidth 表示该行用了程序无法识别的全角冒号；找不到时为 -1。
+func lintFieldLine(lines []string, markerIdx int, skip []bool, key string) (int, bool) {
+	for i := markerIdx + 1; i < len(lines); i++ {
+		if skip[i] {
+			continue
+		}
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, key+":") {
+			return i, false
+		}
+		if strings.HasPrefix(trimmed, key+"：") {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// applyLintEdits 应用可自动修复的问题，保留原文的 BOM 与换行风格，返回修复后的内容与修复处数。
+func applyLintEdits(raw string, diags []lintDiagnostic) (string, int) {
+	edits := map[int][]*lintEdit{}
+	n := 0
+	for _, d := range diags {
+		if d.edit != nil {
+			edits[d.edit.index] = append(edits[d.edit.index], d.edit)
+			n++
+		}
+	}
+	if n == 0 {
+		return raw, 0
+	}
+	lines := splitRawLines(raw)
+	out := make([]string, 0, len(lines)+1)
+	for i, line := range lines {
+		keep := true
+		for _, e := range edits[i] {
+			switch e.op {
+			case lintInsertBefore:
+				out = append(out, e.text)
+			case lintReplace:
+				line = e.text
+			case lintDelete:
+				keep = false
+			}
+		}
+		if keep {
+			out = append(out, line)
+		}
+	}
+	newline := "\n"
+	if strings.Contains(raw, "\r\n") {
+		newline = "\r\n"
+	}
+	fixed := strings.Join(out, newline)
+	if strings.HasPrefix(raw, "\ufeff") {
+		fixed = "\ufeff" + fixed
+	}
+	return fixed, n
+}
+
+// 单位制：同一行同时写了公制与英制时，两者应能互相换算。
+var (
+	lintImperialUnits = map[string]bool{"in": true, "ft": true, "oz": true, "lb": true, "gal": true}
+	lintBaseUnits     = map[string]string{"length": "mm", "weight": "g", "volume": "ml"}
+)
+
+// unitConflict 检查一行中同一量纲的公制与英制数值是否对得上（允许 5% 的取整误差），
+// 如“10 x 13 inches (25 x 40 cm)”中 13 in 约 33 cm，与 40 cm 冲突。
+func unitConflict(line string) string {
+	byKind := map[string][2][]numericFact{}
+	for _, f := range extractNumericFacts(line) {
+		if f.Unit == "" || f.Kind == "count" || f.Kind == "age" {
+			continue
+		}
+		groups := byKind[f.Kind]
+		if lintImperialUnits[f.Unit] {
+			groups[1] = append(groups[1], f)
+		} else {
+			groups[0] = append(groups[0], f)
+		}
+		byKind[f.Kind] = groups
+	}
+	kinds := make([]string, 0, len(byKind))
+	for k := range byKind {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		metric, imperial := byKind[kind][0], byKind[kind][1]
+		if len(metric) == 0 || len(imperial) == 0 {
+			continue
+		}
+		for _, im := range imperial {
+			matched := false
+			for _, m := range metric {
+				if math.Abs(im.Base-m.Base) <= 0.05*math.Max(im.Base, m.Base) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				labels := make([]string, len(metric))
+				for i, m := range metric {
+					labels[i] = m.label()
+				}
+				return fmt.Sprintf("单位换算对不上：%s（约 %s %s）与同一行的 %s 不一致", im.label(), formatFactNumber(math.Round(im.Base*10)/10), lintBaseUnits[kind], strings.Join(labels, "、"))
+			}
+		}
+	}
+	return ""
+}
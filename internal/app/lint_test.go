@@ -0,0 +1,141 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+)
+
+func lintCodes(diags []lintDiagnostic) string {
+	parts := make([]string, 0, len(diags))
+	for _, d := range diags {
+		parts = append(parts, d.format("a.md"))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func TestLintRequirementDiagnostics(t *testing.T) {
+	var kw []string
+	for i := 1; i <= 15; i++ {
+		kw = append(kw, "- keyword "+string(rune('a'+i)))
+	}
+	raw := strings.Join(append([]string{
+		"===Listing Requirements===",
+		"# 生成参数",
+		"char_tolerance: 5",
+		"# 基础信息",
+		"品牌名：BrandX",
+		"尺寸: 10 x 13 inches (25 x 33 cm)",
+		"重量: 2 lb (1.5 kg)",
+		"# 功能卖点",
+		"1. ",
+		"# 分类",
+		"[必填]",
+		"# 关键词库",
+		"- extra long dry erase pocket sleeves for classroom",
+		"- Keyword  B",
+	}, kw...), "\n")
+	settings := lintSettings{cfg: &config.Config{CharTolerance: 20}, hasRules: true}
+	settings.rules.Title.Parsed.Constraints.MaxChars.Value = 40
+	got := lintCodes(lintRequirement("a.md", raw, settings))
+	for _, want := range []string{
+		"a.md:5: 错误 [colon] 品牌名 使用了全角冒号，程序无法识别（可 --fix 修复）",
+		"a.md:7: 警告 [units] 单位换算对不上：2 lb（约 907.2 g）与同一行的 1.5 kg 不一致",
+		"a.md:8: 警告 [features] 功能卖点为空",
+		"a.md:11: 错误 [placeholder] 模板占位符 [必填] 未填写",
+		"a.md:13: 错误 [keyword_length] 关键词长 49，超过标题上限 45（规则 40 + 容差）",
+		"a.md:15: 警告 [duplicate] 关键词重复：keyword b（同第 14 行）（可 --fix 修复）",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"[marker]", "[brand]", "[category]", "[keywords]", "a.md:6:"} {
+		if strings.Contains(got, unwanted) {
+			t.Fatalf("unexpected %q in:\n%s", unwanted, got)
+		}
+	}
+
+	got = lintCodes(lintRequirement("a.md", "\n---\nbulets: 3\n---\n# 关键词库\n- a\n", lintSettings{}))
+	for _, want := range []string{
+		"a.md:1: 错误 [marker] 缺少首行标志",
+		"a.md:2: 错误 [params] 生成参数格式错误",
+		"a.md: 错误 [brand] 品牌名缺失",
+		"a.md: 错误 [category] 分类缺失",
+		"a.md:5: 警告 [keywords] 去重后关键词 1 个",
+		"a.md: 警告 [features] 缺少 # 功能卖点",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestLintRequirementIgnoresNonRequirementBlocks(t *testing.T) {
+	raw := strings.Join([]string{
+		"===Listing Requirements===",
+		"# 现有Listing",
+		"品牌名：OldBrand",
+		"## 标题",
+		"Old title",
+		"# 生成参数",
+		"char_tolerance: 5",
+		"# 基础信息",
+		"品牌名:",
+		"分类: Cat",
+		"# 功能卖点",
+		"1. Reusable",
+		"# 关键词库",
+		"- alpha",
+		"- alpha",
+	}, "\n")
+	got := lintCodes(lintRequirement("a.md", raw, lintSettings{}))
+	for _, want := range []string{
+		"a.md:9: 错误 [brand] 品牌名缺失",
+		"a.md:15: 警告 [duplicate] 关键词重复：alpha（同第 14 行）",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "[colon]") || strings.Contains(got, "[category]") {
+		t.Fatalf("existing listing block should not be linted as requirement:\n%s", got)
+	}
+}
+
+func TestLintFixWritesMechanicalFixes(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	dir := filepath.Join(workDir, "reqs")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "a.md")
+	raw := "\ufeff=== Listing  Requirements ===\r\n品牌名：BrandX\r\n分类: Cat\r\n# 功能卖点\r\n1. Reusable\r\n# 关键词库\r\n- alpha\r\n- Alpha\r\n"
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# 随手记\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := Lint(LintOptions{Inputs: []string{"reqs"}, ConfigPath: cfgPath, CWD: workDir, Stdout: &out}); err == nil || !strings.Contains(err.Error(), "错误 2") {
+		t.Fatalf("expected lint errors, got %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), filepath.Join("reqs", "a.md")+":1: 错误 [marker]") || strings.Contains(out.String(), "notes.md") {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+	out.Reset()
+	res, err := Lint(LintOptions{Inputs: []string{path}, ConfigPath: cfgPath, CWD: workDir, Fix: true, Stdout: &out})
+	if err != nil || res.Fixed != 3 || res.Errors != 0 || res.Warnings != 1 {
+		t.Fatalf("fix result %+v err=%v\n%s", res, err, out.String())
+	}
+	fixed, _ := os.ReadFile(path)
+	want := "\ufeff===Listing Requirements===\r\n品牌名:BrandX\r\n分类: Cat\r\n# 功能卖点\r\n1. Reusable\r\n# 关键词库\r\n- alpha\r\n"
+	if string(fixed) != want {
+		t.Fatalf("fixed content %q, want %q", fixed, want)
+	}
+}
@@ -64,7 +64,7 @@ func TestProcessCandidateSuccess(t *testing.T) {
 		t.Fatal(err)
 	}
 	work := t.TempDir()
-	ok := processCandidate(processCandidateOptions{
+	outcome := processCandidate(processCandidateOptions{
 		Job: candidateJob{
 			Req: listing.Requirement{
 				SourcePath:      "/tmp/a.md",
@@ -87,7 +87,7 @@ func TestProcessCandidateSuccess(t *testing.T) {
 		TranslateClient:      translator.NewClient(10 * time.Second),
 		Logger:               logger,
 	})
-	if !ok {
+	if outcome != candidateOK {
 		t.Fatalf("expected processCandidate success")
 	}
 	enFiles, _ := filepath.Glob(filepath.Join(work, "listing_*_en.md"))
@@ -102,7 +102,7 @@ func TestProcessCandidateGenerateFailed(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	ok := processCandidate(processCandidateOptions{
+	outcome := processCandidate(processCandidateOptions{
 		Job: candidateJob{
 			Req: listing.Requirement{
 				SourcePath:      "/tmp/a.md",
@@ -125,7 +125,7 @@ func TestProcessCandidateGenerateFailed(t *testing.T) {
 		TranslateClient:      translator.NewClient(100 * time.Millisecond),
 		Logger:               logger,
 	})
-	if ok {
+	if outcome != candidateFailed {
 		t.Fatalf("expected processCandidate generate failure")
 	}
 }
@@ -31,7 +31,7 @@ func TestProcessCandidateWriteFailed(t *testing.T) {
 		Keywords:        []string{"alpha", "beta", "gamma"},
 	}
 
-	ok := processCandidate(processCandidateOptions{
+	outcome := processCandidate(processCandidateOptions{
 		Job:           candidateJob{Req: req, Candidate: 1},
 		OutDir:        filepath.Join(t.TempDir(), "not-exist", "dir"),
 		CharTolerance: 20,
@@ -49,7 +49,7 @@ func TestProcessCandidateWriteFailed(t *testing.T) {
 		TranslateClient:      trClient,
 		Logger:               logger,
 	})
-	if ok {
+	if outcome != candidateFailed {
 		t.Fatalf("expected processCandidate failure when output dir missing")
 	}
 }
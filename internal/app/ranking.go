@@ -0,0 +1,255 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/output"
+)
+
+// 最佳候选的输出方式。
+const (
+	bestModeOff     = "off"
+	bestModeCopy    = "copy"
+	bestModeSymlink = "symlink"
+)
+
+func parseBestMode(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", bestModeOff, "none":
+		return bestModeOff, nil
+	case bestModeCopy:
+		return bestModeCopy, nil
+	case bestModeSymlink, "link":
+		return bestModeSymlink, nil
+	default:
+		return "", fmt.Errorf("best 取值无效：%s（可选 copy|symlink|off）", s)
+	}
+}
+
+// candidateOutput 是一次成功生成并写盘的候选。
+type candidateOutput struct {
+	Req       listing.Requirement
+	Candidate int
+	ENPath    string
+	CNPath    string
+	EN        ListingDocument
+	CN        ListingDocument
+	// Review 为模型评审结果；未开启评审时为 nil。
+	Review *listingReview
+}
+
+// rankingCollector 按需求文件收集成功的候选，全部完成后统一评分；nil 表示不收集。
+type rankingCollector struct {
+	mu      sync.Mutex
+	byInput map[string][]candidateOutput
+	order   []string
+}
+
+func newRankingCollector() *rankingCollector {
+	return &rankingCollector{byInput: map[string][]candidateOutput{}}
+}
+
+func (c *rankingCollector) record(out candidateOutput) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := out.Req.SourcePath
+	if _, ok := c.byInput[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.byInput[key] = append(c.byInput[key], out)
+}
+
+// groups 按需求出现顺序返回候选分组，组内按候选编号排序。
+func (c *rankingCollector) groups() [][]candidateOutput {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([][]candidateOutput, 0, len(c.order))
+	for _, key := range c.order {
+		group := append([]candidateOutput{}, c.byInput[key]...)
+		sort.Slice(group, func(i, j int) bool { return group[i].Candidate < group[j].Candidate })
+		out = append(out, group)
+	}
+	return out
+}
+
+type rankingEntry struct {
+	Rank      int    `json:"rank"`
+	Candidate int    `json:"candidate"`
+	ENFile    string `json:"en_file"`
+	CNFile    string `json:"cn_file"`
+	documentScore
+	// Review 为模型评审结果，仅供参考，不计入总分。
+	Review *listingReview `json:"review,omitempty"`
+}
+
+type rankingReport struct {
+	Input      string         `json:"input"`
+	Best       int            `json:"best_candidate"`
+	Candidates []rankingEntry `json:"candidates"`
+}
+
+// buildRankingReport 为同一需求的候选评分并排序；同分时候选编号小的在前。
+func buildRankingReport(outs []candidateOutput, rules config.SectionRules, style *stylePolicy, tolerance int) rankingReport {
+	report := rankingReport{Candidates: make([]rankingEntry, 0, len(outs))}
+	for _, o := range outs {
+		report.Input = o.Req.SourcePath
+		report.Candidates = append(report.Candidates, rankingEntry{
+			Candidate:     o.Candidate,
+			ENFile:        filepath.Base(o.ENPath),
+			CNFile:        filepath.Base(o.CNPath),
+			documentScore: scoreDocument(o.Req, rules, o.EN, style, tolerance),
+			Review:        o.Review,
+		})
+	}
+	sort.SliceStable(report.Candidates, func(i, j int) bool {
+		a, b := report.Candidates[i], report.Candidates[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Candidate < b.Candidate
+	})
+	for i := range report.Candidates {
+		report.Candidates[i].Rank = i + 1
+	}
+	if len(report.Candidates) > 0 {
+		report.Best = report.Candidates[0].Candidate
+	}
+	return report
+}
+
+func (r rankingReport) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 候选评分：%s\n\n", filepath.Base(r.Input))
+	fmt.Fprintf(&b, "需求文件：`%s`\n\n", r.Input)
+	fmt.Fprintf(&b, "总分 = 关键词覆盖 %.0f%% + 长度贴合 %.0f%% + 校验提示 %.0f%% + 禁用词 %.0f%% + 可读性 %.0f%%（满分 100）。\n\n",
+		scoreWeightKeywords*100, scoreWeightLength*100, scoreWeightWarnings*100, scoreWeightForbidden*100, scoreWeightReadability*100)
+	b.WriteString("| 排名 | 候选 | 总分 | 关键词覆盖 | 长度贴合 | 校验提示 | 禁用词 | 可读性 | EN 文件 |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	for _, c := range r.Candidates {
+		fmt.Fprintf(&b, "| %d | %d | %.1f | %.0f%% | %.2f | %d | %d | %.2f | %s |\n",
+			c.Rank, c.Candidate, c.Total, c.KeywordCoverage*100, c.LengthFit, c.Warnings, len(c.ForbiddenHits), c.Readability, c.ENFile)
+	}
+	for _, c := range r.Candidates {
+		fmt.Fprintf(&b, "\n## 候选 %d（%.1f 分）\n\n", c.Candidate, c.Total)
+		b.WriteString("| 分段 | 得分 | 关键词覆盖 | 长度贴合 | 校验提示 | 禁用词 |\n|---|---|---|---|---|---|\n")
+		for _, s := range c.Sections {
+			fmt.Fprintf(&b, "| %s | %.1f | %.0f%% | %.2f | %d | %s |\n", s.Name, s.Score, s.KeywordCoverage*100, s.LengthFit, s.Warnings, joinOrDash(s.ForbiddenHits))
+		}
+		fmt.Fprintf(&b, "\n- 未覆盖关键词：%s\n", joinOrDash(c.MissingKeywords))
+	}
+	return b.String()
+}
+
+func joinOrDash(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, "、")
+}
+
+// reportStem 返回需求对应报告文件的前缀：需求文件名加绝对路径的短哈希，
+// 不同目录下的同名需求（a/product.md 与 b/product.md）写到同一输出目录时不会互相覆盖。
+func reportStem(input string) string {
+	stem := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	if abs, err := filepath.Abs(input); err == nil {
+		input = abs
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(input)))
+	return stem + "_" + hex.EncodeToString(sum[:])[:6]
+}
+
+// rankingPaths 返回需求对应的评分报告与最佳候选文件路径，按 reportStem 区分。
+func rankingPaths(outDir, input string) (md, js, bestEN, bestCN string) {
+	stem := reportStem(input)
+	md = filepath.Join(outDir, stem+"_ranking.md")
+	js = filepath.Join(outDir, stem+"_ranking.json")
+	bestEN = filepath.Join(outDir, stem+"_best_en.md")
+	bestCN = filepath.Join(outDir, stem+"_best_cn.md")
+	return md, js, bestEN, bestCN
+}
+
+type rankingWriteOptions struct {
+	Context   context.Context
+	OutDir    string
+	Rules     config.SectionRules
+	Style     *stylePolicy
+	Tolerance int
+	BestMode  string
+	Logger    *logging.Logger
+}
+
+// writeRankings 为每个需求写入评分报告（Markdown 与 JSON），并按 BestMode 输出最佳候选。
+func writeRankings(opts rankingWriteOptions, groups [][]candidateOutput) []rankingReport {
+	reports := make([]rankingReport, 0, len(groups))
+	for _, outs := range groups {
+		if len(outs) == 0 {
+			continue
+		}
+		report := buildRankingReport(outs, opts.Rules, opts.Style, opts.Tolerance)
+		reports = append(reports, report)
+		mdPath, jsPath, bestEN, bestCN := rankingPaths(opts.OutDir, report.Input)
+		raw, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = output.WriteFilesAtomic(contextOrBackground(opts.Context),
+				output.File{Path: mdPath, Data: []byte(report.markdown())},
+				output.File{Path: jsPath, Data: append(raw, '\n')},
+			)
+		}
+		if err != nil {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "ranking_failed", Input: report.Input, OutputFile: mdPath, Error: err.Error()})
+			continue
+		}
+		opts.Logger.Emit(logging.Event{Event: "ranking_written", Input: report.Input, OutputFile: mdPath, Attempt: report.Best})
+		if opts.BestMode == bestModeOff {
+			continue
+		}
+		var best candidateOutput
+		for _, o := range outs {
+			if o.Candidate == report.Best {
+				best = o
+			}
+		}
+		for _, pair := range [][2]string{{best.ENPath, bestEN}, {best.CNPath, bestCN}} {
+			if err := linkBestFile(opts.Context, opts.BestMode, pair[0], pair[1]); err != nil {
+				opts.Logger.Emit(logging.Event{Level: "warn", Event: "ranking_failed", Input: report.Input, OutputFile: pair[1], Error: err.Error()})
+				continue
+			}
+			opts.Logger.Emit(logging.Event{Event: "best_written", Input: report.Input, OutputFile: pair[1], Attempt: report.Best})
+		}
+	}
+	return reports
+}
+
+// linkBestFile 把最佳候选复制或软链接到固定文件名；软链接失败（如 Windows 无权限）时退回复制。
+func linkBestFile(ctx context.Context, mode, src, dst string) error {
+	if mode == bestModeSymlink {
+		_ = os.Remove(dst)
+		if err := os.Symlink(filepath.Base(src), dst); err == nil {
+			return nil
+		}
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("读取最佳候选失败：%w", err)
+	}
+	_ = os.Remove(dst)
+	return output.WriteFilesAtomic(contextOrBackground(ctx), output.File{Path: dst, Data: data})
+}
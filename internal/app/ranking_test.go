@@ -0,0 +1,98 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/listing"
+)
+
+func TestParseBestMode(t *testing.T) {
+	for in, want := range map[string]string{"": bestModeOff, "off": bestModeOff, "Copy": bestModeCopy, "symlink": bestModeSymlink} {
+		if got, err := parseBestMode(in); err != nil || got != want {
+			t.Fatalf("parseBestMode(%q)=%q,%v", in, got, err)
+		}
+	}
+	if _, err := parseBestMode("move"); err == nil {
+		t.Fatalf("expected invalid mode error")
+	}
+}
+
+func TestWriteRankingsReportsAndBestCopy(t *testing.T) {
+	outDir := t.TempDir()
+	req := listing.Requirement{SourcePath: "/in/demo.md", Keywords: []string{"alpha", "beta"}}
+	writeCandidate := func(n int, doc ListingDocument) candidateOutput {
+		en := filepath.Join(outDir, "listing_"+string(rune('a'+n))+"_en.md")
+		cn := filepath.Join(outDir, "listing_"+string(rune('a'+n))+"_cn.md")
+		_ = os.WriteFile(en, []byte(RenderMarkdown("en", req, doc)), 0o644)
+		_ = os.WriteFile(cn, []byte("cn"), 0o644)
+		return candidateOutput{Req: req, Candidate: n, ENPath: en, CNPath: cn, EN: doc}
+	}
+	weak := ListingDocument{Title: "Organizer", BulletPoints: []string{"a", "b", "c", "d", "e"}, DescriptionParagraphs: []string{"p1", "p2"}, SearchTerms: "s"}
+	strong := ListingDocument{Title: "Alpha Beta Organizer", BulletPoints: []string{"alpha box holds items", "beta lid closes well", "clear view for all", "easy to clean daily", "fits shelves nicely"}, DescriptionParagraphs: []string{"p1", "p2"}, SearchTerms: "alpha beta"}
+
+	collector := newRankingCollector()
+	collector.record(writeCandidate(1, weak))
+	collector.record(writeCandidate(2, strong))
+	reports := writeRankings(rankingWriteOptions{OutDir: outDir, Rules: testRules(), BestMode: bestModeCopy}, collector.groups())
+	if len(reports) != 1 || reports[0].Best != 2 || reports[0].Candidates[0].Rank != 1 {
+		t.Fatalf("unexpected ranking: %+v", reports)
+	}
+
+	mdPath, jsPath, bestEN, bestCN := rankingPaths(outDir, req.SourcePath)
+	md, err := os.ReadFile(mdPath)
+	if err != nil || !strings.Contains(string(md), "| 1 | 2 |") || !strings.Contains(string(md), "未覆盖关键词：alpha、beta") {
+		t.Fatalf("unexpected markdown report (%v):\n%s", err, md)
+	}
+	var parsed rankingReport
+	raw, _ := os.ReadFile(jsPath)
+	if err := json.Unmarshal(raw, &parsed); err != nil || parsed.Best != 2 || len(parsed.Candidates) != 2 || parsed.Candidates[0].Total < parsed.Candidates[1].Total {
+		t.Fatalf("unexpected json report (%v): %s", err, raw)
+	}
+	if filepath.Base(bestEN) != reportStem(req.SourcePath)+"_best_en.md" || !strings.HasPrefix(filepath.Base(bestEN), "demo_") {
+		t.Fatalf("unexpected best path: %s", bestEN)
+	}
+	gotEN, _ := os.ReadFile(bestEN)
+	wantEN, _ := os.ReadFile(filepath.Join(outDir, "listing_c_en.md"))
+	gotCN, _ := os.ReadFile(bestCN)
+	if string(gotEN) != string(wantEN) || string(gotCN) != "cn" {
+		t.Fatalf("best pair should copy candidate 2")
+	}
+}
+
+func TestRunMockRankingWithSymlink(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	res, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Provider:   "mock",
+		Num:        2,
+		Best:       "symlink",
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err != nil || res.Succeeded != 2 {
+		t.Fatalf("mock run failed: res=%+v err=%v", res, err)
+	}
+	stem := reportStem(reqPath)
+	for _, name := range []string{stem + "_ranking.md", stem + "_ranking.json", stem + "_best_en.md", stem + "_best_cn.md"} {
+		if _, err := os.Stat(filepath.Join(workDir, name)); err != nil {
+			t.Fatalf("missing %s: %v", name, err)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(workDir, stem+"_best_en.md")); err == nil && !strings.HasPrefix(target, "listing_") {
+		t.Fatalf("symlink should point at a candidate file: %s", target)
+	}
+}
+
+func TestReportStemSeparatesSameNamedInputs(t *testing.T) {
+	a, b := reportStem("/reqs/a/product.md"), reportStem("/reqs/b/product.md")
+	if a == b || !strings.HasPrefix(a, "product_") || reportStem("/reqs/a/../a/product.md") != a {
+		t.Fatalf("unexpected stems: %s %s", a, b)
+	}
+}
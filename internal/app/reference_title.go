@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+)
+
+// titleCompareTopN 为对比中逐个核对的关键词库前 N 个关键词。
+const titleCompareTopN = 3
+
+// renderTitleComparison 渲染 listing_<id>_title_ab.md：需求中的参考标题（版本A）与生成标题（版本B）
+// 的长度、关键词 #1–#3 覆盖与禁用词对比。
+func renderTitleComparison(req listing.Requirement, rules config.SectionRules, style *stylePolicy, tolerance int, generated string) string {
+	rule, _ := rules.Get("title")
+	bounds := resolveRuleBounds(rule.Parsed, config.RuleIntConstraint{}, rule.Parsed.Constraints.MaxChars, tolerance)
+	mode := rule.Parsed.Constraints.MustContainTopNKeywords.MatchMode()
+	titles := [2]string{strings.TrimSpace(req.ReferenceTitle), strings.TrimSpace(generated)}
+	top := nonEmpty(req.Keywords)
+	if len(top) > titleCompareTopN {
+		top = top[:titleCompareTopN]
+	}
+
+	var b strings.Builder
+	b.WriteString("# 标题 A/B 对比\n\n")
+	b.WriteString("需求：")
+	b.WriteString(req.SourcePath)
+	b.WriteString("\n\n| 项目 | 版本A（参考标题） | 版本B（生成标题） |\n| --- | --- | --- |\n")
+	row := func(label string, cell func(title string) string) {
+		b.WriteString(fmt.Sprintf("| %s | %s | %s |\n", label, cell(titles[0]), cell(titles[1])))
+	}
+	row("标题", markdownCell)
+	lengthLabel := "长度"
+	if bounds.hasRule() {
+		lengthLabel = fmt.Sprintf("长度（规则 %s，容差 %s）", bounds.ruleText(), bounds.toleranceText())
+	}
+	row(lengthLabel, func(title string) string {
+		n := bounds.measure(title)
+		if !bounds.hasRule() {
+			return fmt.Sprintf("%d %s", n, bounds.unit())
+		}
+		return fmt.Sprintf("%d %s %s", n, bounds.unit(), lengthMark(bounds, n))
+	})
+	row(fmt.Sprintf("关键词 #1–#%d 覆盖", len(top)), func(title string) string {
+		hit := 0
+		for _, kw := range top {
+			if keywordMatches(title, kw, mode) {
+				hit++
+			}
+		}
+		return fmt.Sprintf("%d/%d", hit, len(top))
+	})
+	for i, kw := range top {
+		row(fmt.Sprintf("#%d %s", i+1, markdownCell(kw)), func(title string) string {
+			if keywordMatches(title, kw, mode) {
+				return "✓"
+			}
+			return "✗"
+		})
+	}
+	row("禁用词", func(title string) string {
+		if hits := forbiddenHits(rule, style, title); len(hits) > 0 {
+			return markdownCell(strings.Join(hits, "、"))
+		}
+		return "无"
+	})
+	return b.String()
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// titleCompareSidecarPath 返回标题对比文件路径：listing_<id>_title_ab.md。
+func titleCompareSidecarPath(enPath string) string {
+	return strings.TrimSuffix(enPath, "_en.md") + "_title_ab.md"
+}
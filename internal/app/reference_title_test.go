@@ -0,0 +1,61 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+)
+
+func TestRenderTitleComparison(t *testing.T) {
+	req := listing.Requirement{
+		SourcePath:     "req.md",
+		Keywords:       []string{"dry erase pockets", "sleeves", "classroom", "teacher"},
+		ReferenceTitle: "Dry Erase Pockets | Best Seller for Classroom",
+	}
+	md := renderTitleComparison(req, testRules(), newStylePolicy(config.DefaultStyleConfig()), 20, "BrandX Dry Erase Pockets 10 Pack, Reusable Pocket Sleeves for Classroom")
+	for _, want := range []string{
+		"| 标题 | Dry Erase Pockets \\| Best Seller for Classroom | BrandX Dry Erase Pockets",
+		"| 关键词 #1–#3 覆盖 | 2/3 | 3/3 |",
+		"| #2 sleeves | ✗ | ✓ |",
+		"| 禁用词 | best seller | 无 |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("comparison missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestPromptExtrasReferenceTitlePrompt(t *testing.T) {
+	dir := promptExtras{ReferenceTitle: "Ref Title"}
+	if got := dir.prompt("title"); !strings.Contains(got, "【参考标题】") || !strings.Contains(got, "Ref Title") {
+		t.Fatalf("title prompt should include reference: %q", got)
+	}
+	if got := dir.prompt("bullets"); got != "" {
+		t.Fatalf("reference title only applies to title: %q", got)
+	}
+}
+
+func TestRunWritesTitleComparison(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	raw, _ := os.ReadFile(reqPath)
+	if err := os.WriteFile(reqPath, append(raw, []byte("\n# 参考标题\nRef Alpha Title\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(Options{Inputs: []string{reqPath}, ConfigPath: cfgPath, CWD: workDir, Provider: "mock", Stdout: ioDiscard{}, Stderr: ioDiscard{}})
+	if err != nil || res.Succeeded != 1 {
+		t.Fatalf("run: %+v %v", res, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(workDir, "listing_*_title_ab.md"))
+	if len(files) != 1 {
+		t.Fatalf("expected one title comparison, got %v", files)
+	}
+	md, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(md), "| 标题 | Ref Alpha Title |") || !strings.Contains(string(md), "| 关键词 #1–#2 覆盖 | 1/2 | 2/2 |") {
+		t.Fatalf("unexpected comparison:\n%s", md)
+	}
+}
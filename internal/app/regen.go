@@ -0,0 +1,322 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/output"
+	"syl-listing/internal/tm"
+)
+
+// ListingFileOptions 是对已有输出做二次处理（regen、inspect）的公共参数。
+type ListingFileOptions struct {
+	Context context.Context
+	// Listing 为已有英文输出（listing_xxx_en.md），中文按同名 _cn.md 读取。
+	Listing     string
+	Requirement string
+	ConfigPath  string
+	Provider    string
+	MaxRetries  int
+	Cache       string
+	LogFile     string
+	Verbose     bool
+	CWD         string
+	Stdout      io.Writer
+}
+
+// RegenOptions 是 regen 子命令的参数：对已有输出只重新生成一个分段或分段中的一条。
+type RegenOptions struct {
+	ListingFileOptions
+	// Section 为分段名，可用 name:N 或 name[N] 指定第 N 条（从 1 开始），如 bullets:3。
+	Section string
+	// Note 为附加要求，注入重新生成的提示词。
+	Note string
+}
+
+// sectionTarget 是要重新生成的分段；Item 为 0 表示整段。
+type sectionTarget struct {
+	Name string
+	Item int
+}
+
+func (t sectionTarget) String() string {
+	if t.Item == 0 {
+		return t.Name
+	}
+	return fmt.Sprintf("%s:%d", t.Name, t.Item)
+}
+
+var sectionTargetRe = regexp.MustCompile(`^([A-Za-z0-9_-]+)(?::(\d+)|\[:?(\d+)\])?$`)
+
+// parseSectionTarget 解析 --section：bullets、bullets:3、bullets[3]。
+func parseSectionTarget(spec string) (sectionTarget, error) {
+	m := sectionTargetRe.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return sectionTarget{}, fmt.Errorf("--section 格式错误：%q（示例：description、bullets:3）", spec)
+	}
+	t := sectionTarget{Name: strings.ToLower(m[1])}
+	if n := m[2] + m[3]; n != "" {
+		item, _ := strconv.Atoi(n)
+		if item <= 0 {
+			return sectionTarget{}, fmt.Errorf("--section 条目序号从 1 开始：%q", spec)
+		}
+		t.Item = item
+	}
+	return t, nil
+}
+
+// resolveSectionTarget 按规则清单与现有文档检查目标：条目级只支持多行分段，序号不得超出现有条数。
+func resolveSectionTarget(rules config.SectionRules, t sectionTarget, doc ListingDocument) (config.ManifestSection, error) {
+	sec, ok := rules.Section(t.Name)
+	if !ok {
+		names := make([]string, 0, len(rules.Sections()))
+		for _, s := range rules.Sections() {
+			names = append(names, s.Name)
+		}
+		return config.ManifestSection{}, fmt.Errorf("规则清单中没有分段 %s（可选：%s）", t.Name, strings.Join(names, "、"))
+	}
+	if t.Item == 0 {
+		return sec, nil
+	}
+	if sec.Output != config.OutputLines {
+		return config.ManifestSection{}, fmt.Errorf("%s 不是多行分段，只能整段重新生成", t.Name)
+	}
+	if n := len(doc.SectionItems(t.Name)); t.Item > n {
+		return config.ManifestSection{}, fmt.Errorf("%s 只有 %d 条，无法重新生成第 %d 条", t.Name, n, t.Item)
+	}
+	return sec, nil
+}
+
+// regenerateSection 重新生成目标分段（或其中一条）并只重译受影响的中文，其余分段保持不变。
+// note 非空时作为附加要求一并注入。
+func regenerateSection(opts bilingualGenerateOptions, sec config.ManifestSection, t sectionTarget, note string, enDoc, cnDoc ListingDocument) (ListingDocument, ListingDocument, error) {
+	rule, err := opts.Rules.Get(sec.Name)
+	if err != nil {
+		return ListingDocument{}, ListingDocument{}, err
+	}
+	genOpts := opts.enSectionOptions()
+	current := append([]string{}, enDoc.SectionItems(sec.Name)...)
+	var items, cn []string
+	if t.Item == 0 {
+		genOpts.Extras.ReviewComments = []string{"审核人不满意现有版本，请换一种写法重写本段，不要沿用原句：" + strings.Join(current, " / ")}
+		if note = strings.TrimSpace(note); note != "" {
+			genOpts.Extras.ReviewComments = append(genOpts.Extras.ReviewComments, note)
+		}
+		if items, _, err = generateManifestSection(genOpts, sec, enDoc); err != nil {
+			return ListingDocument{}, ListingDocument{}, fmt.Errorf("重新生成 %s 失败：%w", sec.Name, err)
+		}
+		if cn, err = opts.translateCNSection(sec, items, cnDoc); err != nil {
+			return ListingDocument{}, ListingDocument{}, err
+		}
+	} else {
+		if !providerSupportsJSONMode(opts.Provider) {
+			return ListingDocument{}, ListingDocument{}, fmt.Errorf("provider %s 不支持按条重新生成（需要 json 模式）", opts.Provider)
+		}
+		bounds := resolveRuleBounds(rule.Parsed, rule.Parsed.Constraints.MinCharsPerLine, rule.Parsed.Constraints.MaxCharsPerLine, opts.CharTolerance)
+		genOpts.Extras.ReviewComments = []string{fmt.Sprintf("审核人不满意第%d条，请换一种写法重写该条，不要沿用原句", t.Item)}
+		if note = strings.TrimSpace(note); note != "" {
+			genOpts.Extras.ReviewComments = append(genOpts.Extras.ReviewComments, note)
+		}
+		line, _, err := regenerateJSONLineItemWithRetry(genOpts, sec.Name, enDoc.contextFor(sec.DependsOn), rule, t.Item, current, bounds, resolveSectionExecutionPolicy(rule).ItemJSONField)
+		if err != nil {
+			return ListingDocument{}, ListingDocument{}, err
+		}
+		items = current
+		items[t.Item-1] = line
+		if cn, err = retranslateItem(opts, sec, items, t.Item-1, cnDoc); err != nil {
+			return ListingDocument{}, ListingDocument{}, err
+		}
+	}
+	enDoc.setSection(sec, "en", items)
+	cnDoc.setSection(sec, "cn", cn)
+	return enDoc, cnDoc, nil
+}
+
+// retranslateItem 只重译第 idx 条并按中文规则校验整段；中文为 native 或条数与英文不一致时整段重新产出中文。
+func retranslateItem(opts bilingualGenerateOptions, sec config.ManifestSection, enItems []string, idx int, cnDoc ListingDocument) ([]string, error) {
+	cn := append([]string{}, cnDoc.SectionItems(sec.Name)...)
+	if _, native := opts.cnNativeRule(sec.Name); native || len(cn) != len(enItems) {
+		return opts.translateCNSection(sec, enItems, cnDoc)
+	}
+	topts := opts.translateSectionOptions()
+	topts.Section, topts.SourceText = translateSectionName(sec, idx, len(enItems)), enItems[idx]
+	translated, _, err := translateSectionWithRetry(topts)
+	if err != nil {
+		return nil, err
+	}
+	cn[idx] = translatedCleaner(sec.Name)(strings.TrimSpace(translated))
+	if err := validateTranslatedItems(sec, cn); err != nil {
+		return nil, err
+	}
+	return fitCNSection(opts, sec, enItems, cn)
+}
+
+// listingSession 是对一份已有输出（中英文件）做二次处理的上下文：配置、规则、需求、生成参数与读入的文档。
+type listingSession struct {
+	Gen    bilingualGenerateOptions
+	Req    listing.Requirement
+	Rules  config.SectionRules
+	ENPath string
+	CNPath string
+	EN     ListingDocument
+	CN     ListingDocument
+
+	raw    map[string][]byte
+	memory *tm.Memory
+	logger *logging.Logger
+	closer io.Closer
+}
+
+// openListingSession 加载配置、规则与需求，读取并解析已有的中英文件。
+func openListingSession(opts ListingFileOptions) (*listingSession, error) {
+	cwd := strings.TrimSpace(opts.CWD)
+	if cwd == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("读取当前目录失败：%w", err)
+		}
+		cwd = wd
+	}
+	if strings.TrimSpace(opts.Requirement) == "" {
+		return nil, fmt.Errorf("缺少 --req 需求文件")
+	}
+	enPath := absPath(cwd, opts.Listing)
+	if !strings.HasSuffix(enPath, "_en.md") {
+		return nil, fmt.Errorf("listing 文件应为 _en.md：%s", opts.Listing)
+	}
+	s := &listingSession{ENPath: enPath, CNPath: strings.TrimSuffix(enPath, "_en.md") + "_cn.md", raw: map[string][]byte{}}
+
+	cfg, paths, err := config.Load(opts.ConfigPath, cwd)
+	if err != nil {
+		return nil, err
+	}
+	overrideConfig(cfg, Options{Provider: opts.Provider, MaxRetries: opts.MaxRetries, Cache: opts.Cache})
+	providers, err := resolveProviders(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.logger, s.closer, err = logging.New(opts.Stdout, opts.LogFile, opts.Verbose, false)
+	if err != nil {
+		return nil, fmt.Errorf("初始化日志失败：%w", err)
+	}
+	if err := s.load(opts, cfg, paths, providers, cwd); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *listingSession) load(opts ListingFileOptions, cfg *config.Config, paths *config.Paths, providers providerSetup, cwd string) error {
+	var err error
+	if s.Rules, err = syncAndReadRules(cfg, paths, s.logger, nil); err != nil {
+		return err
+	}
+	if s.Req, err = listing.ParseFile(absPath(cwd, opts.Requirement)); err != nil {
+		return err
+	}
+	params, err := resolveRequirementParams(s.Req, s.Rules, cfg, providers.ProviderCfg)
+	if err != nil {
+		return fmt.Errorf("需求生成参数无效：%w", err)
+	}
+	s.Rules = params.Rules
+	params.emit(s.logger, s.Req.SourcePath)
+	var apiKey string
+	if !providers.Mock {
+		if _, apiKey, err = ensureDeepSeekAPIKey(paths, cfg.APIKeyEnv); err != nil {
+			return err
+		}
+	}
+	for _, side := range []struct {
+		lang, path string
+		doc        *ListingDocument
+	}{{"en", s.ENPath, &s.EN}, {"cn", s.CNPath, &s.CN}} {
+		if s.raw[side.lang], err = os.ReadFile(side.path); err != nil {
+			return fmt.Errorf("读取 listing 文件失败：%w", err)
+		}
+		if *side.doc, err = parseListingMarkdown(side.lang, s.Rules, string(s.raw[side.lang])); err != nil {
+			return fmt.Errorf("解析 listing 文件失败（%s）：%w", side.path, err)
+		}
+	}
+	client, translateClient, err := newRunClients(cfg, paths, s.logger, nil)
+	if err != nil {
+		return err
+	}
+	s.memory = openTranslationMemory(cfg, paths, providers.TranslateProvider, s.logger)
+	translateClient.SetMemory(s.memory)
+	s.Gen = bilingualGenerateOptions{
+		Context:              contextOrBackground(opts.Context),
+		Req:                  s.Req,
+		CharTolerance:        params.CharTolerance,
+		Provider:             cfg.Provider,
+		ProviderCfg:          params.ProviderCfg,
+		TranslateProvider:    providers.TranslateProvider,
+		TranslateProviderCfg: providers.TranslateProviderCfg,
+		APIKey:               apiKey,
+		Rules:                s.Rules,
+		MaxRetries:           cfg.MaxRetries,
+		Client:               client,
+		TranslateClient:      translateClient,
+		Logger:               s.logger,
+		Style:                newStylePolicy(cfg.Style),
+		FactCheck:            cfg.FactCheckEnabled(),
+		Protect:              cfg.Protect,
+		ReferenceTitle:       cfg.ReferenceTitle,
+		Library:              openLibrary(cfg, paths, s.logger),
+	}
+	return nil
+}
+
+func (s *listingSession) Close() {
+	if s.closer != nil {
+		s.closer.Close()
+	}
+}
+
+// save 校验后原地改写中英文件，改写前把原文件备份为 .bak；成功后记入翻译记忆。
+func (s *listingSession) save(event, summary string) error {
+	if err := validateDocumentBySectionRules("en", s.Req, s.EN, s.Rules); err != nil {
+		return fmt.Errorf("改写后校验失败：%w", err)
+	}
+	if err := validateDocumentBySectionRules("cn", s.Req, s.CN, s.Rules); err != nil {
+		return fmt.Errorf("改写后中文校验失败：%w", err)
+	}
+	if err := output.WriteFilesAtomic(s.Gen.Context,
+		output.File{Path: s.ENPath + ".bak", Data: s.raw["en"]},
+		output.File{Path: s.CNPath + ".bak", Data: s.raw["cn"]},
+		output.File{Path: s.ENPath, Data: []byte(RenderMarkdown("en", s.Req, s.EN))},
+		output.File{Path: s.CNPath, Data: []byte(RenderMarkdown("cn", s.Req, s.CN))},
+	); err != nil {
+		return fmt.Errorf("写入 listing 文件失败：%w", err)
+	}
+	s.logger.Emit(logging.Event{Event: event, Input: s.Req.SourcePath, OutputFile: s.ENPath, Error: summary})
+	return nil
+}
+
+// Regen 读取已有输出，重新生成指定分段后原地改写中英文件；改写前把原文件备份为 .bak。
+func Regen(opts RegenOptions) error {
+	target, err := parseSectionTarget(opts.Section)
+	if err != nil {
+		return err
+	}
+	s, err := openListingSession(opts.ListingFileOptions)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	sec, err := resolveSectionTarget(s.Rules, target, s.EN)
+	if err != nil {
+		return err
+	}
+	if s.EN, s.CN, err = regenerateSection(s.Gen, sec, target, opts.Note, s.EN, s.CN); err != nil {
+		return err
+	}
+	return s.save("regen_written", target.String())
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+)
+
+func TestParseSectionTarget(t *testing.T) {
+	cases := map[string]sectionTarget{
+		"description": {Name: "description"},
+		"bullets:3":   {Name: "bullets", Item: 3},
+		"Bullets[2]":  {Name: "bullets", Item: 2},
+		"bullets[:4]": {Name: "bullets", Item: 4},
+	}
+	for spec, want := range cases {
+		got, err := parseSectionTarget(spec)
+		if err != nil || got != want {
+			t.Fatalf("parseSectionTarget(%q) = %#v, %v", spec, got, err)
+		}
+	}
+	for _, spec := range []string{"", "bullets:0", "bullets:x", "a b"} {
+		if _, err := parseSectionTarget(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestResolveSectionTarget(t *testing.T) {
+	rules := testRules()
+	en, _ := composeFixtureDocs()
+	if _, err := resolveSectionTarget(rules, sectionTarget{Name: "bullets", Item: 5}, en); err != nil {
+		t.Fatalf("bullets:5 should be valid: %v", err)
+	}
+	for _, target := range []sectionTarget{{Name: "slogan"}, {Name: "title", Item: 1}, {Name: "bullets", Item: 6}} {
+		if _, err := resolveSectionTarget(rules, target, en); err == nil {
+			t.Fatalf("expected error for %v", target)
+		}
+	}
+}
+
+func TestRegenRewritesSectionWithBackup(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	if _, err := Run(Options{Inputs: []string{reqPath}, ConfigPath: cfgPath, CWD: workDir, Provider: "mock", Stdout: ioDiscard{}, Stderr: ioDiscard{}}); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	enFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md"))
+	if len(enFiles) != 1 {
+		t.Fatalf("expected one en output, got %v", enFiles)
+	}
+	enPath := enFiles[0]
+	cnPath := strings.TrimSuffix(enPath, "_en.md") + "_cn.md"
+	origEN, _ := os.ReadFile(enPath)
+	origCN, _ := os.ReadFile(cnPath)
+
+	for _, section := range []string{"bullets:3", "description"} {
+		err := Regen(RegenOptions{
+			ListingFileOptions: ListingFileOptions{
+				Listing:     filepath.Base(enPath),
+				Requirement: reqPath,
+				ConfigPath:  cfgPath,
+				Provider:    "mock",
+				CWD:         workDir,
+				Stdout:      ioDiscard{},
+			},
+			Section: section,
+		})
+		if err != nil {
+			t.Fatalf("regen %s error: %v", section, err)
+		}
+	}
+	bak, _ := os.ReadFile(enPath + ".bak")
+	if len(bak) == 0 {
+		t.Fatalf("expected en backup")
+	}
+	if cnBak, _ := os.ReadFile(cnPath + ".bak"); len(cnBak) == 0 {
+		t.Fatalf("expected cn backup")
+	}
+	enRaw, _ := os.ReadFile(enPath)
+	cnRaw, _ := os.ReadFile(cnPath)
+	cacheDir, _ := os.UserCacheDir()
+	rules, err := config.ReadSectionRules(filepath.Join(cacheDir, "syl-listing", "rules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, side := range []struct {
+		lang      string
+		orig, now []byte
+	}{{"en", origEN, enRaw}, {"cn", origCN, cnRaw}} {
+		before, err := parseListingMarkdown(side.lang, rules, string(side.orig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		after, err := parseListingMarkdown(side.lang, rules, string(side.now))
+		if err != nil {
+			t.Fatalf("rewritten %s listing should parse: %v", side.lang, err)
+		}
+		if after.Title != before.Title || after.SearchTerms != before.SearchTerms {
+			t.Fatalf("untouched sections changed in %s", side.lang)
+		}
+		for i := range before.BulletPoints {
+			if i != 2 && after.BulletPoints[i] != before.BulletPoints[i] {
+				t.Fatalf("%s bullet %d should be kept", side.lang, i+1)
+			}
+		}
+	}
+
+	if err := Regen(RegenOptions{ListingFileOptions: ListingFileOptions{Listing: filepath.Base(enPath), Requirement: reqPath, ConfigPath: cfgPath, Provider: "mock", CWD: workDir, Stdout: ioDiscard{}}, Section: "title:1"}); err == nil {
+		t.Fatalf("item regen of a single-line section should fail")
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/logging"
+)
+
+// requirementParams 是单个需求文件实际生效的生成参数：全局配置与规则包叠加该文件的生成参数。
+type requirementParams struct {
+	Rules         config.SectionRules
+	CharTolerance int
+	Num           int
+	ProviderCfg   config.ProviderConfig
+	// Changed 列出本需求覆盖的项，为空表示完全沿用全局配置与规则包。
+	Changed []string
+}
+
+// resolveRequirementParams 解析需求文件的生成参数并叠加到规则与配置上；参数无效时返回错误，该需求不生成。
+func resolveRequirementParams(req listing.Requirement, rules config.SectionRules, cfg *config.Config, providerCfg config.ProviderConfig) (requirementParams, error) {
+	p := requirementParams{Rules: rules, CharTolerance: cfg.CharTolerance, Num: cfg.Output.Num, ProviderCfg: providerCfg}
+	o, err := config.ParseRequirementOverrides(req.GenerationParams)
+	if err != nil {
+		return requirementParams{}, err
+	}
+	if o.IsZero() {
+		return p, nil
+	}
+	if p.Rules, p.Changed, err = rules.WithOverrides(o); err != nil {
+		return requirementParams{}, err
+	}
+	if o.CharTolerance != nil {
+		p.CharTolerance = *o.CharTolerance
+	}
+	if o.Num > 0 {
+		p.Num = o.Num
+	}
+	if o.Model != "" {
+		p.ProviderCfg.Model = o.Model
+	}
+	p.Changed = append(o.Describe(), p.Changed...)
+	return p, nil
+}
+
+// summary 概括每个分段的条数、长度与禁用词数，以及容差、候选数与模型。
+func (p requirementParams) summary() string {
+	parts := make([]string, 0, len(p.Rules.Sections())+1)
+	for _, sec := range p.Rules.Sections() {
+		f, err := p.Rules.Get(sec.Name)
+		if err != nil {
+			continue
+		}
+		r := f.Parsed
+		s := sec.Name
+		switch {
+		case f.OutputKind() == config.OutputParagraphs:
+			s += fmt.Sprintf(" %d段", r.Output.Paragraphs)
+		case r.Output.Lines > 1:
+			s += fmt.Sprintf(" %d条", r.Output.Lines)
+		}
+		if minV, maxV := r.Constraints.MinCharsPerLine.Value, r.Constraints.MaxCharsPerLine.Value; minV > 0 || maxV > 0 {
+			s += fmt.Sprintf(" 每条%s-%s", intOrDash(minV), intOrDash(maxV))
+		}
+		if v := r.Constraints.MaxChars.Value; v > 0 {
+			s += fmt.Sprintf(" ≤%d", v)
+		}
+		if n := len(r.Forbidden); n > 0 {
+			s += fmt.Sprintf(" 禁用词%d", n)
+		}
+		parts = append(parts, s)
+	}
+	model := strings.TrimSpace(p.ProviderCfg.Model)
+	if model == "" {
+		model = "-"
+	}
+	parts = append(parts, fmt.Sprintf("容差 %d，候选 %d，模型 %s", p.CharTolerance, p.Num, model))
+	out := strings.Join(parts, "；")
+	if len(p.Changed) > 0 {
+		out += "（本需求覆盖：" + strings.Join(p.Changed, ", ") + "）"
+	}
+	return out
+}
+
+func (p requirementParams) emit(logger *logging.Logger, input string) {
+	logger.Emit(logging.Event{Event: "effective_rules", Input: input, Model: p.ProviderCfg.Model, Attempt: p.Num, Error: p.summary()})
+}
+
+func intOrDash(v int) string {
+	if v <= 0 {
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
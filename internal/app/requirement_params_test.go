@@ -0,0 +1,57 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+)
+
+func TestRunAppliesRequirementParams(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	okPath := filepath.Join(workDir, "three.md")
+	badPath := filepath.Join(workDir, "bad.md")
+	for path, params := range map[string]string{
+		okPath:  "---\nbullets: 3\nnum: 2\nchar_tolerance: 0\nmodel: mock-alt\nforbidden: [cheap]\n---",
+		badPath: "# 生成参数\n```yaml\nbullet: 3\n```",
+	} {
+		raw := strings.Join([]string{"===Listing Requirements===", params, "品牌名: BrandX", "分类: Cat", "# 关键词库", "- alpha", "- beta"}, "\n")
+		if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	res, err := Run(Options{Inputs: []string{okPath, badPath}, ConfigPath: cfgPath, CWD: workDir, Provider: "mock", Stdout: &out, Stderr: ioDiscard{}})
+	if err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	if res.Succeeded != 2 || res.Failed != 1 {
+		t.Fatalf("expected two candidates for three.md and bad.md rejected: %+v\n%s", res, out.String())
+	}
+	for _, want := range []string{"[bad.md] 校验失败：生成参数格式错误", "[three.md] 生效规则：", "bullets 3条", "容差 0，候选 2，模型 mock-alt", "本需求覆盖：char_tolerance=0, num=2, model=mock-alt, title.forbidden+1, bullets.lines=3"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	cacheDir, _ := os.UserCacheDir()
+	rules, err := config.ReadSectionRules(filepath.Join(cacheDir, "syl-listing", "rules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, _, err = rules.WithOverrides(config.RequirementOverrides{Sections: map[string]config.SectionOverride{"bullets": {Lines: 3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md"))
+	if len(enFiles) != 2 {
+		t.Fatalf("expected two en outputs, got %v", enFiles)
+	}
+	raw, _ := os.ReadFile(enFiles[0])
+	doc, err := parseListingMarkdown("en", rules, string(raw))
+	if err != nil || len(doc.BulletPoints) != 3 {
+		t.Fatalf("expected 3 bullets: %v %d", err, len(doc.BulletPoints))
+	}
+}
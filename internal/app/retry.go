@@ -1,12 +1,15 @@
 package app
 
 import (
+	"context"
 	"math/rand"
 	"strings"
 	"time"
 )
 
 type retryOptions struct {
+	// Context 取消后不再发起新的尝试，退避等待也立即结束；nil 视为 context.Background()。
+	Context    context.Context
 	MaxRetries int
 	BaseDelay  time.Duration
 	MaxDelay   time.Duration
@@ -35,14 +38,22 @@ func withExponentialBackoff(opts retryOptions, fn func(attempt int) error) error
 		jitter = 1
 	}
 
+	ctx := contextOrBackground(opts.Context)
+
 	var lastErr error
 	for attempt := 1; attempt <= attempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			if lastErr != nil {
+				return lastErr
+			}
+			return err
+		}
 		if err := fn(attempt); err == nil {
 			return nil
 		} else {
 			lastErr = err
 		}
-		if attempt == attempts {
+		if attempt == attempts || ctx.Err() != nil {
 			break
 		}
 		wait := backoffDuration(attempt, base, maxDelay, jitter)
@@ -59,11 +70,35 @@ func withExponentialBackoff(opts retryOptions, fn func(attempt int) error) error
 		if opts.OnRetry != nil {
 			opts.OnRetry(attempt, wait, lastErr)
 		}
-		time.Sleep(wait)
+		if !sleepContext(ctx, wait) {
+			break
+		}
 	}
 	return lastErr
 }
 
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// sleepContext 等待 d；ctx 先结束时返回 false。
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 func backoffDuration(attempt int, base, maxDelay time.Duration, jitter float64) time.Duration {
 	if attempt < 1 {
 		attempt = 1
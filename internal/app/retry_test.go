@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -112,3 +113,38 @@ func TestIsRateLimitError(t *testing.T) {
 	}
 	_ = strings.Contains
 }
+
+func TestWithExponentialBackoffStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	start := time.Now()
+	err := withExponentialBackoff(retryOptions{
+		Context:    ctx,
+		MaxRetries: 5,
+		BaseDelay:  10 * time.Second,
+		MaxDelay:   10 * time.Second,
+		OnRetry: func(attempt int, wait time.Duration, err error) {
+			cancel()
+		},
+	}, func(attempt int) error {
+		calls++
+		return errors.New("boom")
+	})
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("expected last error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected no attempts after cancel, got %d", calls)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatalf("backoff sleep was not interrupted")
+	}
+
+	err = withExponentialBackoff(retryOptions{Context: ctx}, func(attempt int) error {
+		t.Fatalf("fn should not run with cancelled context")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
+)
+
+// reviewSettings 是一次运行的评审设置；nil 表示不评审。
+type reviewSettings struct {
+	Rubric *config.ReviewRubric
+	Repair bool
+	// Model 为空时沿用生成模型。
+	Model string
+}
+
+// newReviewSettings 按配置与需求生效规则中的量表创建评审设置；开启评审但规则没有量表时告警并关闭该需求的评审。
+func newReviewSettings(cfg config.ReviewConfig, rules config.SectionRules, logger *logging.Logger, input string) *reviewSettings {
+	if !cfg.Enabled {
+		return nil
+	}
+	if rules.Review == nil {
+		logger.Emit(logging.Event{Level: "warn", Event: "review_unavailable", Input: input, Error: fmt.Sprintf("规则包中没有 %s，跳过评审", config.ReviewRubricFileName)})
+		return nil
+	}
+	return &reviewSettings{Rubric: rules.Review, Repair: cfg.Repair, Model: strings.TrimSpace(cfg.Model)}
+}
+
+// listingReview 是模型评审结果，写入 listing_<id>_review.json。
+type listingReview struct {
+	Model    string          `json:"model"`
+	Scale    int             `json:"scale"`
+	MinScore float64         `json:"min_score"`
+	Overall  float64         `json:"overall"`
+	Sections []sectionReview `json:"sections"`
+}
+
+type sectionReview struct {
+	Section string             `json:"section"`
+	Score   float64            `json:"score"`
+	Scores  map[string]float64 `json:"scores"`
+	Comment string             `json:"comment"`
+	Low     bool               `json:"low"`
+	// Repaired 表示该分段已按评语修复并重新翻译，修复后重新评审，ScoreBeforeRepair 为修复前的分数；
+	// 重新评审失败时 Stale 为 true，分数仍为修复前的评审结果。
+	Repaired          bool    `json:"repaired,omitempty"`
+	ScoreBeforeRepair float64 `json:"score_before_repair,omitempty"`
+	Stale             bool    `json:"stale,omitempty"`
+}
+
+// lowSections 返回低于及格线的分段名。
+func (r *listingReview) lowSections() []string {
+	if r == nil {
+		return nil
+	}
+	var out []string
+	for _, s := range r.Sections {
+		if s.Low {
+			out = append(out, s.Section)
+		}
+	}
+	return out
+}
+
+func (r *listingReview) summary() string {
+	msg := fmt.Sprintf("总分 %.2f/%d", r.Overall, r.Scale)
+	if low := r.lowSections(); len(low) > 0 {
+		msg += "；低分分段：" + strings.Join(low, "、")
+	}
+	return msg
+}
+
+// reviewableSections 返回需要评审的分段：本地拼接（deterministic）的分段不经模型生成，不参与评审。
+func reviewableSections(rules config.SectionRules) []config.ManifestSection {
+	var out []config.ManifestSection
+	for _, sec := range rules.Sections() {
+		rule, err := rules.Get(sec.Name)
+		if err != nil || resolveSectionExecutionPolicy(rule).useDeterministic() {
+			continue
+		}
+		out = append(out, sec)
+	}
+	return out
+}
+
+func buildReviewSystemPrompt(rubric *config.ReviewRubric, sections []config.ManifestSection) string {
+	names := make([]string, 0, len(rubric.Criteria))
+	for _, c := range rubric.Criteria {
+		names = append(names, fmt.Sprintf("%q:<1~%d 的整数>", c.Name, rubric.Scale))
+	}
+	sectionNames := make([]string, 0, len(sections))
+	for _, sec := range sections {
+		sectionNames = append(sectionNames, sec.Name)
+	}
+	// 待评审分段以 YAML 键接在量表之后，mock 据此打分。
+	return strings.TrimSpace(rubric.Raw) + "\nreview_sections: [" + strings.Join(sectionNames, ", ") + "]" + fmt.Sprintf(`
+
+【JSON协议】
+Return valid json only.
+必须只返回一个 json object，禁止 markdown 代码块、禁止解释。格式：
+{"sections":[{"section":"<分段名>","scores":{%s},"comment":"<中文评语：指出具体问题与修改方向>"}]}
+每个待评审分段都必须出现一次，且给出全部评分项。`, strings.Join(names, ","))
+}
+
+func buildReviewUserPrompt(opts sectionGenerateOptions, sections []config.ManifestSection, doc ListingDocument) string {
+	var b strings.Builder
+	b.WriteString("【需求原文】\n")
+	b.WriteString(opts.Req.BodyAfterMarker)
+	b.WriteString("\n\n【关键词】\n")
+	for _, kw := range opts.Req.Keywords {
+		b.WriteString("- ")
+		b.WriteString(kw)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n【待评审 listing】\n")
+	names := make([]string, 0, len(sections))
+	for _, sec := range sections {
+		names = append(names, sec.Name)
+		b.WriteString("\n### ")
+		b.WriteString(sec.Name)
+		b.WriteString("\n")
+		for i, it := range doc.SectionItems(sec.Name) {
+			if sec.Output == config.OutputLines {
+				b.WriteString(fmt.Sprintf("%d. ", i+1))
+			}
+			b.WriteString(it)
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n【分段列表】")
+	b.WriteString(strings.Join(names, ", "))
+	b.WriteString("\n【当前任务】评审\n")
+	return b.String()
+}
+
+// parseReviewResponse 解析评审 JSON 并按量表计算加权分；缺分段、缺评分项或分数越界都视为无效响应。
+func parseReviewResponse(text string, rubric *config.ReviewRubric, sections []config.ManifestSection) ([]sectionReview, error) {
+	var raw struct {
+		Sections []struct {
+			Section string             `json:"section"`
+			Scores  map[string]float64 `json:"scores"`
+			Comment string             `json:"comment"`
+		} `json:"sections"`
+	}
+	if err := decodeJSONObject(text, &raw); err != nil {
+		return nil, err
+	}
+	byName := map[string]int{}
+	for i, s := range raw.Sections {
+		byName[strings.TrimSpace(s.Section)] = i
+	}
+	out := make([]sectionReview, 0, len(sections))
+	var issues []string
+	for _, sec := range sections {
+		idx, ok := byName[sec.Name]
+		if !ok {
+			issues = append(issues, fmt.Sprintf("缺少分段 %s", sec.Name))
+			continue
+		}
+		got := raw.Sections[idx]
+		r := sectionReview{Section: sec.Name, Scores: map[string]float64{}, Comment: strings.TrimSpace(got.Comment)}
+		var sum, weights float64
+		for _, c := range rubric.Criteria {
+			v, ok := got.Scores[c.Name]
+			if !ok {
+				issues = append(issues, fmt.Sprintf("%s 缺少评分项 %s", sec.Name, c.Name))
+				continue
+			}
+			if v < 1 || v > float64(rubric.Scale) {
+				issues = append(issues, fmt.Sprintf("%s 的 %s 分数越界：%v", sec.Name, c.Name, v))
+				continue
+			}
+			r.Scores[c.Name] = v
+			sum += v * c.EffectiveWeight()
+			weights += c.EffectiveWeight()
+		}
+		if weights > 0 {
+			r.Score = math.Round(sum/weights*100) / 100
+		}
+		r.Low = r.Score < rubric.MinScore
+		out = append(out, r)
+	}
+	if len(issues) > 0 {
+		return nil, errors.New(strings.Join(issues, "；"))
+	}
+	return out, nil
+}
+
+// reviewListing 请求模型按量表评审英文 listing，响应无效时带问题重试。
+func reviewListing(opts sectionGenerateOptions, settings *reviewSettings, doc ListingDocument) (*listingReview, error) {
+	rubric := settings.Rubric
+	sections := reviewableSections(opts.Rules)
+	model := settings.Model
+	if model == "" {
+		model = opts.ProviderCfg.Model
+	}
+	systemPrompt := buildReviewSystemPrompt(rubric, sections)
+	userPrompt := buildReviewUserPrompt(opts, sections, doc)
+	history := make([]llm.Message, 0, 4)
+	var result []sectionReview
+	lastIssues := ""
+	err := withExponentialBackoff(retryOptions{
+		Context:    opts.Context,
+		MaxRetries: opts.MaxRetries,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   8 * time.Second,
+		Jitter:     0.25,
+		OnRetry: func(attempt int, wait time.Duration, err error) {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "retry_backoff_review", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Attempt: attempt, WaitMS: wait.Milliseconds(), Error: err.Error()})
+		},
+	}, func(attempt int) error {
+		messages := append([]llm.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		}, history...)
+		reqEvent := logging.Event{Event: "api_request_review", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Provider: opts.Provider, Model: model, APIMode: opts.ProviderCfg.APIMode, BaseURL: opts.ProviderCfg.BaseURL, Attempt: attempt}
+		if opts.Logger.Verbose() {
+			reqEvent.SystemPrompt = systemPrompt
+			reqEvent.UserPrompt = userPrompt
+		}
+		opts.Logger.Emit(reqEvent)
+		resp, err := opts.Client.Generate(contextOrBackground(opts.Context), llm.Request{
+			Provider:        opts.Provider,
+			BaseURL:         opts.ProviderCfg.BaseURL,
+			Candidate:       opts.Candidate,
+			Model:           model,
+			APIMode:         opts.ProviderCfg.APIMode,
+			APIKey:          opts.APIKey,
+			ReasoningEffort: opts.ProviderCfg.ModelReasoningEffort,
+			SystemPrompt:    systemPrompt,
+			UserPrompt:      userPrompt,
+			Messages:        messages,
+			JSONMode:        providerSupportsJSONMode(opts.Provider),
+		})
+		if err != nil {
+			lastIssues = "API 调用失败: " + err.Error()
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "api_error_review", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Attempt: attempt, Error: err.Error()})
+			return errors.New(lastIssues)
+		}
+		respEvent := logging.Event{Event: "api_response_review", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Provider: opts.Provider, Model: model, LatencyMS: resp.LatencyMS, Attempt: attempt}
+		if resp.Cached {
+			respEvent.Cache = "hit"
+		}
+		if opts.Logger.Verbose() {
+			respEvent.ResponseText = resp.Text
+		}
+		opts.Logger.Emit(respEvent)
+		parsed, err := parseReviewResponse(resp.Text, rubric, sections)
+		if err != nil {
+			lastIssues = err.Error()
+			history = append(history,
+				llm.Message{Role: "assistant", Content: resp.Text},
+				llm.Message{Role: "user", Content: buildJSONRepairPrompt("- "+lastIssues, "{\"sections\":[{\"section\":\"...\",\"scores\":{...},\"comment\":\"...\"}]}")},
+			)
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "validate_error_review", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Attempt: attempt, Error: lastIssues})
+			return errors.New(lastIssues)
+		}
+		result = parsed
+		return nil
+	})
+	if err != nil {
+		if lastIssues == "" {
+			lastIssues = err.Error()
+		}
+		return nil, fmt.Errorf("评审重试后仍失败：%s", lastIssues)
+	}
+	review := &listingReview{Model: model, Scale: rubric.Scale, MinScore: rubric.MinScore, Sections: result}
+	var total float64
+	for _, s := range result {
+		total += s.Score
+	}
+	if len(result) > 0 {
+		review.Overall = math.Round(total/float64(len(result))*100) / 100
+	}
+	return review, nil
+}
+
+// reviewComments 把分段评语与低分项整理成修复提示。
+func (s sectionReview) reviewComments(rubric *config.ReviewRubric) []string {
+	var out []string
+	if s.Comment != "" {
+		out = append(out, s.Comment)
+	}
+	names := make([]string, 0, len(s.Scores))
+	for name, v := range s.Scores {
+		if v < rubric.MinScore {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		desc := name
+		for _, c := range rubric.Criteria {
+			if c.Name == name && c.Description != "" {
+				desc = fmt.Sprintf("%s（%s）", name, c.Description)
+			}
+		}
+		out = append(out, fmt.Sprintf("评分项 %s 得分 %.0f/%d，需改进", desc, s.Scores[name], rubric.Scale))
+	}
+	return out
+}
+
+// reviewAndRepair 评审英文 listing；开启修复时把低分分段带评语重新生成一轮并重新翻译该分段。
+// 评审或修复失败只告警，保留原文档。
+func reviewAndRepair(opts bilingualGenerateOptions, settings *reviewSettings, enDoc, cnDoc ListingDocument) (ListingDocument, ListingDocument, *listingReview) {
+	if settings == nil {
+		return enDoc, cnDoc, nil
+	}
+	genOpts := opts.enSectionOptions()
+	review, err := reviewListing(genOpts, settings, enDoc)
+	if err != nil {
+		opts.Logger.Emit(logging.Event{Level: "warn", Event: "review_failed", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Error: err.Error()})
+		return enDoc, cnDoc, nil
+	}
+	opts.Logger.Emit(logging.Event{Event: "review_ok", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Error: review.summary()})
+	if !settings.Repair {
+		return enDoc, cnDoc, review
+	}
+	for i, s := range review.Sections {
+		if !s.Low {
+			continue
+		}
+		sec, ok := opts.Rules.Section(s.Section)
+		if !ok {
+			continue
+		}
+		repairOpts := genOpts
+		repairOpts.Extras.ReviewComments = s.reviewComments(settings.Rubric)
+		repairOpts.Extras.Reviewed = enDoc.SectionItems(sec.Name)
+		items, _, err := generateManifestSection(repairOpts, sec, enDoc)
+		var cn []string
+		if err == nil {
+			cn, err = opts.translateCNSection(sec, items, cnDoc)
+		}
+		if err != nil {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "review_repair_failed", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Error: fmt.Sprintf("%s：%s", s.Section, err.Error())})
+			continue
+		}
+		nextEN, nextCN := enDoc, cnDoc
+		// setSection 会原地改写扩展分段，先复制，校验失败时保留原文档。
+		nextEN.Extra = append([]SectionContent{}, enDoc.Extra...)
+		nextCN.Extra = append([]SectionContent{}, cnDoc.Extra...)
+		nextEN.setSection(sec, "en", items)
+		nextCN.setSection(sec, "cn", cn)
+		if err := validateDocumentBySectionRules("en", opts.Req, nextEN, opts.Rules); err != nil {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "review_repair_failed", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Error: fmt.Sprintf("%s：%s", s.Section, err.Error())})
+			continue
+		}
+		enDoc, cnDoc = nextEN, nextCN
+		review.Sections[i].Repaired = true
+		opts.Logger.Emit(logging.Event{Event: "review_repaired", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Error: s.Section})
+	}
+	return enDoc, cnDoc, rereviewRepaired(opts, genOpts, settings, review, enDoc)
+}
+
+// rereviewRepaired 对修复后的文档重新评审，使评审结果与写出的文案一致；失败时保留修复前的分数并标记 Stale。
+func rereviewRepaired(opts bilingualGenerateOptions, genOpts sectionGenerateOptions, settings *reviewSettings, review *listingReview, enDoc ListingDocument) *listingReview {
+	repaired := map[string]float64{}
+	for _, s := range review.Sections {
+		if s.Repaired {
+			repaired[s.Section] = s.Score
+		}
+	}
+	if len(repaired) == 0 {
+		return review
+	}
+	again, err := reviewListing(genOpts, settings, enDoc)
+	if err != nil {
+		opts.Logger.Emit(logging.Event{Level: "warn", Event: "review_failed", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Error: "修复后重新评审失败，修复分段保留修复前的分数：" + err.Error()})
+		for i := range review.Sections {
+			review.Sections[i].Stale = review.Sections[i].Repaired
+		}
+		return review
+	}
+	for i, s := range again.Sections {
+		if before, ok := repaired[s.Section]; ok {
+			again.Sections[i].Repaired, again.Sections[i].ScoreBeforeRepair = true, before
+		}
+	}
+	opts.Logger.Emit(logging.Event{Event: "review_ok", Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: "en", Error: again.summary()})
+	return again
+}
+
+// reviewSidecarPath 返回评审结果文件路径：listing_<id>_review.json。
+func reviewSidecarPath(enPath string) string {
+	return strings.TrimSuffix(enPath, "_en.md") + "_review.json"
+}
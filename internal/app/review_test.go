@@ -0,0 +1,187 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/translator"
+)
+
+func testReviewRubric() *config.ReviewRubric {
+	return &config.ReviewRubric{
+		Criteria: []config.ReviewCriterion{{Name: "clarity", Description: "表达清晰"}, {Name: "grammar", Weight: 3}},
+		Scale:    5,
+		MinScore: 3,
+		Raw:      "criteria:\n  - name: clarity\n  - name: grammar\n",
+	}
+}
+
+func TestParseReviewResponse(t *testing.T) {
+	rubric := testReviewRubric()
+	sections := reviewableSections(testRules())
+	names := make([]string, 0, len(sections))
+	for _, s := range sections {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "title,bullets,description,search_terms" {
+		t.Fatalf("unexpected reviewable sections: %v", names)
+	}
+
+	text := `{"sections":[
+{"section":"title","scores":{"clarity":1,"grammar":3},"comment":"too generic"},
+{"section":"bullets","scores":{"clarity":5,"grammar":5}},
+{"section":"description","scores":{"clarity":4,"grammar":4}},
+{"section":"search_terms","scores":{"clarity":5,"grammar":4}}]}`
+	got, err := parseReviewResponse(text, rubric, sections)
+	if err != nil {
+		t.Fatalf("parse review failed: %v", err)
+	}
+	if got[0].Score != 2.5 || !got[0].Low || got[1].Low || got[3].Score != 4.25 {
+		t.Fatalf("unexpected weighted scores: %+v", got)
+	}
+	comments := got[0].reviewComments(rubric)
+	if len(comments) != 2 || comments[0] != "too generic" || !strings.Contains(comments[1], "clarity（表达清晰） 得分 1/5") {
+		t.Fatalf("unexpected comments: %v", comments)
+	}
+
+	bad := `{"sections":[{"section":"title","scores":{"clarity":9}}]}`
+	_, err = parseReviewResponse(bad, rubric, sections[:1])
+	if err == nil || !strings.Contains(err.Error(), "缺少评分项 grammar") || !strings.Contains(err.Error(), "分数越界") {
+		t.Fatalf("invalid scores should fail: %v", err)
+	}
+	if _, err := parseReviewResponse(`{"sections":[]}`, rubric, sections[:1]); err == nil || !strings.Contains(err.Error(), "缺少分段 title") {
+		t.Fatalf("missing section should fail: %v", err)
+	}
+}
+
+func TestReviewAndRepairRegeneratesLowSection(t *testing.T) {
+	var (
+		repairPrompt string
+		reviews      int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		user := body.Messages[1].Content
+		text := "alpha beta sturdy organizer for desks"
+		if strings.Contains(user, "【当前任务】评审") {
+			reviews++
+			title := `{"section":"title","scores":{"clarity":2,"grammar":2},"comment":"lead with the benefit"}`
+			if reviews > 1 {
+				title = `{"section":"title","scores":{"clarity":4,"grammar":4}}`
+			}
+			text = `{"sections":[` + title + `,
+{"section":"bullets","scores":{"clarity":5,"grammar":5}},
+{"section":"description","scores":{"clarity":5,"grammar":5}},
+{"section":"search_terms","scores":{"clarity":5,"grammar":5}}]}`
+		} else {
+			repairPrompt = user
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger, _, err := logging.New(&buf, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	en, cn := composeFixtureDocs()
+	opts := bilingualGenerateOptions{
+		Req:               listing.Requirement{SourcePath: "/in/demo.md", Brand: "BrandX", Category: "Cat", Keywords: []string{"alpha", "beta"}},
+		Provider:          "deepseek",
+		ProviderCfg:       config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		TranslateProvider: "mock",
+		APIKey:            "k",
+		Rules:             testRules(),
+		MaxRetries:        1,
+		Client:            llm.NewClient(10 * time.Second),
+		TranslateClient:   translator.NewClient(10 * time.Second),
+		Logger:            logger,
+		Candidate:         1,
+	}
+	settings := &reviewSettings{Rubric: testReviewRubric(), Repair: true, Model: "reviewer"}
+	gotEN, gotCN, review := reviewAndRepair(opts, settings, en, cn)
+	if review == nil || review.Model != "reviewer" || review.Overall != 4.75 || reviews != 2 {
+		t.Fatalf("repaired listing should be reviewed again: %+v (%d reviews)", review, reviews)
+	}
+	if s := review.Sections[0]; !s.Repaired || s.Score != 4 || s.ScoreBeforeRepair != 2 || s.Stale || review.Sections[1].Repaired {
+		t.Fatalf("only low title should be repaired and rescored: %+v", review.Sections)
+	}
+	if gotEN.Title != "alpha beta sturdy organizer for desks" || gotCN.Title == cn.Title || gotEN.BulletPoints[0] != en.BulletPoints[0] {
+		t.Fatalf("unexpected repaired docs: %+v / %+v", gotEN, gotCN)
+	}
+	if !strings.Contains(repairPrompt, "被评审的本段内容：\n1. "+en.Title) || !strings.Contains(repairPrompt, "【评审意见】") || !strings.Contains(repairPrompt, "lead with the benefit") {
+		t.Fatalf("review comments should reach repair prompt: %q", repairPrompt)
+	}
+	if !strings.Contains(buf.String(), "低分分段已按评语修复：title") {
+		t.Fatalf("missing review_repaired event: %s", buf.String())
+	}
+
+	settings.Repair = false
+	settings.Rubric.Criteria = append(settings.Rubric.Criteria, config.ReviewCriterion{Name: "missing"})
+	opts.MaxRetries = 0
+	gotEN, _, review = reviewAndRepair(opts, settings, en, cn)
+	if review != nil || gotEN.Title != en.Title || !strings.Contains(buf.String(), "评审失败") {
+		t.Fatalf("failed review should keep docs: %+v %+v", review, gotEN)
+	}
+	if reviewSidecarPath("/out/listing_ab_en.md") != "/out/listing_ab_review.json" {
+		t.Fatalf("unexpected sidecar path")
+	}
+}
+
+func TestRunMockWritesReviewSidecar(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	raw, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, append(raw, []byte("review:\n  enabled: true\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rubric := "criteria:\n  - name: clarity\n  - name: grammar\n"
+	if err := os.WriteFile(filepath.Join(cacheDir, "syl-listing", "rules", config.ReviewRubricFileName), []byte(rubric), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Provider:   "mock",
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err != nil || res.Succeeded != 1 {
+		t.Fatalf("mock run failed: res=%+v err=%v", res, err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(workDir, "listing_*_review.json"))
+	if len(matches) != 1 {
+		t.Fatalf("expected one review sidecar, got %v", matches)
+	}
+	var review listingReview
+	data, _ := os.ReadFile(matches[0])
+	if err := json.Unmarshal(data, &review); err != nil || review.Overall != 5 || len(review.Sections) != 4 {
+		t.Fatalf("unexpected review sidecar: %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+)
+
+// measureLength 按规则的 count 口径计算长度。
+func measureLength(s, mode string) int {
+	switch config.NormalizeCountMode(mode) {
+	case config.CountBytes:
+		return len(s)
+	case config.CountWords:
+		return len(strings.Fields(s))
+	default:
+		return runeLen(s)
+	}
+}
+
+func countUnitLabel(mode string) string {
+	switch config.NormalizeCountMode(mode) {
+	case config.CountBytes:
+		return "字节"
+	case config.CountWords:
+		return "词"
+	default:
+		return "字符"
+	}
+}
+
+// resolveRuleBounds 由规则的 min/max 约束构造区间，带上计数口径与软硬属性。
+func resolveRuleBounds(rule config.SectionRule, minC, maxC config.RuleIntConstraint, tolerance int) charBounds {
+	b := resolveCharBounds(minC.Value, maxC.Value, tolerance)
+	mode := maxC.Count
+	if strings.TrimSpace(mode) == "" {
+		mode = minC.Count
+	}
+	b.count = config.NormalizeCountMode(mode)
+	b.softMin = !rule.EffectiveHard(minC.IsHard())
+	b.softMax = !rule.EffectiveHard(maxC.IsHard())
+	// 容差以字符给出，按词或字节计数时不适用；字节上限多为平台硬限，也不能放宽。退回规则区间本身。
+	if b.count == config.CountWords || b.count == config.CountBytes {
+		b = b.withoutTolerance()
+	}
+	return b
+}
+
+func (b charBounds) withoutTolerance() charBounds {
+	b.tolMin = b.ruleMin
+	b.tolMax = b.ruleMax
+	if !b.hasMin {
+		b.tolMin = 0
+	}
+	if !b.hasMax {
+		b.tolMax = 0
+	}
+	return b
+}
+
+func (b charBounds) measure(s string) int {
+	return measureLength(s, b.count)
+}
+
+func (b charBounds) unit() string {
+	return countUnitLabel(b.count)
+}
+
+// violationIsSoft 判断超出容差的长度是否只触发软约束。
+func (b charBounds) violationIsSoft(n int) bool {
+	if b.hasMin && n < b.tolMin {
+		return b.softMin
+	}
+	if b.hasMax && n > b.tolMax {
+		return b.softMax
+	}
+	return false
+}
+
+// keywordSourceTerms 按 must_contain_top_n_keywords.source 取关键词来源。
+func keywordSourceTerms(c config.RuleKeywordConstraint, req listing.Requirement) []string {
+	switch strings.ToLower(strings.TrimSpace(c.Source)) {
+	case "category", "分类":
+		parts := strings.Split(req.Category, ">")
+		out := make([]string, 0, len(parts))
+		for i := len(parts) - 1; i >= 0; i-- {
+			if p := strings.TrimSpace(parts[i]); p != "" {
+				out = append(out, p)
+			}
+		}
+		return out
+	default:
+		return req.Keywords
+	}
+}
+
+var wordTokenRe = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’][\p{L}]+)?`)
+
+// keywordMatches 按匹配方式判断 text 是否包含关键词 kw。
+func keywordMatches(text, kw, mode string) bool {
+	kw = strings.TrimSpace(kw)
+	if kw == "" {
+		return true
+	}
+	switch mode {
+	case config.MatchExact:
+		return strings.Contains(text, kw)
+	case config.MatchWordBoundary:
+		re, err := regexp.Compile(`(?i)(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(kw) + `($|[^\p{L}\p{N}])`)
+		if err != nil {
+			return strings.Contains(strings.ToLower(text), strings.ToLower(kw))
+		}
+		return re.MatchString(text)
+	case config.MatchStem:
+		return containsStemSequence(stemTokens(text), stemTokens(kw))
+	default:
+		return strings.Contains(strings.ToLower(text), strings.ToLower(kw))
+	}
+}
+
+func stemTokens(s string) []string {
+	raw := wordTokenRe.FindAllString(strings.ToLower(s), -1)
+	out := make([]string, 0, len(raw))
+	for _, t := range raw {
+		out = append(out, stemWord(t))
+	}
+	return out
+}
+
+func containsStemSequence(haystack, needle []string) bool {
+	if len(needle) == 0 {
+		return true
+	}
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		ok := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stemWord 是面向英文复数与常见词尾的轻量词干化，足以让 "pocket" 匹配 "pockets"、"box" 匹配 "boxes"。
+func stemWord(w string) string {
+	w = strings.TrimRightFunc(strings.TrimSuffix(strings.TrimSuffix(w, "'s"), "’s"), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	n := len(w)
+	switch {
+	case n > 4 && strings.HasSuffix(w, "ies"):
+		return w[:n-3] + "y"
+	case n > 4 && (strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes") || strings.HasSuffix(w, "sses") || strings.HasSuffix(w, "xes") || strings.HasSuffix(w, "zes")):
+		return w[:n-2]
+	case n > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		return w[:n-1]
+	}
+	return w
+}
+
+// ruleIssue 是带约束名的校验问题，便于按 execution.priority 排序。
+type ruleIssue struct {
+	Constraint string
+	Text       string
+}
+
+// ruleIssueCollector 把问题按软硬分流：硬约束进入 issues，软约束进入 warnings。
+type ruleIssueCollector struct {
+	rule     config.SectionRule
+	issues   []ruleIssue
+	warnings []string
+}
+
+func (c *ruleIssueCollector) add(constraint string, hard bool, text string) {
+	if hard {
+		c.issues = append(c.issues, ruleIssue{Constraint: constraint, Text: text})
+		return
+	}
+	c.warnings = append(c.warnings, text+"（软约束，已放行）")
+}
+
+func (c *ruleIssueCollector) warn(text string) {
+	c.warnings = append(c.warnings, text)
+}
+
+func (c *ruleIssueCollector) result() ([]string, []string) {
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		return c.rule.PriorityIndex(c.issues[i].Constraint) < c.rule.PriorityIndex(c.issues[j].Constraint)
+	})
+	issues := make([]string, 0, len(c.issues))
+	for _, it := range c.issues {
+		issues = append(issues, it.Text)
+	}
+	return dedupeIssues(issues), dedupeIssues(c.warnings)
+}
+
+// parseParagraphsBySeparator 按 output.paragraph_separator 拆分描述段落。
+// 空值或 blank_line 按空行拆分；newline 按单个换行拆分；其他值作为字面分隔符。
+func parseParagraphsBySeparator(text string, expected int, separator string) ([]string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	separator = strings.ReplaceAll(separator, `\n`, "\n")
+	var chunks []string
+	trimmed := strings.ToLower(strings.TrimSpace(separator))
+	switch {
+	case separator == "" || trimmed == "blank_line" || trimmed == "blank_lines" || trimmed == "empty_line":
+		chunks = splitByBlankLines(text)
+	case trimmed == "newline" || trimmed == "line" || trimmed == "single_newline":
+		chunks = splitNonEmptyLines(text)
+	case trimmed == "":
+		// 字面空白分隔符："\n\n" 视为空行，"\n" 视为逐行。
+		if strings.Count(separator, "\n") >= 2 {
+			chunks = splitByBlankLines(text)
+		} else {
+			chunks = splitNonEmptyLines(text)
+		}
+	default:
+		for _, part := range strings.Split(text, separator) {
+			if p := strings.Join(strings.Fields(part), " "); p != "" {
+				chunks = append(chunks, p)
+			}
+		}
+	}
+	if len(chunks) != expected {
+		return nil, fmt.Errorf("描述段落数量错误：%d != %d", len(chunks), expected)
+	}
+	return chunks, nil
+}
+
+func splitNonEmptyLines(text string) []string {
+	out := make([]string, 0, 4)
+	for _, ln := range strings.Split(text, "\n") {
+		if ln = strings.TrimSpace(ln); ln != "" {
+			out = append(out, ln)
+		}
+	}
+	return out
+}
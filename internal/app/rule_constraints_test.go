@@ -0,0 +1,132 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+)
+
+func TestValidateSearchTermsCountsBytes(t *testing.T) {
+	rule := config.SectionRuleFile{Parsed: config.SectionRule{
+		Output:      config.RuleOutputSpec{Lines: 1},
+		Constraints: config.RuleConstraints{MaxChars: config.RuleIntConstraint{Value: 10, Count: "bytes"}},
+	}}
+	req := listing.Requirement{}
+	// 5 个中文字符 = 15 字节，按字符计会通过，按字节计必须失败。
+	issues, _ := validateSectionText("search_terms", "en", req, "收纳盒收纳", rule, 0)
+	if len(issues) == 0 || !strings.Contains(issues[0], "字节") {
+		t.Fatalf("expected byte-length issue, got %v", issues)
+	}
+	if issues, _ := validateSectionText("search_terms", "en", req, "abc def", rule, 0); len(issues) != 0 {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+}
+
+func TestValidateBytesCountIgnoresCharTolerance(t *testing.T) {
+	rule := config.SectionRuleFile{Parsed: config.SectionRule{
+		Output:      config.RuleOutputSpec{Lines: 1},
+		Constraints: config.RuleConstraints{MaxChars: config.RuleIntConstraint{Value: 10, Count: "bytes"}},
+	}}
+	// 12 字节在 20 的字符容差内，但字节上限不放宽。
+	issues, _ := validateSectionText("search_terms", "en", listing.Requirement{}, "abcdef ghijk", rule, 20)
+	if len(issues) == 0 || !strings.Contains(issues[0], "字节") {
+		t.Fatalf("bytes mode should drop char tolerance, got %v", issues)
+	}
+}
+
+func TestValidateWordsCountIgnoresCharTolerance(t *testing.T) {
+	bounds := resolveRuleBounds(config.SectionRule{}, config.RuleIntConstraint{Value: 3, Count: "words"}, config.RuleIntConstraint{Value: 5, Count: "words"}, 50)
+	if bounds.tolMin != 3 || bounds.tolMax != 5 {
+		t.Fatalf("words mode should drop char tolerance: %+v", bounds)
+	}
+	if issues, _ := validateLineItem("bullets", 1, "one two three four five six", bounds); len(issues) == 0 {
+		t.Fatalf("expected word-count issue")
+	}
+	if issues, warns := validateLineItem("bullets", 1, "one two three four", bounds); len(issues) != 0 || len(warns) != 0 {
+		t.Fatalf("unexpected issues=%v warns=%v", issues, warns)
+	}
+}
+
+func TestSoftConstraintBecomesWarning(t *testing.T) {
+	soft := false
+	rule := config.SectionRuleFile{Parsed: config.SectionRule{
+		Output: config.RuleOutputSpec{Lines: 1},
+		Constraints: config.RuleConstraints{
+			MaxChars:                config.RuleIntConstraint{Value: 10, Hard: &soft},
+			MustContainTopNKeywords: config.RuleKeywordConstraint{Value: 1, Hard: &soft},
+		},
+	}}
+	req := listing.Requirement{Keywords: []string{"gamma"}}
+	issues, warns := validateSectionText("title", "en", req, "alpha beta long title", rule, 0)
+	if len(issues) != 0 || len(warns) != 2 {
+		t.Fatalf("expected soft warnings only, issues=%v warns=%v", issues, warns)
+	}
+	rule.Parsed.Execution.HardRule = "all"
+	if issues, _ := validateSectionText("title", "en", req, "alpha beta long title", rule, 0); len(issues) != 2 {
+		t.Fatalf("hard_rule=all should force hard, got %v", issues)
+	}
+}
+
+func TestIssuesFollowExecutionPriority(t *testing.T) {
+	rule := config.SectionRuleFile{Parsed: config.SectionRule{
+		Output: config.RuleOutputSpec{Lines: 1},
+		Constraints: config.RuleConstraints{
+			MaxChars:                config.RuleIntConstraint{Value: 5},
+			MustContainTopNKeywords: config.RuleKeywordConstraint{Value: 1},
+		},
+		Execution: config.RuleExecutionSpec{Priority: []string{"must_contain_top_n_keywords", "max_chars"}},
+	}}
+	issues, _ := validateSectionText("title", "en", listing.Requirement{Keywords: []string{"gamma"}}, "alpha beta long title", rule, 0)
+	if len(issues) != 2 || !strings.Contains(issues[0], "关键词") || !strings.Contains(issues[1], "长度") {
+		t.Fatalf("unexpected issue order: %v", issues)
+	}
+}
+
+func TestKeywordMatchModes(t *testing.T) {
+	cases := []struct {
+		text, kw, mode string
+		want           bool
+	}{
+		{"Storage Box", "storage box", config.MatchCaseInsensitive, true},
+		{"Storage Box", "storage box", config.MatchExact, false},
+		{"Storage Box", "Storage Box", config.MatchExact, true},
+		{"Boxing gloves", "box", config.MatchCaseInsensitive, true},
+		{"Boxing gloves", "box", config.MatchWordBoundary, false},
+		{"Big box, small", "box", config.MatchWordBoundary, true},
+		{"Jacket with 4 pockets", "pocket", config.MatchStem, true},
+		{"Set of storage boxes", "storage box", config.MatchStem, true},
+		{"Two berries", "berry", config.MatchStem, true},
+		{"Glass bottle", "glasses", config.MatchStem, true},
+		{"Boxing gloves", "box", config.MatchStem, false},
+	}
+	for _, c := range cases {
+		if got := keywordMatches(c.text, c.kw, c.mode); got != c.want {
+			t.Fatalf("keywordMatches(%q,%q,%s)=%v want %v", c.text, c.kw, c.mode, got, c.want)
+		}
+	}
+}
+
+func TestParseParagraphsBySeparator(t *testing.T) {
+	if pars, err := parseParagraphsBySeparator("a\nb\nc", 3, "newline"); err != nil || len(pars) != 3 {
+		t.Fatalf("newline separator failed: %v %v", pars, err)
+	}
+	if pars, err := parseParagraphsBySeparator("a\n\nb", 2, "\\n\\n"); err != nil || len(pars) != 2 {
+		t.Fatalf("escaped blank-line separator failed: %v %v", pars, err)
+	}
+	if pars, err := parseParagraphsBySeparator("first part\n---\nsecond\npart", 2, "---"); err != nil || pars[1] != "second part" {
+		t.Fatalf("literal separator failed: %v %v", pars, err)
+	}
+	if _, err := parseParagraphsBySeparator("a\nb", 2, ""); err == nil {
+		t.Fatalf("default separator should split on blank lines only")
+	}
+}
+
+func TestNormalizeLineByBytes(t *testing.T) {
+	bounds := resolveRuleBounds(config.SectionRule{}, config.RuleIntConstraint{}, config.RuleIntConstraint{Value: 10, Count: "bytes"}, 0)
+	got := normalizeLineByBounds("收纳 盒子 整理", bounds, nil)
+	if len(got) > 10 {
+		t.Fatalf("expected trimmed to 10 bytes, got %q (%d)", got, len(got))
+	}
+}
@@ -1,44 +1,64 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"syl-listing/internal/cache"
+	"syl-listing/internal/cassette"
 	"syl-listing/internal/config"
 	"syl-listing/internal/discovery"
+	"syl-listing/internal/library"
 	"syl-listing/internal/listing"
 	"syl-listing/internal/llm"
 	"syl-listing/internal/logging"
 	"syl-listing/internal/output"
+	"syl-listing/internal/tm"
 	"syl-listing/internal/translator"
 )
 
 type Options struct {
-	Inputs          []string
-	ConfigPath      string
-	OutputDir       string
-	Num             int
-	Concurrency     int
-	MaxRetries      int
-	Provider        string
-	LogFile         string
-	Verbose         bool
+	// Context 取消（如 SIGINT）后停止调度新任务并中断进行中的请求；nil 视为 context.Background()。
+	Context     context.Context
+	Inputs      []string
+	ConfigPath  string
+	OutputDir   string
+	Num         int
+	Concurrency int
+	MaxRetries  int
+	Provider    string
+	LogFile     string
+	Verbose     bool
+	Cache       string
+	Record      string
+	Replay      string
+	ReplayMatch string
+	DryRun      bool
+	// Best 覆盖配置 output.best。
+	Best            string
 	CWD             string
 	Stdout          io.Writer
 	Stderr          io.Writer
 	Stdin           io.Reader
 	InvokedSubcmd   bool
 	NormalizedInput []string
+	// Base 为现有英文 listing 文件，非空时所有需求进入编辑模式；相对路径按 CWD 解析。
+	Base string
 }
 
 type Result struct {
 	Succeeded int
 	Failed    int
+	Cancelled int
 	ElapsedMS int64
 	Balance   string
 }
@@ -46,10 +66,13 @@ type Result struct {
 type candidateJob struct {
 	Req       listing.Requirement
 	Candidate int
+	// Base 非 nil 时以该现有文案为基准编辑。
+	Base *ListingDocument
 }
 
 func Run(opts Options) (result Result, err error) {
 	runStartedAt := time.Now()
+	ctx := contextOrBackground(opts.Context)
 	cwd := strings.TrimSpace(opts.CWD)
 	if cwd == "" {
 		wd, err := os.Getwd()
@@ -64,14 +87,21 @@ func Run(opts Options) (result Result, err error) {
 		return Result{}, err
 	}
 	overrideConfig(cfg, opts)
+	bestMode, err := parseBestMode(cfg.Output.Best)
+	if err != nil {
+		return Result{}, err
+	}
 
-	providerCfg, ok := cfg.Providers[cfg.Provider]
-	if !ok {
-		return Result{}, fmt.Errorf("配置中不存在 provider：%s", cfg.Provider)
+	providers, err := resolveProviders(cfg)
+	if err != nil {
+		return Result{}, err
 	}
-	translateProviderCfg, ok := cfg.Providers["deepseek"]
-	if !ok {
-		return Result{}, fmt.Errorf("配置中不存在 provider：deepseek（中文翻译固定使用 providers.deepseek）")
+	// 试跑仍按配置的 provider 构造提示词，只在发请求时换成 mock。
+	mockMode := providers.Mock || opts.DryRun
+	providerCfg := providers.ProviderCfg
+	translateProvider, translateProviderCfg := providers.TranslateProvider, providers.TranslateProviderCfg
+	if opts.DryRun {
+		translateProvider, translateProviderCfg = "mock", providerCfg
 	}
 
 	logger, closer, err := logging.New(opts.Stdout, opts.LogFile, opts.Verbose, cfg.Output.Num > 1)
@@ -84,29 +114,49 @@ func Run(opts Options) (result Result, err error) {
 	logger.Emit(logging.Event{Event: "startup", Provider: cfg.Provider, Model: providerCfg.Model})
 	logger.Emit(logging.Event{Event: "config_loaded", Input: paths.ConfigSource})
 
-	syncRes, syncErr := config.SyncRulesFromCenter(cfg, paths)
-	if syncErr != nil {
-		return Result{}, syncErr
-	}
-	if strings.TrimSpace(syncRes.Warning) != "" {
-		logger.Emit(logging.Event{Level: "warn", Event: "rules_sync_warning", Error: syncRes.Warning})
-	}
-	if syncRes.Updated && strings.TrimSpace(syncRes.Message) != "" {
-		logger.Emit(logging.Event{Event: "rules_sync_updated", Error: syncRes.Message})
-	}
-
-	rules, err := config.ReadSectionRules(paths.ResolvedRulesDir)
+	transport, finishCassette, err := openCassette(opts, cwd, logger)
 	if err != nil {
 		return Result{}, err
 	}
+	defer finishCassette()
 
-	envMap, apiKey, err := ensureDeepSeekAPIKey(paths, cfg.APIKeyEnv)
+	var rules config.SectionRules
+	if opts.DryRun {
+		rules, err = config.ReadSectionRules(paths.ResolvedRulesDir)
+	} else {
+		rules, err = syncAndReadRules(cfg, paths, logger, transport)
+	}
 	if err != nil {
 		return Result{}, err
 	}
+	var flagBase *ListingDocument
+	if strings.TrimSpace(opts.Base) != "" {
+		if flagBase, err = loadBaseListing(absPath(cwd, opts.Base), rules); err != nil {
+			return Result{}, err
+		}
+	}
+
+	var (
+		envMap map[string]string
+		apiKey string
+	)
+	if !mockMode {
+		envMap, apiKey, err = ensureDeepSeekAPIKey(paths, cfg.APIKeyEnv)
+		if err != nil {
+			return Result{}, err
+		}
+	}
 	balanceAPIKey := resolveDeepSeekBalanceKey(envMap, apiKey)
 	defer func() {
-		balance, fetchErr := fetchDeepSeekBalanceWithRetry(balanceAPIKey, cfg.MaxRetries)
+		if mockMode {
+			result.Balance = "未查询（mock）"
+			return
+		}
+		if ctx.Err() != nil {
+			result.Balance = "未查询（已取消）"
+			return
+		}
+		balance, fetchErr := fetchDeepSeekBalanceWithRetry(balanceAPIKey, cfg.MaxRetries, transport)
 		if fetchErr != nil {
 			result.Balance = "查询失败"
 			logger.Emit(logging.Event{Level: "warn", Event: "balance_failed", Error: fetchErr.Error()})
@@ -129,6 +179,8 @@ func Run(opts Options) (result Result, err error) {
 	}
 
 	validReqs := make([]listing.Requirement, 0, len(discoverRes.Files))
+	bases := map[string]*ListingDocument{}
+	params := map[string]requirementParams{}
 	for _, file := range discoverRes.Files {
 		req, parseErr := listing.ParseFile(file)
 		if parseErr != nil {
@@ -146,9 +198,27 @@ func Run(opts Options) (result Result, err error) {
 			logger.Emit(logging.Event{Level: "error", Event: "validation_failed", Input: file, Error: "分类缺失"})
 			continue
 		}
+		reqParams, paramsErr := resolveRequirementParams(req, rules, cfg, providerCfg)
+		if paramsErr != nil {
+			result.Failed++
+			logger.Emit(logging.Event{Level: "error", Event: "validation_failed", Input: file, Error: paramsErr.Error()})
+			continue
+		}
+		base, baseErr := resolveEditBase(flagBase, req, reqParams.Rules)
+		if baseErr != nil {
+			result.Failed++
+			logger.Emit(logging.Event{Level: "error", Event: "validation_failed", Input: file, Error: baseErr.Error()})
+			continue
+		}
 		for _, w := range req.Warnings {
 			logger.Emit(logging.Event{Level: "warn", Event: "validation_warning", Input: file, Error: w})
 		}
+		params[file] = reqParams
+		reqParams.emit(logger, file)
+		if base != nil {
+			bases[file] = base
+			logger.Emit(logging.Event{Event: "edit_mode", Input: file, Attempt: len(base.Order)})
+		}
 		validReqs = append(validReqs, req)
 	}
 
@@ -160,45 +230,96 @@ func Run(opts Options) (result Result, err error) {
 		return result, fmt.Errorf("没有可生成的需求文件")
 	}
 
-	outDir := cfg.Output.Dir
-	if strings.TrimSpace(outDir) == "" {
-		outDir = "."
+	outDir, err := prepareOutDir(cfg, cwd)
+	if err != nil {
+		return result, err
 	}
-	if !filepath.IsAbs(outDir) {
-		outDir = filepath.Join(cwd, outDir)
+	client, translateClient, err := newRunClients(cfg, paths, logger, transport)
+	if err != nil {
+		return result, err
 	}
-	if err := output.EnsureDir(outDir); err != nil {
-		return result, fmt.Errorf("创建输出目录失败：%w", err)
+	if opts.DryRun {
+		client.UseMock()
+	}
+	var memory *tm.Memory
+	if !opts.DryRun {
+		memory = openTranslationMemory(cfg, paths, translateProvider, logger)
+		translateClient.SetMemory(memory)
 	}
 
-	results := make(chan bool, len(validReqs)*cfg.Output.Num)
-	client := llm.NewClient(time.Duration(cfg.RequestTimeoutSec) * time.Second)
-	translateClient := translator.NewClient(time.Duration(cfg.RequestTimeoutSec) * time.Second)
+	style := newStylePolicy(cfg.Style)
+	lib := openLibrary(cfg, paths, logger)
+	var (
+		ranking   *rankingCollector
+		diversity *candidateDiversity
+	)
+	jobs, maxNum := 0, 0
+	for _, req := range validReqs {
+		jobs += params[req.SourcePath].Num
+		maxNum = max(maxNum, params[req.SourcePath].Num)
+	}
+	if maxNum > 1 {
+		logger.ShowCandidates()
+		diversity = newCandidateDiversity(cfg.Diversity)
+		if !opts.DryRun {
+			ranking = newRankingCollector()
+		}
+	}
+	// 评审按各需求自己的生效规则进行，与生成、评分口径一致。
+	reviews := make(map[string]*reviewSettings, len(validReqs))
+	for _, req := range validReqs {
+		reviews[req.SourcePath] = newReviewSettings(cfg.Review, params[req.SourcePath].Rules, logger, req.SourcePath)
+	}
+	translationQA := newTranslationQASettings(cfg.TranslationQA)
+	results := make(chan candidateOutcome, jobs)
+
+	stopCancelNotice := context.AfterFunc(ctx, func() {
+		logger.Emit(logging.Event{Level: "warn", Event: "run_cancelled", Error: "收到中断信号，停止调度新任务并取消进行中的请求"})
+	})
+	defer stopCancelNotice()
 
 	var wg sync.WaitGroup
 
 	go func() {
 		for _, req := range validReqs {
-			for i := 1; i <= cfg.Output.Num; i++ {
-				job := candidateJob{Req: req, Candidate: i}
+			p := params[req.SourcePath]
+			for i := 1; i <= p.Num; i++ {
+				job := candidateJob{Req: req, Candidate: i, Base: bases[req.SourcePath]}
+				if ctx.Err() != nil {
+					results <- candidateCancelled
+					continue
+				}
 				wg.Add(1)
 				go func(j candidateJob) {
 					defer wg.Done()
-					ok := processCandidate(processCandidateOptions{
+					outcome := processCandidate(processCandidateOptions{
+						Context:              ctx,
 						Job:                  j,
 						OutDir:               outDir,
-						CharTolerance:        cfg.CharTolerance,
+						CharTolerance:        p.CharTolerance,
 						Provider:             cfg.Provider,
-						ProviderCfg:          providerCfg,
+						ProviderCfg:          p.ProviderCfg,
+						TranslateProvider:    translateProvider,
 						TranslateProviderCfg: translateProviderCfg,
 						APIKey:               apiKey,
-						Rules:                rules,
+						Rules:                p.Rules,
 						MaxRetries:           cfg.MaxRetries,
 						Client:               client,
 						TranslateClient:      translateClient,
 						Logger:               logger,
+						DryRun:               opts.DryRun,
+						Style:                style,
+						FactCheck:            cfg.FactCheckEnabled(),
+						Protect:              cfg.Protect,
+						ReferenceTitle:       cfg.ReferenceTitle,
+						Library:              lib,
+						Ranking:              ranking,
+						Diversity:            diversity,
+						Review:               reviews[req.SourcePath],
+						TranslationQA:        translationQA,
+						Memory:               memory,
 					})
-					results <- ok
+					results <- outcome
 				}(job)
 			}
 		}
@@ -206,24 +327,189 @@ func Run(opts Options) (result Result, err error) {
 		close(results)
 	}()
 
-	for ok := range results {
-		if ok {
+	for outcome := range results {
+		switch outcome {
+		case candidateOK:
 			result.Succeeded++
-		} else {
+		case candidateCancelled:
+			result.Cancelled++
+		default:
 			result.Failed++
 		}
 	}
+	if ctx.Err() == nil {
+		// 评分与组合按各需求自己的生效规则进行，因此逐个需求写出。
+		for _, outs := range ranking.groups() {
+			p := params[outs[0].Req.SourcePath]
+			group := [][]candidateOutput{outs}
+			writeRankings(rankingWriteOptions{
+				Context:   ctx,
+				OutDir:    outDir,
+				Rules:     p.Rules,
+				Style:     style,
+				Tolerance: p.CharTolerance,
+				BestMode:  bestMode,
+				Logger:    logger,
+			}, group)
+			if cfg.Output.ComposeEnabled() {
+				writeComposed(composeOptions{
+					Context:              ctx,
+					OutDir:               outDir,
+					CharTolerance:        p.CharTolerance,
+					Provider:             cfg.Provider,
+					ProviderCfg:          p.ProviderCfg,
+					TranslateProvider:    translateProvider,
+					TranslateProviderCfg: translateProviderCfg,
+					APIKey:               apiKey,
+					Rules:                p.Rules,
+					MaxRetries:           cfg.MaxRetries,
+					Client:               client,
+					TranslateClient:      translateClient,
+					Logger:               logger,
+					Style:                style,
+					FactCheck:            cfg.FactCheckEnabled(),
+					Protect:              cfg.Protect,
+				}, group)
+			}
+		}
+	}
+	saveTranslationMemory(memory, logger)
 	result.ElapsedMS = time.Since(runStartedAt).Milliseconds()
-	logger.Emit(logging.Event{Event: "finished", Attempt: result.Succeeded + result.Failed, Error: fmt.Sprintf("success=%d failed=%d", result.Succeeded, result.Failed)})
+	logger.Emit(logging.Event{Event: "finished", Attempt: result.Succeeded + result.Failed, Error: fmt.Sprintf("success=%d failed=%d cancelled=%d", result.Succeeded, result.Failed, result.Cancelled)})
 	return result, nil
 }
 
+type providerSetup struct {
+	Mock                 bool
+	ProviderCfg          config.ProviderConfig
+	TranslateProvider    string
+	TranslateProviderCfg config.ProviderConfig
+}
+
+// resolveProviders 解析生成与翻译使用的 provider；中文翻译固定走 deepseek，mock 时两者都走 mock。
+func resolveProviders(cfg *config.Config) (providerSetup, error) {
+	out := providerSetup{Mock: isMockProvider(cfg.Provider)}
+	providerCfg, ok := cfg.Providers[cfg.Provider]
+	if !ok && out.Mock {
+		providerCfg, ok = config.ProviderConfig{Model: "mock"}, true
+	}
+	if !ok {
+		return providerSetup{}, fmt.Errorf("配置中不存在 provider：%s", cfg.Provider)
+	}
+	out.ProviderCfg = providerCfg
+	out.TranslateProvider = "deepseek"
+	out.TranslateProviderCfg, ok = cfg.Providers["deepseek"]
+	if out.Mock {
+		out.TranslateProvider, out.TranslateProviderCfg, ok = "mock", providerCfg, true
+	}
+	if !ok {
+		return providerSetup{}, fmt.Errorf("配置中不存在 provider：deepseek（中文翻译固定使用 providers.deepseek）")
+	}
+	return out, nil
+}
+
+// syncAndReadRules 同步规则中心后读取本地分段规则；transport 为 nil 时使用默认 Transport。
+func syncAndReadRules(cfg *config.Config, paths *config.Paths, logger *logging.Logger, transport http.RoundTripper) (config.SectionRules, error) {
+	syncRes, syncErr := config.SyncRulesFromCenterVia(cfg, paths, transport)
+	if syncErr != nil {
+		return config.SectionRules{}, syncErr
+	}
+	if strings.TrimSpace(syncRes.Warning) != "" {
+		logger.Emit(logging.Event{Level: "warn", Event: "rules_sync_warning", Error: syncRes.Warning})
+	}
+	if syncRes.Updated && strings.TrimSpace(syncRes.Message) != "" {
+		logger.Emit(logging.Event{Event: "rules_sync_updated", Error: syncRes.Message})
+	}
+	return config.ReadSectionRules(paths.ResolvedRulesDir)
+}
+
+// prepareOutDir 返回绝对输出目录并确保其存在。
+func prepareOutDir(cfg *config.Config, cwd string) (string, error) {
+	outDir := cfg.Output.Dir
+	if strings.TrimSpace(outDir) == "" {
+		outDir = "."
+	}
+	if !filepath.IsAbs(outDir) {
+		outDir = filepath.Join(cwd, outDir)
+	}
+	if err := output.EnsureDir(outDir); err != nil {
+		return "", fmt.Errorf("创建输出目录失败：%w", err)
+	}
+	return outDir, nil
+}
+
+// newRunClients 按配置的超时与缓存模式创建生成与翻译客户端；transport 非 nil 时两者都经它发请求。
+func newRunClients(cfg *config.Config, paths *config.Paths, logger *logging.Logger, transport http.RoundTripper) (*llm.Client, *translator.Client, error) {
+	cacheMode, err := cache.ParseMode(cfg.Cache.Mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	responseCache, err := cache.New(paths.CacheDir, cacheMode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if responseCache != nil {
+		logger.Emit(logging.Event{Event: "cache_enabled", OutputFile: responseCache.Dir(), Cache: string(cacheMode)})
+	}
+	client := llm.NewClient(time.Duration(cfg.RequestTimeoutSec) * time.Second)
+	client.SetCache(responseCache)
+	client.SetTransport(transport)
+	translateClient := translator.NewClient(time.Duration(cfg.RequestTimeoutSec) * time.Second)
+	translateClient.SetCache(responseCache)
+	translateClient.SetTransport(transport)
+	return client, translateClient, nil
+}
+
+// openCassette 按 --record / --replay 返回本次运行使用的 HTTP Transport（都未指定时为 nil，即默认 Transport），
+// 由调用方传给生成、翻译、余额查询与规则中心；返回的函数负责保存录像或报告未用完的条目。
+// 不替换全局 http.DefaultTransport，同进程内的其他运行不受影响。
+func openCassette(opts Options, cwd string, logger *logging.Logger) (http.RoundTripper, func(), error) {
+	recordPath := strings.TrimSpace(opts.Record)
+	replayPath := strings.TrimSpace(opts.Replay)
+	switch {
+	case recordPath != "" && replayPath != "":
+		return nil, nil, fmt.Errorf("--record 与 --replay 不能同时使用")
+	case replayPath != "":
+		replayPath = absPath(cwd, replayPath)
+		mode, err := cassette.ParseMatchMode(opts.ReplayMatch)
+		if err != nil {
+			return nil, nil, err
+		}
+		tape, err := cassette.Load(replayPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		replayer := cassette.NewReplayer(tape, mode)
+		logger.Emit(logging.Event{Event: "cassette_replay", Input: replayPath, Attempt: len(tape.Interactions)})
+		return replayer, func() {
+			if left := replayer.Remaining(); left > 0 {
+				logger.Emit(logging.Event{Level: "warn", Event: "cassette_unused", Input: replayPath, Attempt: left})
+			}
+		}, nil
+	case recordPath != "":
+		recordPath = absPath(cwd, recordPath)
+		recorder := cassette.NewRecorder(http.DefaultTransport)
+		logger.Emit(logging.Event{Event: "cassette_record", OutputFile: recordPath})
+		return recorder, func() {
+			if err := recorder.Save(recordPath); err != nil {
+				logger.Emit(logging.Event{Level: "warn", Event: "cassette_save_failed", OutputFile: recordPath, Error: err.Error()})
+				return
+			}
+			logger.Emit(logging.Event{Event: "cassette_saved", OutputFile: recordPath, Attempt: len(recorder.Cassette().Interactions)})
+		}, nil
+	default:
+		return nil, func() {}, nil
+	}
+}
+
 type processCandidateOptions struct {
+	Context              context.Context
 	Job                  candidateJob
 	OutDir               string
 	CharTolerance        int
 	Provider             string
 	ProviderCfg          config.ProviderConfig
+	TranslateProvider    string
 	TranslateProviderCfg config.ProviderConfig
 	APIKey               string
 	Rules                config.SectionRules
@@ -231,21 +517,51 @@ type processCandidateOptions struct {
 	Client               *llm.Client
 	TranslateClient      *translator.Client
 	Logger               *logging.Logger
+	DryRun               bool
+	Style                *stylePolicy
+	FactCheck            bool
+	Protect              config.ProtectConfig
+	ReferenceTitle       config.ReferenceTitleConfig
+	Library              *library.Library
+	// Ranking 非 nil 时记录写盘成功的候选，供评分排序。
+	Ranking   *rankingCollector
+	Diversity *candidateDiversity
+	// Review 非 nil 时生成后按量表评审，并写入 listing_<id>_review.json。
+	Review *reviewSettings
+	// TranslationQA 非 nil 时回译质检中文译文，并写入 listing_<id>_qa.json。
+	TranslationQA *translationQASettings
+	// Memory 非 nil 时把写盘成功的英中对照记入翻译记忆。
+	Memory *tm.Memory
 }
 
-func processCandidate(opts processCandidateOptions) bool {
+// candidateOutcome 是单个候选的结局；取消单独计数，不与失败混在一起。
+type candidateOutcome int
+
+const (
+	candidateFailed candidateOutcome = iota
+	candidateOK
+	candidateCancelled
+)
+
+func processCandidate(opts processCandidateOptions) candidateOutcome {
 	id, enPath, cnPath, err := output.NextPair(opts.OutDir, 8, nil)
 	if err != nil {
 		opts.Logger.Emit(logging.Event{Level: "error", Event: "name_failed", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Error: err.Error()})
-		return false
+		return candidateFailed
 	}
 	_ = id
+	var prompts *promptDump
+	if opts.DryRun {
+		prompts = newPromptDump()
+	}
 
-	enDoc, cnDoc, enLatency, cnLatency, err := generateENAndTranslateCNBySections(bilingualGenerateOptions{
+	genOpts := bilingualGenerateOptions{
+		Context:              opts.Context,
 		Req:                  opts.Job.Req,
 		CharTolerance:        opts.CharTolerance,
 		Provider:             opts.Provider,
 		ProviderCfg:          opts.ProviderCfg,
+		TranslateProvider:    opts.TranslateProvider,
 		TranslateProviderCfg: opts.TranslateProviderCfg,
 		APIKey:               opts.APIKey,
 		Rules:                opts.Rules,
@@ -254,27 +570,80 @@ func processCandidate(opts processCandidateOptions) bool {
 		TranslateClient:      opts.TranslateClient,
 		Logger:               opts.Logger,
 		Candidate:            opts.Job.Candidate,
-	})
+		Prompts:              prompts,
+		Style:                opts.Style,
+		FactCheck:            opts.FactCheck,
+		Diversity:            opts.Diversity,
+		Protect:              opts.Protect,
+		ReferenceTitle:       opts.ReferenceTitle,
+		Library:              opts.Library,
+		Edit:                 newEditSession(opts.Job.Base),
+	}
+	enDoc, cnDoc, enLatency, cnLatency, err := generateENAndTranslateCNBySections(genOpts)
+	if opts.DryRun {
+		promptPath := promptDumpPath(enPath)
+		if writeErr := output.WriteFilesAtomic(contextOrBackground(opts.Context), output.File{Path: promptPath, Data: []byte(prompts.render(opts.Job.Req.SourcePath))}); writeErr != nil {
+			if errors.Is(writeErr, context.Canceled) || errors.Is(writeErr, context.DeadlineExceeded) {
+				opts.Logger.Emit(logging.Event{Level: "warn", Event: "generate_cancelled", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Error: writeErr.Error()})
+				return candidateCancelled
+			}
+			opts.Logger.Emit(logging.Event{Level: "error", Event: "write_failed", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, OutputFile: promptPath, Error: writeErr.Error()})
+			return candidateFailed
+		}
+		opts.Logger.Emit(logging.Event{Event: "dry_run_prompts", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, OutputFile: promptPath})
+	}
 	if err != nil {
+		if ctxErr := contextOrBackground(opts.Context).Err(); ctxErr != nil {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "generate_cancelled", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Error: ctxErr.Error()})
+			return candidateCancelled
+		}
 		opts.Logger.Emit(logging.Event{Level: "error", Event: "generate_failed", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Error: err.Error()})
-		return false
+		return candidateFailed
 	}
 	opts.Logger.Emit(logging.Event{Event: "generate_ok", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Lang: "en", LatencyMS: enLatency})
 	opts.Logger.Emit(logging.Event{Event: "generate_ok", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Lang: "cn", LatencyMS: cnLatency})
+	if opts.DryRun {
+		return candidateOK
+	}
 
-	enMD := RenderMarkdown("en", opts.Job.Req, enDoc)
-	if err := os.WriteFile(enPath, []byte(enMD), 0o644); err != nil {
-		opts.Logger.Emit(logging.Event{Level: "error", Event: "write_failed", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Lang: "en", OutputFile: enPath, Error: err.Error()})
-		return false
+	enDoc, cnDoc, review := reviewAndRepair(genOpts, opts.Review, enDoc, cnDoc)
+	cnDoc, qa := checkBackTranslation(genOpts, opts.TranslationQA, enDoc, cnDoc)
+	files := []output.File{
+		{Path: enPath, Data: []byte(RenderMarkdown("en", opts.Job.Req, enDoc))},
+		{Path: cnPath, Data: []byte(RenderMarkdown("cn", opts.Job.Req, cnDoc))},
+	}
+	if review != nil {
+		raw, _ := json.MarshalIndent(review, "", "  ")
+		files = append(files, output.File{Path: reviewSidecarPath(enPath), Data: append(raw, '\n')})
+	}
+	if qa != nil {
+		raw, _ := json.MarshalIndent(qa, "", "  ")
+		files = append(files, output.File{Path: translationQASidecarPath(enPath), Data: append(raw, '\n')})
+	}
+	if strings.TrimSpace(opts.Job.Req.ReferenceTitle) != "" {
+		files = append(files, output.File{Path: titleCompareSidecarPath(enPath), Data: []byte(renderTitleComparison(opts.Job.Req, opts.Rules, opts.Style, opts.CharTolerance, enDoc.Title))})
 	}
-	cnMD := RenderMarkdown("cn", opts.Job.Req, cnDoc)
-	if err := os.WriteFile(cnPath, []byte(cnMD), 0o644); err != nil {
-		opts.Logger.Emit(logging.Event{Level: "error", Event: "write_failed", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Lang: "cn", OutputFile: cnPath, Error: err.Error()})
-		return false
+	if genOpts.Edit != nil {
+		files = append(files, output.File{Path: editDiffSidecarPath(enPath), Data: []byte(renderEditDiff(opts.Job.Req, buildEditDiffs(opts.Rules, genOpts.Edit, enDoc)))})
+	}
+	if err := output.WriteFilesAtomic(contextOrBackground(opts.Context), files...); err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "generate_cancelled", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Error: err.Error()})
+			return candidateCancelled
+		}
+		opts.Logger.Emit(logging.Event{Level: "error", Event: "write_failed", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, OutputFile: enPath, Error: err.Error()})
+		return candidateFailed
 	}
 	opts.Logger.Emit(logging.Event{Event: "write_ok", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Lang: "en", OutputFile: enPath})
 	opts.Logger.Emit(logging.Event{Event: "write_ok", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, Lang: "cn", OutputFile: cnPath})
-	return true
+	if strings.TrimSpace(opts.Job.Req.ReferenceTitle) != "" {
+		opts.Logger.Emit(logging.Event{Event: "title_compare", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, OutputFile: titleCompareSidecarPath(enPath)})
+	}
+	if genOpts.Edit != nil {
+		opts.Logger.Emit(logging.Event{Event: "edit_diff", Input: opts.Job.Req.SourcePath, Candidate: opts.Job.Candidate, OutputFile: editDiffSidecarPath(enPath)})
+	}
+	opts.Ranking.record(candidateOutput{Req: opts.Job.Req, Candidate: opts.Job.Candidate, ENPath: enPath, CNPath: cnPath, EN: enDoc, CN: cnDoc, Review: review})
+	return candidateOK
 }
 
 func overrideConfig(cfg *config.Config, opts Options) {
@@ -293,6 +662,17 @@ func overrideConfig(cfg *config.Config, opts Options) {
 	if strings.TrimSpace(opts.Provider) != "" {
 		cfg.Provider = opts.Provider
 	}
+	if strings.TrimSpace(opts.Cache) != "" {
+		cfg.Cache.Mode = opts.Cache
+	}
+	if strings.TrimSpace(opts.Best) != "" {
+		cfg.Output.Best = opts.Best
+	}
+}
+
+// isMockProvider 判断是否为内置离线 provider：不需要 API KEY，也不查询余额。
+func isMockProvider(provider string) bool {
+	return strings.EqualFold(strings.TrimSpace(provider), "mock")
 }
 
 func absPath(cwd, p string) string {
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/translator"
+)
+
+func TestRunCancelledBeforeSchedulingCountsCancelled(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "DEEPSEEK_API_KEY=test\n")
+	reqPath := filepath.Join(workDir, "ok.md")
+	raw := strings.Join([]string{
+		"===Listing Requirements===",
+		"品牌名: BrandX",
+		"分类: Cat",
+		"# 关键词库",
+		"- alpha",
+		"- beta",
+	}, "\n")
+	if err := os.WriteFile(reqPath, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := Run(Options{
+		Context:    ctx,
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Num:        2,
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err != nil {
+		t.Fatalf("cancelled run error: %v", err)
+	}
+	if res.Cancelled != 2 || res.Succeeded != 0 || res.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res.Balance != "未查询（已取消）" {
+		t.Fatalf("unexpected balance: %q", res.Balance)
+	}
+	if files, _ := filepath.Glob(filepath.Join(workDir, "listing_*")); len(files) != 0 {
+		t.Fatalf("cancelled run should not write files: %v", files)
+	}
+}
+
+func TestProcessCandidateCancelInFlightLeavesNoFiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	logger, _, err := logging.New(io.Discard, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	start := time.Now()
+	outcome := processCandidate(processCandidateOptions{
+		Context: ctx,
+		Job: candidateJob{
+			Req:       listing.Requirement{SourcePath: "/tmp/a.md", BodyAfterMarker: "body", Brand: "BrandX", Category: "Cat", Keywords: []string{"alpha", "beta"}},
+			Candidate: 1,
+		},
+		OutDir:               work,
+		CharTolerance:        20,
+		Provider:             "deepseek",
+		ProviderCfg:          config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		TranslateProviderCfg: config.ProviderConfig{BaseURL: ts.URL, Model: "deepseek-chat"},
+		APIKey:               "k",
+		Rules:                testRules(),
+		MaxRetries:           3,
+		Client:               llm.NewClient(30 * time.Second),
+		TranslateClient:      translator.NewClient(30 * time.Second),
+		Logger:               logger,
+	})
+	if outcome != candidateCancelled {
+		t.Fatalf("expected cancelled candidate, got %v", outcome)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Fatalf("in-flight request was not cancelled promptly")
+	}
+	entries, _ := os.ReadDir(work)
+	if len(entries) != 0 {
+		t.Fatalf("expected no output files, got %v", entries)
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRecordThenReplayWithoutNetwork(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "DEEPSEEK_API_KEY=test\n")
+	reqPath := filepath.Join(workDir, "ok.md")
+	raw := strings.Join([]string{
+		"===Listing Requirements===",
+		"品牌名: BrandX",
+		"分类: Cat",
+		"# 关键词库",
+		"- alpha",
+		"- beta",
+	}, "\n")
+	if err := os.WriteFile(reqPath, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tape := filepath.Join(workDir, "tape.json")
+	recorded, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Record:     "tape.json",
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err != nil {
+		t.Fatalf("record run error: %v", err)
+	}
+	data, err := os.ReadFile(tape)
+	if err != nil {
+		t.Fatalf("cassette not written: %v", err)
+	}
+	for _, want := range []string{"/chat/completions", "/user/balance", "/releases/latest"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("cassette missing %s", want)
+		}
+	}
+
+	offline := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("network disabled: %s", req.URL)
+	})
+	t.Cleanup(func() { http.DefaultTransport = offline })
+
+	replayed, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Replay:     tape,
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err != nil {
+		t.Fatalf("replay run error: %v", err)
+	}
+	if replayed.Succeeded != recorded.Succeeded || replayed.Failed != recorded.Failed || replayed.Balance != recorded.Balance {
+		t.Fatalf("replay diverged: recorded=%+v replayed=%+v", recorded, replayed)
+	}
+	if replayed.Balance != "9.99 元" {
+		t.Fatalf("expected balance from cassette, got %q", replayed.Balance)
+	}
+}
+
+func TestRunRecordAndReplayConflict(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "DEEPSEEK_API_KEY=test\n")
+	_, err := Run(Options{
+		Inputs:     []string{"a.md"},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Record:     "a.json",
+		Replay:     "b.json",
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "不能同时使用") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
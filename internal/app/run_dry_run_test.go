@@ -0,0 +1,128 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeDryRunRequirement(t *testing.T, workDir string) string {
+	t.Helper()
+	reqPath := filepath.Join(workDir, "ok.md")
+	raw := strings.Join([]string{
+		"===Listing Requirements===",
+		"品牌名: BrandX",
+		"分类: Cat",
+		"# 关键词库",
+		"- alpha",
+		"- beta",
+	}, "\n")
+	if err := os.WriteFile(reqPath, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return reqPath
+}
+
+func TestRunMockProviderWithoutAPIKey(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	res, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Provider:   "mock",
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err != nil {
+		t.Fatalf("mock run error: %v", err)
+	}
+	if res.Succeeded != 1 || res.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res.Balance != "未查询（mock）" {
+		t.Fatalf("unexpected balance: %q", res.Balance)
+	}
+	cnFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_cn.md"))
+	if len(cnFiles) != 1 {
+		t.Fatalf("expected cn output, got %v", cnFiles)
+	}
+	cnRaw, _ := os.ReadFile(cnFiles[0])
+	if !strings.Contains(string(cnRaw), "【模拟译文】") {
+		t.Fatalf("expected mock translation in cn file: %s", cnRaw)
+	}
+}
+
+func TestRunDryRunDumpsPrompts(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	reqPath := writeDryRunRequirement(t, workDir)
+	var logs bytes.Buffer
+	res, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		DryRun:     true,
+		Stdout:     &logs,
+		Stderr:     ioDiscard{},
+	})
+	if err != nil {
+		t.Fatalf("dry-run error: %v", err)
+	}
+	if res.Succeeded != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if strings.Contains(logs.String(), "规则中心") {
+		t.Fatalf("dry-run should not sync the rules center:\n%s", logs.String())
+	}
+	if listings, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md")); len(listings) != 0 {
+		t.Fatalf("dry-run should not write listings: %v", listings)
+	}
+	dumps, _ := filepath.Glob(filepath.Join(workDir, "listing_*_prompts.md"))
+	if len(dumps) != 1 {
+		t.Fatalf("expected one prompt dump, got %v", dumps)
+	}
+	raw, _ := os.ReadFile(dumps[0])
+	text := string(raw)
+	for _, want := range []string{"## title（第1次）", "## bullets（第1次）", "## description（第1次）", "## search_terms（第1次）", "### system", "section: title", "【当前任务】生成：title", "【JSON协议】"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("prompt dump missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestRunDryRunKeepsConfiguredProviderPrompts(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	raw, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw = append(raw, []byte("  openai:\n    base_url: http://127.0.0.1:1\n    api_mode: chat\n    model: gpt-test\n")...)
+	if err := os.WriteFile(cfgPath, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reqPath := writeDryRunRequirement(t, workDir)
+	var logs bytes.Buffer
+	res, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Provider:   "openai",
+		DryRun:     true,
+		Stdout:     &logs,
+		Stderr:     ioDiscard{},
+	})
+	// 试跑与真实运行一样按 openai 构造提示词，因而同样暴露 json_lines 规则不受支持。
+	if err != nil || res.Failed != 1 || !strings.Contains(logs.String(), "provider openai 不支持 json_lines 协议") {
+		t.Fatalf("unexpected dry-run: res=%+v err=%v\n%s", res, err, logs.String())
+	}
+	dumps, _ := filepath.Glob(filepath.Join(workDir, "listing_*_prompts.md"))
+	if len(dumps) != 1 {
+		t.Fatalf("expected one prompt dump, got %v", dumps)
+	}
+	dump, _ := os.ReadFile(dumps[0])
+	if strings.Contains(string(dump), "【JSON协议】") {
+		t.Fatalf("openai prompts should not use the deepseek JSON protocol:\n%s", dump)
+	}
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupManifestRunFixture(t *testing.T) (string, string) {
+	t.Helper()
+	cfgPath, workDir := setupRunFixture(t, "")
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rulesDir := filepath.Join(cacheDir, "syl-listing", "rules")
+	files := map[string]string{
+		"manifest.yaml": `version: 1
+sections:
+  - name: title
+  - name: product_name
+    output: line
+    depends_on: [title]
+    heading: {en: Product Name, cn: 产品名}
+  - name: bullets
+  - name: description
+  - name: search_terms
+  - name: intended_use
+    depends_on: [title, product_name]
+    heading: {en: Intended Use, cn: 适用场景}
+`,
+		"product_name.yaml": `version: 1
+section: product_name
+language: en
+output:
+  format: plain_text
+  lines: 1
+constraints:
+  max_chars:
+    value: 40
+execution:
+  generation:
+    protocol: text
+  repair:
+    granularity: whole
+  fallback:
+    disable_thinking_on_length_error: true
+instruction: |
+  only output a short product name
+`,
+		"intended_use.yaml": `version: 1
+section: intended_use
+language: en
+output:
+  format: plain_text
+  lines: 3
+constraints:
+  max_chars_per_line:
+    value: 60
+execution:
+  generation:
+    protocol: text
+  repair:
+    granularity: whole
+  fallback:
+    disable_thinking_on_length_error: true
+instruction: |
+  output three intended uses, one per line
+`,
+	}
+	for name, raw := range files {
+		if err := os.WriteFile(filepath.Join(rulesDir, name), []byte(raw), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cfgPath, workDir
+}
+
+func TestRunMockWithManifestExtraSections(t *testing.T) {
+	cfgPath, workDir := setupManifestRunFixture(t)
+	reqPath := writeDryRunRequirement(t, workDir)
+	res, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		Provider:   "mock",
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	})
+	if err != nil || res.Succeeded != 1 {
+		t.Fatalf("mock run failed: res=%+v err=%v", res, err)
+	}
+	enFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_en.md"))
+	cnFiles, _ := filepath.Glob(filepath.Join(workDir, "listing_*_cn.md"))
+	if len(enFiles) != 1 || len(cnFiles) != 1 {
+		t.Fatalf("expected en/cn outputs, got %v %v", enFiles, cnFiles)
+	}
+	enRaw, _ := os.ReadFile(enFiles[0])
+	en := string(enRaw)
+	titleAt := strings.Index(en, "## Title")
+	nameAt := strings.Index(en, "## Product Name")
+	bulletsAt := strings.Index(en, "## Bullet Points")
+	useAt := strings.Index(en, "## Intended Use")
+	if titleAt < 0 || nameAt < titleAt || bulletsAt < nameAt || useAt < bulletsAt {
+		t.Fatalf("sections not rendered in manifest order:\n%s", en)
+	}
+	cnRaw, _ := os.ReadFile(cnFiles[0])
+	if !strings.Contains(string(cnRaw), "## 产品名\n【模拟译文】") || !strings.Contains(string(cnRaw), "## 适用场景\n") {
+		t.Fatalf("cn extra sections missing:\n%s", cnRaw)
+	}
+}
+
+func TestDryRunManifestPromptsFollowDependencies(t *testing.T) {
+	cfgPath, workDir := setupManifestRunFixture(t)
+	reqPath := writeDryRunRequirement(t, workDir)
+	if _, err := Run(Options{
+		Inputs:     []string{reqPath},
+		ConfigPath: cfgPath,
+		CWD:        workDir,
+		DryRun:     true,
+		Stdout:     ioDiscard{},
+		Stderr:     ioDiscard{},
+	}); err != nil {
+		t.Fatalf("dry-run error: %v", err)
+	}
+	dumps, _ := filepath.Glob(filepath.Join(workDir, "listing_*_prompts.md"))
+	if len(dumps) != 1 {
+		t.Fatalf("expected one prompt dump, got %v", dumps)
+	}
+	raw, _ := os.ReadFile(dumps[0])
+	text := string(raw)
+	useAt := strings.Index(text, "## intended_use（第1次）")
+	if useAt < 0 {
+		t.Fatalf("intended_use prompt missing:\n%s", text)
+	}
+	usePrompt := text[useAt:]
+	if !strings.Contains(usePrompt, "【已生成标题】") || !strings.Contains(usePrompt, "【已生成Product Name】") {
+		t.Fatalf("intended_use prompt should carry its dependencies:\n%s", usePrompt)
+	}
+	if strings.Contains(usePrompt, "【已生成五点】") || strings.Contains(usePrompt, "【已生成描述】") {
+		t.Fatalf("intended_use prompt should not carry non-dependencies:\n%s", usePrompt)
+	}
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+)
+
+// 总分权重：关键词覆盖、长度贴合、校验提示、禁用词、可读性，合计 1。
+const (
+	scoreWeightKeywords    = 0.35
+	scoreWeightLength      = 0.25
+	scoreWeightWarnings    = 0.15
+	scoreWeightForbidden   = 0.15
+	scoreWeightReadability = 0.10
+)
+
+// documentScore 是单个候选英文文档的评分，分项均为 0~1，Total 为 0~100。
+type documentScore struct {
+	Total           float64        `json:"total"`
+	KeywordCoverage float64        `json:"keyword_coverage"`
+	LengthFit       float64        `json:"length_fit"`
+	Warnings        int            `json:"warnings"`
+	ForbiddenHits   []string       `json:"forbidden_hits"`
+	Readability     float64        `json:"readability"`
+	MissingKeywords []string       `json:"missing_keywords"`
+	Sections        []sectionScore `json:"sections"`
+}
+
+// sectionScore 是单个分段的评分，用于跨候选挑选最佳分段。
+type sectionScore struct {
+	Name            string   `json:"name"`
+	Score           float64  `json:"score"`
+	KeywordCoverage float64  `json:"keyword_coverage"`
+	LengthFit       float64  `json:"length_fit"`
+	Warnings        int      `json:"warnings"`
+	ForbiddenHits   []string `json:"forbidden_hits"`
+	Readability     float64  `json:"readability"`
+}
+
+// scoreDocument 按规则为英文文档评分；所有分项都可由文档本地复算，不依赖生成过程中的日志。
+func scoreDocument(req listing.Requirement, rules config.SectionRules, doc ListingDocument, style *stylePolicy, tolerance int) documentScore {
+	out := documentScore{ForbiddenHits: []string{}, MissingKeywords: []string{}}
+	var allText strings.Builder
+	var lengthSum, readSum float64
+	readN := 0
+	for _, sec := range rules.Sections() {
+		rule, err := rules.Get(sec.Name)
+		if err != nil {
+			continue
+		}
+		items := doc.SectionItems(sec.Name)
+		s := scoreSection(req, sec.Name, rule, items, style, tolerance)
+		out.Sections = append(out.Sections, s)
+		out.Warnings += s.Warnings
+		out.ForbiddenHits = append(out.ForbiddenHits, s.ForbiddenHits...)
+		lengthSum += s.LengthFit
+		if isProseSection(sec) {
+			readSum += s.Readability
+			readN++
+		}
+		allText.WriteString(strings.Join(items, "\n"))
+		allText.WriteString("\n")
+	}
+	if n := len(out.Sections); n > 0 {
+		out.LengthFit = lengthSum / float64(n)
+	}
+	out.Readability = 1
+	if readN > 0 {
+		out.Readability = readSum / float64(readN)
+	}
+	out.KeywordCoverage, out.MissingKeywords = keywordCoverage(req.Keywords, allText.String())
+	out.Total = roundScore(100 * (scoreWeightKeywords*out.KeywordCoverage +
+		scoreWeightLength*out.LengthFit +
+		scoreWeightWarnings*warningScore(out.Warnings) +
+		scoreWeightForbidden*forbiddenScore(len(out.ForbiddenHits)) +
+		scoreWeightReadability*out.Readability))
+	out.KeywordCoverage = roundRatio(out.KeywordCoverage)
+	out.LengthFit = roundRatio(out.LengthFit)
+	out.Readability = roundRatio(out.Readability)
+	return out
+}
+
+func scoreSection(req listing.Requirement, name string, rule config.SectionRuleFile, items []string, style *stylePolicy, tolerance int) sectionScore {
+	text := joinSectionItems(rule, items)
+	s := sectionScore{Name: name, ForbiddenHits: forbiddenHits(rule, style, text), Readability: 1}
+	s.KeywordCoverage, _ = keywordCoverage(req.Keywords, text)
+	s.LengthFit = lengthFit(rule, items, text)
+	issues, warnings := validateSectionText(name, "en", req, text, rule, tolerance)
+	s.Warnings = len(issues) + len(warnings)
+	if rule.OutputKind() != config.OutputLine {
+		s.Readability = readingEase(text)
+	}
+	s.Score = roundScore(100 * (scoreWeightKeywords*s.KeywordCoverage +
+		scoreWeightLength*s.LengthFit +
+		scoreWeightWarnings*warningScore(s.Warnings) +
+		scoreWeightForbidden*forbiddenScore(len(s.ForbiddenHits)) +
+		scoreWeightReadability*s.Readability))
+	s.KeywordCoverage = roundRatio(s.KeywordCoverage)
+	s.LengthFit = roundRatio(s.LengthFit)
+	s.Readability = roundRatio(s.Readability)
+	return s
+}
+
+func isProseSection(sec config.ManifestSection) bool {
+	return sec.Output != config.OutputLine
+}
+
+// joinSectionItems 把条目还原成模型输出格式，便于复用 validateSectionText。
+func joinSectionItems(rule config.SectionRuleFile, items []string) string {
+	if rule.OutputKind() != config.OutputParagraphs {
+		return strings.Join(items, "\n")
+	}
+	sep := rule.Parsed.Output.ParagraphSeparator
+	switch strings.ToLower(strings.TrimSpace(strings.ReplaceAll(sep, `\n`, "\n"))) {
+	case "", "blank_line", "blank_lines", "empty_line":
+		return strings.Join(items, "\n\n")
+	case "newline", "line", "single_newline":
+		return strings.Join(items, "\n")
+	default:
+		return strings.Join(items, "\n"+sep+"\n")
+	}
+}
+
+// keywordCoverage 返回出现在文本中的关键词比例（不区分大小写）与缺失的关键词。
+func keywordCoverage(keywords []string, text string) (float64, []string) {
+	missing := []string{}
+	total := 0
+	lower := strings.ToLower(text)
+	for _, kw := range keywords {
+		kw = strings.TrimSpace(kw)
+		if kw == "" {
+			continue
+		}
+		total++
+		if !strings.Contains(lower, strings.ToLower(kw)) {
+			missing = append(missing, kw)
+		}
+	}
+	if total == 0 {
+		return 1, missing
+	}
+	return float64(total-len(missing)) / float64(total), missing
+}
+
+// lengthFit 衡量长度与规则目标的接近程度：有区间取中点，只有上限取上限，只有下限取下限。
+// 有逐行约束时按条目平均，否则按整段计算；没有长度规则记满分。
+func lengthFit(rule config.SectionRuleFile, items []string, text string) float64 {
+	c := rule.Parsed.Constraints
+	if c.MinCharsPerLine.Value > 0 || c.MaxCharsPerLine.Value > 0 {
+		bounds := resolveRuleBounds(rule.Parsed, c.MinCharsPerLine, c.MaxCharsPerLine, 0)
+		if len(items) == 0 {
+			return 0
+		}
+		sum := 0.0
+		for _, it := range items {
+			sum += boundsFit(bounds, it)
+		}
+		return sum / float64(len(items))
+	}
+	if c.MaxChars.Value > 0 {
+		return boundsFit(resolveRuleBounds(rule.Parsed, config.RuleIntConstraint{}, c.MaxChars, 0), text)
+	}
+	return 1
+}
+
+func boundsFit(b charBounds, s string) float64 {
+	target := 0
+	switch {
+	case b.hasMin && b.hasMax:
+		target = (b.ruleMin + b.ruleMax) / 2
+	case b.hasMax:
+		target = b.ruleMax
+	case b.hasMin:
+		target = b.ruleMin
+	default:
+		return 1
+	}
+	if target <= 0 {
+		return 1
+	}
+	diff := math.Abs(float64(b.measure(s) - target))
+	return math.Max(0, 1-diff/float64(target))
+}
+
+// forbiddenHits 统计规则 forbidden 与风格促销用语的命中（整词、不区分大小写）。
+func forbiddenHits(rule config.SectionRuleFile, style *stylePolicy, text string) []string {
+	hits := []string{}
+	for _, term := range rule.Parsed.Forbidden {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		if keywordMatches(text, term, config.MatchWordBoundary) {
+			hits = append(hits, term)
+		}
+	}
+	if style != nil {
+		hits = append(hits, style.promotionalHits(text)...)
+	}
+	return dedupeIssues(hits)
+}
+
+func warningScore(n int) float64 {
+	return math.Max(0, 1-0.1*float64(n))
+}
+
+func forbiddenScore(n int) float64 {
+	return math.Max(0, 1-0.25*float64(n))
+}
+
+var (
+	sentenceEndRe = regexp.MustCompile(`[.!?;:]+(\s|$)|\n+`)
+	vowelGroupRe  = regexp.MustCompile(`[aeiouy]+`)
+)
+
+// readingEase 按 Flesch Reading Ease 估算英文可读性，归一化到 0~1；无英文单词时记满分。
+func readingEase(text string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z') && r != '\''
+	})
+	if len(words) == 0 {
+		return 1
+	}
+	sentences := 0
+	for _, s := range sentenceEndRe.Split(text, -1) {
+		if strings.TrimSpace(s) != "" {
+			sentences++
+		}
+	}
+	if sentences == 0 {
+		sentences = 1
+	}
+	syllables := 0
+	for _, w := range words {
+		syllables += countSyllables(w)
+	}
+	fre := 206.835 - 1.015*float64(len(words))/float64(sentences) - 84.6*float64(syllables)/float64(len(words))
+	return math.Min(1, math.Max(0, fre/100))
+}
+
+func countSyllables(w string) int {
+	w = strings.TrimSuffix(w, "'s")
+	if len(w) > 3 && strings.HasSuffix(w, "e") && !strings.HasSuffix(w, "le") {
+		w = w[:len(w)-1]
+	}
+	n := len(vowelGroupRe.FindAllString(w, -1))
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func roundRatio(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+)
+
+func TestScoreDocumentPrefersCoverageAndFit(t *testing.T) {
+	rules := testRules()
+	rules.Title.Parsed.Forbidden = []string{"cheap"}
+	req := listing.Requirement{Keywords: []string{"alpha", "beta", "gamma"}}
+	good := ListingDocument{
+		Title:                 "Alpha Beta Gamma Organizer " + strings.Repeat("x", 150),
+		BulletPoints:          []string{"Sturdy alpha box holds", "Soft beta lid closes", "Clear gamma view here", "Easy to clean and use", "Fits most shelves ok"},
+		DescriptionParagraphs: []string{"A box for your room.", "Keep it tidy."},
+		SearchTerms:           "alpha beta gamma",
+	}
+	bad := good
+	bad.Title = "Cheap Organizer"
+	bad.BulletPoints = []string{"x", "y", "z", "w", "v"}
+
+	gs := scoreDocument(req, rules, good, nil, 0)
+	bs := scoreDocument(req, rules, bad, nil, 0)
+	if gs.Total <= bs.Total {
+		t.Fatalf("good doc should score higher: %.1f vs %.1f", gs.Total, bs.Total)
+	}
+	if gs.KeywordCoverage != 1 || len(gs.MissingKeywords) != 0 {
+		t.Fatalf("unexpected coverage: %+v", gs)
+	}
+	if len(bs.ForbiddenHits) != 1 || bs.ForbiddenHits[0] != "cheap" {
+		t.Fatalf("forbidden hit missing: %+v", bs.ForbiddenHits)
+	}
+	if len(gs.Sections) != 4 || gs.Sections[0].Name != "title" {
+		t.Fatalf("section scores missing: %+v", gs.Sections)
+	}
+	if bs.Sections[1].LengthFit >= gs.Sections[1].LengthFit {
+		t.Fatalf("short bullets should fit worse: %+v vs %+v", bs.Sections[1], gs.Sections[1])
+	}
+}
+
+func TestScoringHelpers(t *testing.T) {
+	if fit := boundsFit(resolveCharBounds(10, 20, 0), strings.Repeat("x", 15)); fit != 1 {
+		t.Fatalf("midpoint should fit perfectly: %v", fit)
+	}
+	if fit := boundsFit(resolveCharBounds(0, 100, 0), strings.Repeat("x", 50)); fit != 0.5 {
+		t.Fatalf("max-only target should be max: %v", fit)
+	}
+	if readingEase("The cat sat on the mat.") <= readingEase("Comprehensive multifunctional organizational infrastructure characteristics.") {
+		t.Fatalf("short words should read easier")
+	}
+	cov, missing := keywordCoverage([]string{"Alpha", "delta"}, "alpha beta")
+	if cov != 0.5 || len(missing) != 1 || missing[0] != "delta" {
+		t.Fatalf("unexpected coverage: %v %v", cov, missing)
+	}
+	paras := config.SectionRuleFile{Parsed: config.SectionRule{Output: config.RuleOutputSpec{Paragraphs: 2, ParagraphSeparator: "---"}}}
+	if got := joinSectionItems(paras, []string{"a", "b"}); got != "a\n---\nb" {
+		t.Fatalf("unexpected join: %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/logging"
+)
+
+// defaultSearchTermsLimit 是规则未给 max_chars 时的上限（亚马逊后台搜索词 250 字节）。
+const defaultSearchTermsLimit = 250
+
+// composeSearchTerms 按指南算法在本地拼接搜索词：关键词按权重顺序拆词，
+// 去掉亚马逊不允许的字符，按词干去重（pocket/pockets 视为同一词），
+// 可选剔除标题已出现的词，在规则的 max_chars 口径（字节或字符）内尽量填满。
+// 全部关键词用完仍未填满时，从第 1 个关键词起按顺序循环补齐，直到下一个关键词放不下为止。
+func composeSearchTerms(keywords []string, title string, rule config.SectionRuleFile) string {
+	bounds := resolveRuleBounds(rule.Parsed, config.RuleIntConstraint{}, rule.Parsed.Constraints.MaxChars, 0)
+	limit := defaultSearchTermsLimit
+	if bounds.hasMax {
+		limit = bounds.ruleMax
+	}
+	skip := map[string]bool{}
+	if rule.Parsed.Execution.Generation.ExcludeTitleWords {
+		for _, tok := range searchTermTokens(title) {
+			skip[stemWord(tok)] = true
+		}
+	}
+	used := map[string]bool{}
+	parts := make([]string, 0, 32)
+	// cycle 是每个关键词去掉标题词后的词，循环补齐时整条追加。
+	cycle := make([][]string, 0, len(keywords))
+	for _, kw := range keywords {
+		var kept []string
+		for _, tok := range searchTermTokens(kw) {
+			stem := stemWord(tok)
+			if skip[stem] {
+				continue
+			}
+			kept = append(kept, tok)
+			if used[stem] {
+				continue
+			}
+			candidate := strings.Join(append(parts, tok), " ")
+			if bounds.measure(candidate) > limit {
+				// 放不下的词跳过，继续尝试后面更短的词。
+				continue
+			}
+			parts = append(parts, tok)
+			used[stem] = true
+		}
+		if len(kept) > 0 {
+			cycle = append(cycle, kept)
+		}
+	}
+	for len(parts) > 0 && len(cycle) > 0 {
+		for _, kept := range cycle {
+			candidate := strings.Join(append(parts, kept...), " ")
+			if bounds.measure(candidate) > limit {
+				return strings.Join(parts, " ")
+			}
+			parts = append(parts, kept...)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// searchTermTokens 把关键词拆成小写词：只保留字母与数字，其余字符（标点、符号、emoji）视为分隔。
+func searchTermTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// composeSectionDeterministic 执行 protocol=deterministic 的分段：不调用模型，本地拼接后按规则校验。
+func composeSectionDeterministic(opts sectionGenerateOptions, step string, doc ListingDocument, rule config.SectionRuleFile) ([]string, error) {
+	line := composeSearchTerms(opts.Req.Keywords, doc.Title, rule)
+	if line == "" {
+		return nil, fmt.Errorf("%s 本地拼接结果为空：关键词为空或全部被剔除", step)
+	}
+	issues, warnings := validateSectionText(step, opts.Lang, opts.Req, line, rule, opts.CharTolerance)
+	for _, w := range warnings {
+		opts.Logger.Emit(logging.Event{
+			Level:     "warn",
+			Event:     "validation_warning",
+			Input:     opts.Req.SourcePath,
+			Candidate: opts.Candidate,
+			Lang:      opts.Lang,
+			Error:     w,
+		})
+	}
+	if len(issues) > 0 {
+		return nil, fmt.Errorf("%s 本地拼接校验失败：%s", step, strings.Join(issues, "; "))
+	}
+	ev := logging.Event{
+		Event:     "compose_" + step,
+		Input:     opts.Req.SourcePath,
+		Candidate: opts.Candidate,
+		Lang:      opts.Lang,
+	}
+	if opts.Logger.Verbose() {
+		ev.ResponseText = line
+	}
+	opts.Logger.Emit(ev)
+	return []string{line}, nil
+}
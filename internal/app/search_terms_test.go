@@ -0,0 +1,110 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+)
+
+func deterministicSearchRule(max int, count string, excludeTitle bool) config.SectionRuleFile {
+	return config.SectionRuleFile{Parsed: config.SectionRule{
+		Section:     "search_terms",
+		Output:      config.RuleOutputSpec{Format: "plain_text", Lines: 1},
+		Constraints: config.RuleConstraints{MaxChars: config.RuleIntConstraint{Value: max, Count: count}},
+		Execution: config.RuleExecutionSpec{Generation: config.RuleGenerationSpec{
+			Protocol:          "deterministic",
+			ExcludeTitleWords: excludeTitle,
+		}},
+	}}
+}
+
+func TestComposeSearchTermsDedupesAndStripsDisallowed(t *testing.T) {
+	rule := deterministicSearchRule(40, "bytes", false)
+	got := composeSearchTerms([]string{"Storage Box!", "storage boxes", "Pocket-Organizer", "pockets, {drawer}"}, "", rule)
+	if got != "storage box pocket organizer drawer" {
+		t.Fatalf("unexpected composed line: %q", got)
+	}
+}
+
+func TestComposeSearchTermsExcludesTitleWords(t *testing.T) {
+	rule := deterministicSearchRule(25, "bytes", true)
+	got := composeSearchTerms([]string{"storage box", "closet organizer", "shelf bins"}, "Storage Boxes for Closet", rule)
+	if got != "organizer shelf bins" {
+		t.Fatalf("title words should be removed: %q", got)
+	}
+}
+
+func TestComposeSearchTermsRespectsByteLimit(t *testing.T) {
+	rule := deterministicSearchRule(12, "bytes", false)
+	got := composeSearchTerms([]string{"收纳盒", "alpha", "be"}, "", rule)
+	// "收纳盒" 占 9 字节，"alpha" 放不下被跳过，"be" 仍可放入。
+	if got != "收纳盒 be" || len(got) > 12 {
+		t.Fatalf("unexpected byte-limited line: %q (%d bytes)", got, len(got))
+	}
+}
+
+func TestComposeSearchTermsCyclesFromFirstKeyword(t *testing.T) {
+	rule := deterministicSearchRule(60, "bytes", true)
+	got := composeSearchTerms([]string{"storage box", "Closet Organizer", "shelf bins"}, "Storage Boxes", rule)
+	// 第一轮去重后为 "closet organizer shelf bins"（27 字节），再从第 1 个关键词循环：
+	// storage box 全是标题词被跳过，依次追加 closet organizer、shelf bins，下一条放不下时停止。
+	if got != "closet organizer shelf bins closet organizer shelf bins" {
+		t.Fatalf("unexpected cycled line: %q", got)
+	}
+	if got := composeSearchTerms([]string{"storage box"}, "Storage Boxes", rule); got != "" {
+		t.Fatalf("keywords fully covered by the title should compose nothing: %q", got)
+	}
+}
+
+func TestGenerateDocumentWithDeterministicSearchTerms(t *testing.T) {
+	upstream := newLLMTestServer()
+	defer upstream.Close()
+	var searchCalls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "生成：search_terms") {
+			atomic.AddInt32(&searchCalls, 1)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		upstream.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	rules := testRules()
+	rules.SearchTerms = deterministicSearchRule(10, "bytes", true)
+	doc, _, err := generateDocumentBySections(sectionGenerateOptions{
+		Req: listing.Requirement{
+			SourcePath:      "/tmp/a.md",
+			BodyAfterMarker: "body",
+			Category:        "Cat",
+			Keywords:        []string{"alpha", "beta", "gamma"},
+		},
+		Lang:          "en",
+		CharTolerance: 20,
+		Provider:      "deepseek",
+		ProviderCfg:   config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		APIKey:        "k",
+		Rules:         rules,
+		Client:        llm.NewClient(10 * time.Second),
+		Candidate:     1,
+	})
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	// 标题为 "alpha beta generated title"，alpha/beta 被剔除。
+	if doc.SearchTerms != "gamma" {
+		t.Fatalf("unexpected search terms: %q", doc.SearchTerms)
+	}
+	if n := atomic.LoadInt32(&searchCalls); n != 0 {
+		t.Fatalf("deterministic search_terms should not call the model, got %d calls", n)
+	}
+}
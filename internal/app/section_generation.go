@@ -6,17 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"syl-listing/internal/config"
+	"syl-listing/internal/library"
 	"syl-listing/internal/listing"
 	"syl-listing/internal/llm"
 	"syl-listing/internal/logging"
 )
 
 type sectionGenerateOptions struct {
+	Context       context.Context
 	Req           listing.Requirement
 	Lang          string
 	CharTolerance int
@@ -28,6 +31,94 @@ type sectionGenerateOptions struct {
 	Client        *llm.Client
 	Logger        *logging.Logger
 	Candidate     int
+	// Prompts 非 nil 时记录每次请求的完整消息（dry-run）。
+	Prompts *promptDump
+	// Style 非 nil 时对英文标题与五点执行亚马逊风格修正与检查。
+	Style *stylePolicy
+	// Facts 非 nil 时核对英文文案中的数值是否来自需求原文。
+	Facts *factSheet
+	// Direction 为多候选时的写作角度与需避开的标题。
+	Direction candidateDirection
+	// Extras 为角度之外注入用户提示词的参考与修订要求。
+	Extras promptExtras
+}
+
+// promptExtras 是写作角度之外注入分段用户提示词的内容；零值表示不注入。
+type promptExtras struct {
+	// ReviewComments 为评审给出的修改意见，Reviewed 为被评审的本段原文；只在评审后修复该分段时注入。
+	ReviewComments []string
+	Reviewed       []string
+	// Existing 为编辑模式下该分段的现有文案，ExistingIssues 为其未通过的校验；只在修订该分段时注入。
+	Existing       []string
+	ExistingIssues []string
+	// ReferenceTitle 为需求中的参考标题（版本A），只在生成标题时作为风格参考注入。
+	ReferenceTitle string
+	// Voice 为品牌语气档案，Examples 为从范例库选出的已审核 listing；范例只注入当前分段的内容。
+	Voice    string
+	Examples []library.Example
+}
+
+// examplesFor 返回各范例中分段 step 的条目，跳过没有该分段的范例。
+func examplesFor(examples []library.Example, step string) [][]string {
+	var out [][]string
+	for _, e := range examples {
+		if items := e.SectionItems(step); len(items) > 0 {
+			out = append(out, items)
+		}
+	}
+	return out
+}
+
+func (e promptExtras) prompt(step string) string {
+	var b strings.Builder
+	if e.Voice != "" {
+		b.WriteString("\n【品牌语气】\n")
+		b.WriteString(e.Voice)
+		b.WriteString("\n")
+	}
+	if examples := examplesFor(e.Examples, step); len(examples) > 0 {
+		b.WriteString("\n【已审核范例】以下为同类已审核通过的 listing 中的本段内容，学习其语气、结构与信息密度，不要照抄词句与数值：\n")
+		for i, items := range examples {
+			b.WriteString(fmt.Sprintf("范例%d：\n", i+1))
+			for _, it := range items {
+				b.WriteString("- ")
+				b.WriteString(it)
+				b.WriteString("\n")
+			}
+		}
+	}
+	if step == "title" && e.ReferenceTitle != "" {
+		b.WriteString("\n【参考标题】运营参照竞品写的标题如下，只借鉴其风格与结构，不要照抄原句，仍须满足全部规则约束：\n")
+		b.WriteString(e.ReferenceTitle)
+		b.WriteString("\n")
+	}
+	if len(e.ReviewComments) > 0 {
+		if len(e.Reviewed) > 0 {
+			b.WriteString("\n【待修复文案】以下为被评审的本段内容：\n")
+			for i, it := range e.Reviewed {
+				b.WriteString(fmt.Sprintf("%d. %s\n", i+1, it))
+			}
+		}
+		b.WriteString("\n【评审意见】上述本段内容的评审意见如下，请据此改写，同时仍须满足全部规则约束：\n")
+		for _, c := range e.ReviewComments {
+			b.WriteString("- ")
+			b.WriteString(c)
+			b.WriteString("\n")
+		}
+	}
+	if len(e.Existing) > 0 {
+		b.WriteString("\n【现有文案】以下为线上现有的本段内容：\n")
+		for i, it := range e.Existing {
+			b.WriteString(fmt.Sprintf("%d. %s\n", i+1, it))
+		}
+		b.WriteString("【修订要求】在现有文案基础上做最小改动：只修改为满足规则约束与关键词所必需的词句，其余措辞、顺序与卖点保持原样，条目数按规则输出。\n")
+		for _, issue := range e.ExistingIssues {
+			b.WriteString("- ")
+			b.WriteString(issue)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
 }
 
 type sectionExecutionPolicy struct {
@@ -48,12 +139,17 @@ func (p sectionExecutionPolicy) useJSONLines() bool {
 	return p.Protocol == "json_lines"
 }
 
+func (p sectionExecutionPolicy) useDeterministic() bool {
+	return p.Protocol == "deterministic"
+}
+
 func (p sectionExecutionPolicy) useItemRepair() bool {
 	return p.Granularity == "item"
 }
 
 func providerSupportsJSONMode(provider string) bool {
-	return strings.EqualFold(strings.TrimSpace(provider), "deepseek")
+	p := strings.ToLower(strings.TrimSpace(provider))
+	return p == "deepseek" || p == "mock"
 }
 
 func generateDocumentBySections(opts sectionGenerateOptions) (ListingDocument, int64, error) {
@@ -63,66 +159,62 @@ func generateDocumentBySections(opts sectionGenerateOptions) (ListingDocument, i
 	}
 	var total int64
 
-	title, latency, err := generateSectionWithRetry(opts, "title", doc)
-	total += latency
-	if err != nil {
-		return ListingDocument{}, total, err
+	for _, sec := range opts.Rules.Sections() {
+		items, latency, err := generateManifestSection(opts, sec, doc)
+		total += latency
+		if err != nil {
+			return ListingDocument{}, total, err
+		}
+		doc.setSection(sec, opts.Lang, items)
 	}
-	doc.Title = cleanTitleLine(title)
 
-	bulletRule, err := opts.Rules.Get("bullets")
+	emitFactCheckTable(opts, doc)
+	return doc, total, validateDocumentBySectionRules(opts.Lang, opts.Req, doc, opts.Rules)
+}
+
+// generateManifestSection 按清单项生成单个分段：上下文只带依赖分段，按规则协议与输出类型拆分结果。
+func generateManifestSection(opts sectionGenerateOptions, sec config.ManifestSection, doc ListingDocument) ([]string, int64, error) {
+	rule, err := opts.Rules.Get(sec.Name)
 	if err != nil {
-		return ListingDocument{}, total, err
+		return nil, 0, err
+	}
+	ctxDoc := doc.contextFor(sec.DependsOn)
+	policy := resolveSectionExecutionPolicy(rule)
+	if policy.useDeterministic() {
+		// 标题去词以实际标题为准，不受 depends_on 裁剪。
+		items, err := composeSectionDeterministic(opts, sec.Name, doc, rule)
+		return items, 0, err
 	}
-	var bullets []string
-	bulletPolicy := resolveSectionExecutionPolicy(bulletRule)
-	if bulletPolicy.useJSONLines() {
+	if policy.useJSONLines() {
 		if !providerSupportsJSONMode(opts.Provider) {
-			return ListingDocument{}, total, fmt.Errorf("provider %s 不支持 json_lines 协议", opts.Provider)
+			return nil, 0, fmt.Errorf("provider %s 不支持 json_lines 协议", opts.Provider)
 		}
-		items, itemLatency, itemErr := generateJSONLinesWithRepair(opts, "bullets", doc, bulletRule)
-		total += itemLatency
-		if itemErr != nil {
-			return ListingDocument{}, total, itemErr
-		}
-		bullets = items
-	} else {
-		bulletsText, sectionLatency, sectionErr := generateSectionWithRetry(opts, "bullets", doc)
-		total += sectionLatency
-		if sectionErr != nil {
-			return ListingDocument{}, total, sectionErr
-		}
-		items, parseErr := parseBullets(bulletsText, bulletRule.Parsed.Output.Lines)
-		if parseErr != nil {
-			return ListingDocument{}, total, parseErr
-		}
-		bullets = items
-	}
-	doc.BulletPoints = bullets
-
-	descText, latency, err := generateSectionWithRetry(opts, "description", doc)
-	total += latency
-	if err != nil {
-		return ListingDocument{}, total, err
+		return generateJSONLinesWithRepair(opts, sec.Name, ctxDoc, rule)
 	}
-	descRule, err := opts.Rules.Get("description")
+	text, latency, err := generateSectionWithRetry(opts, sec.Name, ctxDoc)
 	if err != nil {
-		return ListingDocument{}, total, err
+		return nil, latency, err
 	}
-	desc, err := parseParagraphs(descText, descRule.Parsed.Output.Paragraphs)
-	if err != nil {
-		return ListingDocument{}, total, err
-	}
-	doc.DescriptionParagraphs = desc
+	items, err := splitSectionOutput(sec.Name, rule, text)
+	return items, latency, err
+}
 
-	search, latency, err := generateSectionWithRetry(opts, "search_terms", doc)
-	total += latency
-	if err != nil {
-		return ListingDocument{}, total, err
+// splitSectionOutput 把分段文本拆成条目：单行分段一个元素，多行按条目，描述类按段落。
+func splitSectionOutput(step string, rule config.SectionRuleFile, text string) ([]string, error) {
+	switch step {
+	case "title":
+		return []string{cleanTitleLine(text)}, nil
+	case "search_terms":
+		return []string{cleanSearchTermsLine(text)}, nil
+	}
+	switch rule.OutputKind() {
+	case config.OutputLines:
+		return parseBullets(text, rule.Parsed.Output.Lines)
+	case config.OutputParagraphs:
+		return parseParagraphsBySeparator(text, rule.Parsed.Output.Paragraphs, rule.Parsed.Output.ParagraphSeparator)
+	default:
+		return []string{normalizeSingleLine(text)}, nil
 	}
-	doc.SearchTerms = cleanSearchTermsLine(search)
-
-	return doc, total, validateDocumentBySectionRules(opts.Lang, opts.Req, doc, opts.Rules)
 }
 
 func generateSectionWithRetry(opts sectionGenerateOptions, step string, doc ListingDocument) (string, int64, error) {
@@ -134,6 +226,7 @@ func generateSectionWithRetry(opts sectionGenerateOptions, step string, doc List
 	history := make([]llm.Message, 0, 6)
 	lengthIssue := false
 	err := withExponentialBackoff(retryOptions{
+		Context:    opts.Context,
 		MaxRetries: opts.MaxRetries,
 		BaseDelay:  500 * time.Millisecond,
 		MaxDelay:   8 * time.Second,
@@ -157,7 +250,7 @@ func generateSectionWithRetry(opts sectionGenerateOptions, step string, doc List
 			return errors.New(lastIssues)
 		}
 		systemPrompt := buildSectionSystemPrompt(sectionRule)
-		baseUserPrompt := buildSectionUserPrompt(step, opts.Req, doc)
+		baseUserPrompt := buildSectionUserPrompt(step, opts.Req, doc, opts.Direction, opts.Extras)
 		messages := make([]llm.Message, 0, 2+len(history))
 		messages = append(messages,
 			llm.Message{Role: "system", Content: systemPrompt},
@@ -193,9 +286,12 @@ func generateSectionWithRetry(opts sectionGenerateOptions, step string, doc List
 			reqEvent.UserPrompt = baseUserPrompt
 		}
 		opts.Logger.Emit(reqEvent)
-		resp, err := opts.Client.Generate(context.Background(), llm.Request{
+		opts.Prompts.record(step, attempt, messages)
+		abortLimit := streamAbortLimit(opts, sectionRule)
+		resp, err := opts.Client.Generate(contextOrBackground(opts.Context), llm.Request{
 			Provider:        opts.Provider,
 			BaseURL:         opts.ProviderCfg.BaseURL,
+			Candidate:       opts.Candidate,
 			Model:           reqModel,
 			APIMode:         opts.ProviderCfg.APIMode,
 			APIKey:          opts.APIKey,
@@ -203,12 +299,27 @@ func generateSectionWithRetry(opts sectionGenerateOptions, step string, doc List
 			SystemPrompt:    systemPrompt,
 			UserPrompt:      baseUserPrompt,
 			Messages:        messages,
+			Stream:          opts.ProviderCfg.Stream,
+			OnChunk:         streamChunkLogger(opts, step, attempt),
+			AbortAfterChars: abortLimit,
 		})
 		if err != nil {
 			lastIssues = "- API 调用失败: " + err.Error()
 			opts.Logger.Emit(logging.Event{Level: "warn", Event: "api_error_" + step, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: opts.Lang, Attempt: attempt, Error: err.Error()})
 			return errors.New(lastIssues)
 		}
+		if resp.Aborted {
+			partial := normalizeModelText(resp.Text)
+			issue := fmt.Sprintf("%s长度超出容差区间：流式输出已超过 %d 字符，已提前中止", sectionLabel(step), abortLimit)
+			lastIssues = "- " + issue
+			history = append(history,
+				llm.Message{Role: "assistant", Content: partial},
+				llm.Message{Role: "user", Content: buildSectionRepairPrompt(step, []string{issue})},
+			)
+			lengthIssue = true
+			opts.Logger.Emit(logging.Event{Level: "warn", Event: "validate_error_" + step, Input: opts.Req.SourcePath, Candidate: opts.Candidate, Lang: opts.Lang, Attempt: attempt, LatencyMS: resp.LatencyMS, Error: issue})
+			return errors.New(issue)
+		}
 
 		text := normalizeModelText(resp.Text)
 		respEvent := logging.Event{
@@ -221,6 +332,9 @@ func generateSectionWithRetry(opts sectionGenerateOptions, step string, doc List
 			LatencyMS: resp.LatencyMS,
 			Attempt:   attempt,
 		}
+		if resp.Cached {
+			respEvent.Cache = "hit"
+		}
 		if opts.Logger.Verbose() {
 			respEvent.ResponseText = text
 		}
@@ -228,7 +342,10 @@ func generateSectionWithRetry(opts sectionGenerateOptions, step string, doc List
 		if step == "search_terms" {
 			text = cleanSearchTermsLine(text)
 		}
+		text, styleIssues := opts.Style.applySectionText(step, opts.Lang, sectionRule, text)
 		issues, warnings := validateSectionText(step, opts.Lang, opts.Req, text, sectionRule, opts.CharTolerance)
+		issues = append(issues, styleIssues...)
+		issues = dedupeIssues(append(issues, opts.Facts.sectionIssues(step, opts.Lang, text)...))
 		for _, w := range warnings {
 			opts.Logger.Emit(logging.Event{
 				Level:     "warn",
@@ -274,11 +391,7 @@ func generateJSONLinesWithRepair(opts sectionGenerateOptions, step string, doc L
 		return nil, 0, fmt.Errorf("%s 规则 output.lines 无效：%d", step, expected)
 	}
 	policy := resolveSectionExecutionPolicy(rule)
-	bounds := resolveCharBounds(
-		rule.Parsed.Constraints.MinCharsPerLine.Value,
-		rule.Parsed.Constraints.MaxCharsPerLine.Value,
-		opts.CharTolerance,
-	)
+	bounds := resolveRuleBounds(rule.Parsed, rule.Parsed.Constraints.MinCharsPerLine, rule.Parsed.Constraints.MaxCharsPerLine, opts.CharTolerance)
 	var total int64
 
 	out, latencyMS, err := generateJSONLinesBatchWithRetry(opts, step, doc, rule, bounds)
@@ -288,9 +401,9 @@ func generateJSONLinesWithRepair(opts sectionGenerateOptions, step string, doc L
 	}
 
 	for i := range out {
-		out[i] = normalizeLineByBounds(cleanBulletLine(out[i]), bounds, opts.Req.Keywords)
+		out[i] = normalizeLineByBounds(opts.Style.fixItem(step, opts.Lang, cleanBulletLine(out[i])), bounds, opts.Req.Keywords)
 	}
-	invalidIndexes, issues, warnings := validateLineSet(step, out, bounds)
+	invalidIndexes, issues, warnings := validateLineSetWithChecks(opts, step, out, bounds)
 	for _, w := range warnings {
 		opts.Logger.Emit(logging.Event{
 			Level:     "warn",
@@ -343,7 +456,7 @@ func generateJSONLinesWithRepair(opts sectionGenerateOptions, step string, doc L
 		return nil, total, firstErr
 	}
 
-	_, finalIssues, _ := validateLineSet(step, repaired, bounds)
+	_, finalIssues, _ := validateLineSetWithChecks(opts, step, repaired, bounds)
 	if len(finalIssues) > 0 {
 		return nil, total, fmt.Errorf("%s 修复后仍不满足规则：%s", step, strings.Join(finalIssues, "; "))
 	}
@@ -379,10 +492,11 @@ func generateJSONLinesBatchWithRetry(opts sectionGenerateOptions, step string, d
 Return valid json only.
 必须只返回一个 json object，禁止 markdown 代码块、禁止解释。
 对象中必须包含一个字符串数组字段，长度必须满足 output.lines。`
-	baseUserPrompt := buildSectionUserPrompt(step, opts.Req, doc) +
+	baseUserPrompt := buildSectionUserPrompt(step, opts.Req, doc, opts.Direction, opts.Extras) +
 		fmt.Sprintf("\n【输出要求】必须返回 json object，其中字符串数组字段长度必须恰好 %d。", expected)
 	history := make([]llm.Message, 0, 8)
 	err := withExponentialBackoff(retryOptions{
+		Context:    opts.Context,
 		MaxRetries: opts.MaxRetries,
 		BaseDelay:  500 * time.Millisecond,
 		MaxDelay:   8 * time.Second,
@@ -390,7 +504,7 @@ Return valid json only.
 		OnRetry: func(attempt int, wait time.Duration, err error) {
 			opts.Logger.Emit(logging.Event{
 				Level:     "warn",
-				Event:     "retry_backoff_bullets",
+				Event:     "retry_backoff_" + step,
 				Input:     opts.Req.SourcePath,
 				Candidate: opts.Candidate,
 				Lang:      opts.Lang,
@@ -435,9 +549,11 @@ Return valid json only.
 			reqEvent.UserPrompt = baseUserPrompt
 		}
 		opts.Logger.Emit(reqEvent)
-		resp, err := opts.Client.Generate(context.Background(), llm.Request{
+		opts.Prompts.record(step, attempt, messages)
+		resp, err := opts.Client.Generate(contextOrBackground(opts.Context), llm.Request{
 			Provider:        opts.Provider,
 			BaseURL:         opts.ProviderCfg.BaseURL,
+			Candidate:       opts.Candidate,
 			Model:           reqModel,
 			APIMode:         opts.ProviderCfg.APIMode,
 			APIKey:          opts.APIKey,
@@ -446,6 +562,8 @@ Return valid json only.
 			UserPrompt:      baseUserPrompt,
 			Messages:        messages,
 			JSONMode:        true,
+			Stream:          opts.ProviderCfg.Stream,
+			OnChunk:         streamChunkLogger(opts, step, attempt),
 		})
 		if err != nil {
 			lastIssues = "- API 调用失败: " + err.Error()
@@ -471,6 +589,9 @@ Return valid json only.
 			LatencyMS: resp.LatencyMS,
 			Attempt:   attempt,
 		}
+		if resp.Cached {
+			respEvent.Cache = "hit"
+		}
 		if opts.Logger.Verbose() {
 			respEvent.ResponseText = text
 		}
@@ -531,23 +652,24 @@ func regenerateJSONLineItemWithRetry(opts sectionGenerateOptions, step string, d
 Return valid json only.
 必须只返回一个 json object，且对象中必须包含一个字符串字段。`
 	tmpDoc := doc
-	switch step {
-	case "bullets":
-		tmpDoc.BulletPoints = append([]string{}, current...)
+	if sec, ok := opts.Rules.Section(step); ok {
+		tmpDoc.setSection(sec, opts.Lang, append([]string{}, current...))
 	}
 	if strings.TrimSpace(itemField) == "" {
 		itemField = "item"
 	}
-	baseUserPrompt := buildSectionUserPrompt(step, opts.Req, tmpDoc) +
+	baseUserPrompt := buildSectionUserPrompt(step, opts.Req, tmpDoc, opts.Direction, opts.Extras) +
 		fmt.Sprintf(
-			"\n【子任务】只修复第%d条，返回 json object，且仅包含一个字符串字段（键名=%s）。\n【硬约束】只返回一行文本，不得包含换行；文本长度必须落在规则区间 %s（容差区间 %s）。",
+			"\n【子任务】只修复第%d条，返回 json object，且仅包含一个字符串字段（键名=%s）。\n【硬约束】只返回一行文本，不得包含换行；文本长度（按%s计）必须落在规则区间 %s（容差区间 %s）。",
 			idx,
 			itemField,
+			bounds.unit(),
 			bounds.ruleText(),
 			bounds.toleranceText(),
 		)
 	history := make([]llm.Message, 0, 8)
 	err := withExponentialBackoff(retryOptions{
+		Context:    opts.Context,
 		MaxRetries: opts.MaxRetries,
 		BaseDelay:  500 * time.Millisecond,
 		MaxDelay:   8 * time.Second,
@@ -600,9 +722,11 @@ Return valid json only.
 			reqEvent.UserPrompt = baseUserPrompt
 		}
 		opts.Logger.Emit(reqEvent)
-		resp, err := opts.Client.Generate(context.Background(), llm.Request{
+		opts.Prompts.record(fmt.Sprintf("%s_item_%d", step, idx), attempt, messages)
+		resp, err := opts.Client.Generate(contextOrBackground(opts.Context), llm.Request{
 			Provider:        opts.Provider,
 			BaseURL:         opts.ProviderCfg.BaseURL,
+			Candidate:       opts.Candidate,
 			Model:           reqModel,
 			APIMode:         opts.ProviderCfg.APIMode,
 			APIKey:          opts.APIKey,
@@ -611,6 +735,8 @@ Return valid json only.
 			UserPrompt:      baseUserPrompt,
 			Messages:        messages,
 			JSONMode:        true,
+			Stream:          opts.ProviderCfg.Stream,
+			OnChunk:         streamChunkLogger(opts, fmt.Sprintf("%s_item_%d", step, idx), attempt),
 		})
 		if err != nil {
 			lastIssues = "- API 调用失败: " + err.Error()
@@ -637,6 +763,9 @@ Return valid json only.
 			LatencyMS: resp.LatencyMS,
 			Attempt:   attempt,
 		}
+		if resp.Cached {
+			respEvent.Cache = "hit"
+		}
 		if opts.Logger.Verbose() {
 			respEvent.ResponseText = text
 		}
@@ -661,9 +790,10 @@ Return valid json only.
 			lengthIssue = false
 			return errors.New(lastIssues)
 		}
-		line = normalizeLineByBounds(cleanBulletLine(line), bounds, opts.Req.Keywords)
+		line = normalizeLineByBounds(opts.Style.fixItem(step, opts.Lang, cleanBulletLine(line)), bounds, opts.Req.Keywords)
 
 		issues, warnings := validateLineItem(step, idx, line, bounds)
+		issues = append(issues, opts.extraItemIssues(step, idx, line)...)
 		for _, w := range warnings {
 			opts.Logger.Emit(logging.Event{
 				Level:     "warn",
@@ -728,6 +858,34 @@ func validateLineSet(step string, items []string, bounds charBounds) ([]int, []s
 	return invalid, dedupeIssues(issues), dedupeIssues(warnings)
 }
 
+// extraItemIssues 汇总规则之外的逐条检查：风格策略与数值事实核对。
+func (opts sectionGenerateOptions) extraItemIssues(step string, idx int, line string) []string {
+	issues := opts.Style.itemIssues(step, opts.Lang, idx, line)
+	return append(issues, opts.Facts.itemIssues(step, opts.Lang, idx, line)...)
+}
+
+// validateLineSetWithChecks 在规则校验之上叠加风格与数值问题，命中的条目也进入逐条修复。
+func validateLineSetWithChecks(opts sectionGenerateOptions, step string, items []string, bounds charBounds) ([]int, []string, []string) {
+	invalid, issues, warnings := validateLineSet(step, items, bounds)
+	marked := map[int]bool{}
+	for _, idx := range invalid {
+		marked[idx] = true
+	}
+	for i, it := range items {
+		extra := opts.extraItemIssues(step, i+1, it)
+		if len(extra) == 0 {
+			continue
+		}
+		if !marked[i+1] {
+			marked[i+1] = true
+			invalid = append(invalid, i+1)
+		}
+		issues = append(issues, extra...)
+	}
+	sort.Ints(invalid)
+	return invalid, dedupeIssues(issues), warnings
+}
+
 func validateBulletLine(idx int, raw string, bounds charBounds) ([]string, []string) {
 	return validateLineItem("bullets", idx, raw, bounds)
 }
@@ -747,14 +905,11 @@ func validateLineItem(step string, idx int, raw string, bounds charBounds) ([]st
 		issues = append(issues, fmt.Sprintf("%s为空", itemLabel))
 		return issues, warnings
 	}
-	n := runeLen(line)
-	if bounds.hasRule() && !bounds.inRule(n) {
-		if bounds.inTolerance(n) {
-			warnings = append(warnings, fmt.Sprintf("%s长度 %d 未落入规则区间 %s，但落入容差区间 %s，已放行", itemLabel, n, bounds.ruleText(), bounds.toleranceText()))
-		} else {
-			issues = append(issues, fmt.Sprintf("%s长度超出容差区间：%d 不在 %s（规则区间 %s）", itemLabel, n, bounds.toleranceText(), bounds.ruleText()))
-		}
-	}
+	c := &ruleIssueCollector{}
+	checkLengthBounds(c, "max_chars_per_line", itemLabel, line, bounds)
+	lineIssues, lineWarnings := c.result()
+	issues = append(issues, lineIssues...)
+	warnings = append(warnings, lineWarnings...)
 	return issues, warnings
 }
 
@@ -972,7 +1127,7 @@ func buildSectionSystemPrompt(rule config.SectionRuleFile) string {
 	return strings.TrimSpace(rule.Raw)
 }
 
-func buildSectionUserPrompt(step string, req listing.Requirement, doc ListingDocument) string {
+func buildSectionUserPrompt(step string, req listing.Requirement, doc ListingDocument, dir candidateDirection, extras promptExtras) string {
 	var b strings.Builder
 	b.WriteString("【需求原文】\n")
 	b.WriteString(req.BodyAfterMarker)
@@ -1003,6 +1158,20 @@ func buildSectionUserPrompt(step string, req listing.Requirement, doc ListingDoc
 			b.WriteString("\n\n")
 		}
 	}
+	if doc.SearchTerms != "" {
+		b.WriteString("\n【已生成搜索词】\n")
+		b.WriteString(doc.SearchTerms)
+		b.WriteString("\n")
+	}
+	for _, c := range doc.Extra {
+		b.WriteString(fmt.Sprintf("\n【已生成%s】\n", c.Heading))
+		for _, it := range c.Items {
+			b.WriteString(it)
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString(extras.prompt(step))
+	b.WriteString(dir.prompt(step))
 	b.WriteString("\n【当前任务】生成：")
 	b.WriteString(step)
 	b.WriteString("\n")
@@ -1061,6 +1230,52 @@ func resolveModelForAttempt(opts sectionGenerateOptions, rule config.SectionRule
 	return fallbackModel, true
 }
 
+// streamAbortLimit 返回流式提前中止的字符阈值：仅对有 max_chars 的单行分段生效，
+// 阈值取容差上限再放宽一半，超过即认为本轮必然进入修复，不必等完整输出。
+func streamAbortLimit(opts sectionGenerateOptions, rule config.SectionRuleFile) int {
+	if !opts.ProviderCfg.Stream || !opts.ProviderCfg.StreamEarlyAbort {
+		return 0
+	}
+	max := rule.Parsed.Constraints.MaxChars
+	// 按词计数时无法用字符阈值预判；按字节计数时字符数不大于字节数，字符阈值仍然安全。
+	if max.Value <= 0 || max.CountMode() == config.CountWords {
+		return 0
+	}
+	bounds := resolveCharBounds(0, max.Value, opts.CharTolerance)
+	return bounds.tolMax + bounds.tolMax/2
+}
+
+func streamChunkLogger(opts sectionGenerateOptions, step string, attempt int) func(string) {
+	if !opts.ProviderCfg.Stream || !opts.Logger.Verbose() {
+		return nil
+	}
+	return func(delta string) {
+		opts.Logger.Emit(logging.Event{
+			Event:        "api_stream_" + step,
+			Input:        opts.Req.SourcePath,
+			Candidate:    opts.Candidate,
+			Lang:         opts.Lang,
+			Attempt:      attempt,
+			ResponseText: delta,
+		})
+	}
+}
+
+func sectionLabel(step string) string {
+	switch step {
+	case "title":
+		return "标题"
+	case "bullets":
+		return "五点"
+	case "description":
+		return "描述"
+	case "search_terms":
+		return "搜索词"
+	default:
+		return step
+	}
+}
+
 func containsLengthError(issues []string) bool {
 	for _, s := range issues {
 		t := strings.TrimSpace(strings.ToLower(s))
@@ -1075,93 +1290,139 @@ func containsLengthError(issues []string) bool {
 }
 
 func validateSectionText(step, lang string, req listing.Requirement, text string, rule config.SectionRuleFile, tolerance int) ([]string, []string) {
-	issues := make([]string, 0)
-	warnings := make([]string, 0)
+	parsed := rule.Parsed
+	cons := parsed.Constraints
+	c := &ruleIssueCollector{rule: parsed}
 	switch step {
 	case "title":
 		t := cleanTitleLine(text)
 		if t == "" {
-			issues = append(issues, "标题为空")
-			return issues, warnings
-		}
-		max := rule.Parsed.Constraints.MaxChars.Value
-		n := runeLen(t)
-		bounds := resolveCharBounds(0, max, tolerance)
-		if bounds.hasRule() && !bounds.inRule(n) {
-			if bounds.inTolerance(n) {
-				warnings = append(warnings, fmt.Sprintf("标题长度 %d 未落入规则区间 %s，但落入容差区间 %s，已放行", n, bounds.ruleText(), bounds.toleranceText()))
-			} else {
-				issues = append(issues, fmt.Sprintf("标题长度超出容差区间：%d 不在 %s（规则区间 %s）", n, bounds.toleranceText(), bounds.ruleText()))
-			}
+			c.add("output", true, "标题为空")
+			return c.result()
 		}
+		bounds := resolveRuleBounds(parsed, config.RuleIntConstraint{}, cons.MaxChars, tolerance)
+		checkLengthBounds(c, "max_chars", "标题", t, bounds)
 		if lang == "en" {
-			topN := rule.Parsed.Constraints.MustContainTopNKeywords.Value
-			if topN > len(req.Keywords) {
-				topN = len(req.Keywords)
-			}
-			for i := 0; i < topN; i++ {
-				kw := strings.TrimSpace(req.Keywords[i])
-				if kw == "" {
-					continue
-				}
-				if !strings.Contains(strings.ToLower(t), strings.ToLower(kw)) {
-					issues = append(issues, fmt.Sprintf("标题缺少关键词 #%d: %s", i+1, kw))
-				}
-			}
+			checkTopNKeywords(c, parsed, req, t, "标题")
 		}
 	case "bullets":
-		expected := rule.Parsed.Output.Lines
+		expected := parsed.Output.Lines
 		items, err := parseBullets(text, expected)
 		if err != nil {
-			issues = append(issues, err.Error())
-			return issues, warnings
+			c.add("output", true, err.Error())
+			return c.result()
 		}
+		bounds := resolveRuleBounds(parsed, cons.MinCharsPerLine, cons.MaxCharsPerLine, tolerance)
 		for i, it := range items {
-			n := runeLen(it)
-			minLen := rule.Parsed.Constraints.MinCharsPerLine.Value
-			maxLen := rule.Parsed.Constraints.MaxCharsPerLine.Value
-			bounds := resolveCharBounds(minLen, maxLen, tolerance)
-			if bounds.hasRule() && !bounds.inRule(n) {
-				if bounds.inTolerance(n) {
-					warnings = append(warnings, fmt.Sprintf("第%d点长度 %d 未落入规则区间 %s，但落入容差区间 %s，已放行", i+1, n, bounds.ruleText(), bounds.toleranceText()))
-				} else {
-					issues = append(issues, fmt.Sprintf("第%d点长度超出容差区间：%d 不在 %s（规则区间 %s）", i+1, n, bounds.toleranceText(), bounds.ruleText()))
-				}
-			}
+			checkLengthBounds(c, "max_chars_per_line", fmt.Sprintf("第%d点", i+1), it, bounds)
 		}
 	case "description":
-		expected := rule.Parsed.Output.Paragraphs
-		pars, err := parseParagraphs(text, expected)
+		expected := parsed.Output.Paragraphs
+		pars, err := parseParagraphsBySeparator(text, expected, parsed.Output.ParagraphSeparator)
 		if err != nil {
-			issues = append(issues, err.Error())
-			return issues, warnings
+			c.add("output", true, err.Error())
+			return c.result()
 		}
 		for i, p := range pars {
 			if strings.TrimSpace(p) == "" {
-				issues = append(issues, fmt.Sprintf("描述第%d段为空", i+1))
+				c.add("output", true, fmt.Sprintf("描述第%d段为空", i+1))
 			}
 		}
 	case "search_terms":
 		line := cleanSearchTermsLine(text)
 		if line == "" {
-			issues = append(issues, "搜索词为空")
-			return issues, warnings
-		}
-		if countNonEmptyLines(text) != rule.Parsed.Output.Lines {
-			issues = append(issues, fmt.Sprintf("搜索词行数错误：%d != %d", countNonEmptyLines(text), rule.Parsed.Output.Lines))
-		}
-		max := rule.Parsed.Constraints.MaxChars.Value
-		n := runeLen(line)
-		bounds := resolveCharBounds(0, max, tolerance)
-		if bounds.hasRule() && !bounds.inRule(n) {
-			if bounds.inTolerance(n) {
-				warnings = append(warnings, fmt.Sprintf("搜索词长度 %d 未落入规则区间 %s，但落入容差区间 %s，已放行", n, bounds.ruleText(), bounds.toleranceText()))
-			} else {
-				issues = append(issues, fmt.Sprintf("搜索词长度超出容差区间：%d 不在 %s（规则区间 %s）", n, bounds.toleranceText(), bounds.ruleText()))
-			}
+			c.add("output", true, "搜索词为空")
+			return c.result()
+		}
+		if countNonEmptyLines(text) != parsed.Output.Lines {
+			c.add("output", true, fmt.Sprintf("搜索词行数错误：%d != %d", countNonEmptyLines(text), parsed.Output.Lines))
+		}
+		bounds := resolveRuleBounds(parsed, config.RuleIntConstraint{}, cons.MaxChars, tolerance)
+		checkLengthBounds(c, "max_chars", "搜索词", line, bounds)
+	default:
+		validateManifestSectionText(c, step, lang, req, text, rule, tolerance)
+	}
+	return c.result()
+}
+
+// validateManifestSectionText 按清单扩展分段的输出类型校验：单行看 max_chars 与关键词，多行看每行区间，段落看段数。
+func validateManifestSectionText(c *ruleIssueCollector, step, lang string, req listing.Requirement, text string, rule config.SectionRuleFile, tolerance int) {
+	parsed := rule.Parsed
+	cons := parsed.Constraints
+	switch rule.OutputKind() {
+	case config.OutputLines:
+		items, err := parseBullets(text, parsed.Output.Lines)
+		if err != nil {
+			c.add("output", true, err.Error())
+			return
+		}
+		bounds := resolveRuleBounds(parsed, cons.MinCharsPerLine, cons.MaxCharsPerLine, tolerance)
+		for i, it := range items {
+			checkLengthBounds(c, "max_chars_per_line", fmt.Sprintf("%s第%d行", step, i+1), it, bounds)
+		}
+	case config.OutputParagraphs:
+		pars, err := parseParagraphsBySeparator(text, parsed.Output.Paragraphs, parsed.Output.ParagraphSeparator)
+		if err != nil {
+			c.add("output", true, err.Error())
+			return
+		}
+		bounds := resolveRuleBounds(parsed, cons.MinCharsPerLine, cons.MaxCharsPerLine, tolerance)
+		for i, p := range pars {
+			checkLengthBounds(c, "max_chars_per_line", fmt.Sprintf("%s第%d段", step, i+1), p, bounds)
+		}
+	default:
+		line := normalizeSingleLine(text)
+		if line == "" {
+			c.add("output", true, fmt.Sprintf("%s为空", step))
+			return
+		}
+		if n := countNonEmptyLines(text); n > 1 {
+			c.add("output", true, fmt.Sprintf("%s应为单行输出：%d 行", step, n))
+		}
+		bounds := resolveRuleBounds(parsed, config.RuleIntConstraint{}, cons.MaxChars, tolerance)
+		checkLengthBounds(c, "max_chars", step, line, bounds)
+		if lang == "en" && cons.MustContainTopNKeywords.Value > 0 {
+			checkTopNKeywords(c, parsed, req, line, step)
+		}
+	}
+}
+
+// checkLengthBounds 校验单行长度：规则区间外但在容差内给警告；超出容差时按软硬约束分流。
+func checkLengthBounds(c *ruleIssueCollector, constraint, label, line string, bounds charBounds) {
+	n := bounds.measure(line)
+	if !bounds.hasRule() || bounds.inRule(n) {
+		return
+	}
+	unit := ""
+	if bounds.count != "" && bounds.count != config.CountRunes {
+		unit = bounds.unit()
+	}
+	if bounds.inTolerance(n) {
+		c.warn(fmt.Sprintf("%s长度 %d%s 未落入规则区间 %s，但落入容差区间 %s，已放行", label, n, unit, bounds.ruleText(), bounds.toleranceText()))
+		return
+	}
+	c.add(constraint, !bounds.violationIsSoft(n), fmt.Sprintf("%s长度超出容差区间：%d%s 不在 %s（规则区间 %s）", label, n, unit, bounds.toleranceText(), bounds.ruleText()))
+}
+
+// checkTopNKeywords 按 must_contain_top_n_keywords 的 source/match/hard 校验前 N 个关键词。
+func checkTopNKeywords(c *ruleIssueCollector, rule config.SectionRule, req listing.Requirement, text, label string) {
+	kc := rule.Constraints.MustContainTopNKeywords
+	terms := keywordSourceTerms(kc, req)
+	topN := kc.Value
+	if topN > len(terms) {
+		topN = len(terms)
+	}
+	mode := kc.MatchMode()
+	hard := rule.EffectiveHard(kc.IsHard())
+	for i := 0; i < topN; i++ {
+		kw := strings.TrimSpace(terms[i])
+		if kw == "" {
+			continue
+		}
+		if !keywordMatches(text, kw, mode) {
+			c.add("must_contain_top_n_keywords", hard, fmt.Sprintf("%s缺少关键词 #%d: %s", label, i+1, kw))
 		}
 	}
-	return dedupeIssues(issues), dedupeIssues(warnings)
 }
 
 type charBounds struct {
@@ -1171,6 +1432,10 @@ type charBounds struct {
 	tolMax  int
 	hasMin  bool
 	hasMax  bool
+	// count 为计数口径（空值按 runes）；softMin/softMax 表示对应一侧为软约束。
+	count   string
+	softMin bool
+	softMax bool
 }
 
 func resolveCharBounds(minConstraint, maxConstraint, tolerance int) charBounds {
@@ -1347,12 +1612,7 @@ func splitBySeparator(line, sep string) []string {
 }
 
 func parseParagraphs(text string, expected int) ([]string, error) {
-	text = strings.ReplaceAll(text, "\r\n", "\n")
-	chunks := splitByBlankLines(text)
-	if len(chunks) != expected {
-		return nil, fmt.Errorf("描述段落数量错误：%d != %d", len(chunks), expected)
-	}
-	return chunks, nil
+	return parseParagraphsBySeparator(text, expected, "")
 }
 
 func splitByBlankLines(text string) []string {
@@ -1458,27 +1718,28 @@ func normalizeLineByBounds(line string, bounds charBounds, keywords []string) st
 	if line == "" || !bounds.hasRule() {
 		return line
 	}
-	if bounds.hasMax && bounds.tolMax > 0 && runeLen(line) > bounds.tolMax {
-		line = trimToMaxByWords(line, bounds.tolMax)
+	measure := bounds.measure
+	if bounds.hasMax && bounds.tolMax > 0 && measure(line) > bounds.tolMax {
+		line = trimToMaxByMeasure(line, bounds.tolMax, measure)
 	}
-	if bounds.hasMin && bounds.tolMin > 0 && runeLen(line) < bounds.tolMin {
-		line = padToMinByKeywords(line, bounds.tolMin, bounds.tolMax, keywords)
+	if bounds.hasMin && bounds.tolMin > 0 && measure(line) < bounds.tolMin {
+		line = padToMinByMeasure(line, bounds.tolMin, bounds.tolMax, keywords, measure)
 	}
 	return strings.TrimSpace(line)
 }
 
 func trimToMaxByWords(s string, maxRunes int) string {
+	return trimToMaxByMeasure(s, maxRunes, runeLen)
+}
+
+func trimToMaxByMeasure(s string, maxRunes int, measure func(string) int) string {
 	s = strings.TrimSpace(s)
-	if maxRunes <= 0 || runeLen(s) <= maxRunes {
+	if maxRunes <= 0 || measure(s) <= maxRunes {
 		return s
 	}
 	words := strings.Fields(s)
 	if len(words) == 0 {
-		rs := []rune(s)
-		if len(rs) > maxRunes {
-			return strings.TrimSpace(string(rs[:maxRunes]))
-		}
-		return s
+		return truncateByMeasure(s, maxRunes, measure)
 	}
 	out := ""
 	for _, w := range words {
@@ -1486,25 +1747,33 @@ func trimToMaxByWords(s string, maxRunes int) string {
 		if out != "" {
 			candidate = out + " " + w
 		}
-		if runeLen(candidate) > maxRunes {
+		if measure(candidate) > maxRunes {
 			break
 		}
 		out = candidate
 	}
 	if strings.TrimSpace(out) == "" {
-		rs := []rune(s)
-		if len(rs) > maxRunes {
-			out = string(rs[:maxRunes])
-		} else {
-			out = s
-		}
+		out = truncateByMeasure(s, maxRunes, measure)
 	}
 	return strings.TrimSpace(out)
 }
 
+// truncateByMeasure 按 rune 逐个截断，直到 measure 不超过上限；保证不切断 UTF-8 字符。
+func truncateByMeasure(s string, limit int, measure func(string) int) string {
+	rs := []rune(s)
+	for len(rs) > 0 && measure(string(rs)) > limit {
+		rs = rs[:len(rs)-1]
+	}
+	return strings.TrimSpace(string(rs))
+}
+
 func padToMinByKeywords(s string, minRunes, maxRunes int, keywords []string) string {
+	return padToMinByMeasure(s, minRunes, maxRunes, keywords, runeLen)
+}
+
+func padToMinByMeasure(s string, minRunes, maxRunes int, keywords []string, measure func(string) int) string {
 	s = strings.TrimSpace(s)
-	if minRunes <= 0 || runeLen(s) >= minRunes {
+	if minRunes <= 0 || measure(s) >= minRunes {
 		return s
 	}
 	addition := func(base, tail string) string {
@@ -1512,7 +1781,7 @@ func padToMinByKeywords(s string, minRunes, maxRunes int, keywords []string) str
 			return base
 		}
 		candidate := strings.TrimSpace(base + " " + strings.TrimSpace(tail))
-		if maxRunes > 0 && runeLen(candidate) > maxRunes {
+		if maxRunes > 0 && measure(candidate) > maxRunes {
 			return base
 		}
 		return candidate
@@ -1530,7 +1799,7 @@ func padToMinByKeywords(s string, minRunes, maxRunes int, keywords []string) str
 		if s == before {
 			continue
 		}
-		if runeLen(s) >= minRunes {
+		if measure(s) >= minRunes {
 			return s
 		}
 	}
@@ -1545,7 +1814,7 @@ func padToMinByKeywords(s string, minRunes, maxRunes int, keywords []string) str
 		if s == before {
 			continue
 		}
-		if runeLen(s) >= minRunes {
+		if measure(s) >= minRunes {
 			return s
 		}
 	}
@@ -1594,5 +1863,19 @@ func validateDocumentBySectionRules(lang string, req listing.Requirement, doc Li
 	if len(doc.DescriptionParagraphs) != rules.DescriptionParagraphs() {
 		return fmt.Errorf("描述段落数量错误")
 	}
+	for _, sec := range rules.Sections() {
+		if config.IsBuiltinSection(sec.Name) {
+			continue
+		}
+		items := doc.SectionItems(sec.Name)
+		if len(items) == 0 {
+			return fmt.Errorf("%s 为空", sec.Name)
+		}
+		for i, it := range items {
+			if strings.TrimSpace(it) == "" {
+				return fmt.Errorf("%s 第%d项为空", sec.Name, i+1)
+			}
+		}
+	}
 	return nil
 }
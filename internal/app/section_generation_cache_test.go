@@ -0,0 +1,69 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"syl-listing/internal/cache"
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+)
+
+func TestGenerateDocumentBySectionsReplaysFromCache(t *testing.T) {
+	upstream := newLLMTestServer()
+	defer upstream.Close()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		upstream.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	store, err := cache.New(t.TempDir(), cache.ModeReadWrite)
+	if err != nil {
+		t.Fatalf("cache.New error: %v", err)
+	}
+	client := llm.NewClient(10 * time.Second)
+	client.SetCache(store)
+
+	req := listing.Requirement{
+		SourcePath:      "/tmp/a.md",
+		BodyAfterMarker: "body",
+		Category:        "Cat",
+		Keywords:        []string{"alpha", "beta", "gamma"},
+	}
+	opts := sectionGenerateOptions{
+		Req:           req,
+		Lang:          "en",
+		CharTolerance: 20,
+		Provider:      "deepseek",
+		ProviderCfg:   config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		APIKey:        "k",
+		Rules:         testRules(),
+		MaxRetries:    1,
+		Client:        client,
+		Candidate:     1,
+	}
+	first, _, err := generateDocumentBySections(opts)
+	if err != nil {
+		t.Fatalf("first run error: %v", err)
+	}
+	warm := atomic.LoadInt32(&calls)
+	if warm == 0 {
+		t.Fatalf("expected upstream calls on cold cache")
+	}
+	second, _, err := generateDocumentBySections(opts)
+	if err != nil {
+		t.Fatalf("second run error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != warm {
+		t.Fatalf("expected no upstream calls on warm cache, got %d extra", got-warm)
+	}
+	if first.Title != second.Title || first.SearchTerms != second.SearchTerms {
+		t.Fatalf("cached replay differs: first=%+v second=%+v", first, second)
+	}
+}
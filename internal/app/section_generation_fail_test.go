@@ -1,6 +1,7 @@
 package app
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"syl-listing/internal/config"
 	"syl-listing/internal/listing"
 	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
 )
 
 func TestGenerateBulletsBatchJSONWithRetry_ParseThenRecover(t *testing.T) {
@@ -49,6 +51,44 @@ func TestGenerateBulletsBatchJSONWithRetry_ParseThenRecover(t *testing.T) {
 	}
 }
 
+func TestGenerateJSONLinesBatchWithRetry_RetryEventUsesStep(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			fmt.Fprint(w, `{"choices":[{"message":{"content":"not-json"}}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"{\"items\":[\"feature one enough\",\"feature two enough\",\"feature three enough\",\"feature four enough\",\"feature five enough\"]}"}}]}`)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger, _, err := logging.New(&buf, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := listing.Requirement{SourcePath: "/tmp/a.md", BodyAfterMarker: "body", Category: "Cat", Keywords: []string{"alpha"}}
+	_, _, err = generateJSONLinesBatchWithRetry(sectionGenerateOptions{
+		Req:         req,
+		Lang:        "en",
+		Provider:    "deepseek",
+		ProviderCfg: config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		APIKey:      "k",
+		Rules:       testRules(),
+		MaxRetries:  1,
+		Client:      llm.NewClient(10 * time.Second),
+		Logger:      logger,
+		Candidate:   1,
+	}, "features", ListingDocument{Category: "Cat", Keywords: req.Keywords}, testRules().Bullets, resolveCharBounds(10, 40, 0))
+	if err != nil {
+		t.Fatalf("expected recover after parse retry, got %v", err)
+	}
+	if !strings.Contains(buf.String(), `"event":"retry_backoff_features"`) || strings.Contains(buf.String(), "retry_backoff_bullets") {
+		t.Fatalf("retry event should carry the section step:\n%s", buf.String())
+	}
+}
+
 func TestRegenerateBulletItemJSONWithRetry_JSONParseFailure(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"choices":[{"message":{"content":"not-json"}}]}`)
@@ -103,7 +103,7 @@ func TestPromptBuilders(t *testing.T) {
 	if got := buildSectionSystemPrompt(config.SectionRuleFile{Raw: "  x  "}); got != "x" {
 		t.Fatalf("unexpected system prompt: %q", got)
 	}
-	up := buildSectionUserPrompt("title", req, doc)
+	up := buildSectionUserPrompt("title", req, doc, candidateDirection{}, promptExtras{})
 	if !strings.Contains(up, "【当前任务】生成：title") || !strings.Contains(up, "k1") {
 		t.Fatalf("unexpected user prompt: %s", up)
 	}
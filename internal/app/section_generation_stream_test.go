@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+	"syl-listing/internal/logging"
+)
+
+func TestGenerateSectionWithRetryStreamEarlyAbort(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		deltas := []string{"alpha beta short title"}
+		if n == 1 {
+			deltas = []string{strings.Repeat("x", 40), strings.Repeat("y", 40), strings.Repeat("z", 400)}
+		}
+		for _, d := range deltas {
+			raw, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]any{"content": d}}}})
+			fmt.Fprintf(w, "data: %s\n\n", raw)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	logger, _, err := logging.New(&logs, "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := testRules()
+	title := rules.Title
+	title.Parsed.Constraints.MaxChars.Value = 50
+	rules.Title = title
+	req := listing.Requirement{SourcePath: "/tmp/a.md", BodyAfterMarker: "body", Category: "Cat", Keywords: []string{"alpha", "beta"}}
+	text, _, err := generateSectionWithRetry(sectionGenerateOptions{
+		Req:      req,
+		Lang:     "en",
+		Provider: "deepseek",
+		ProviderCfg: config.ProviderConfig{
+			BaseURL:          ts.URL,
+			Model:            "deepseek-chat",
+			Stream:           true,
+			StreamEarlyAbort: true,
+		},
+		APIKey:        "k",
+		Rules:         rules,
+		MaxRetries:    1,
+		Client:        llm.NewClient(10 * time.Second),
+		Logger:        logger,
+		Candidate:     1,
+		CharTolerance: 10,
+	}, "title", ListingDocument{Category: "Cat", Keywords: req.Keywords})
+	if err != nil {
+		t.Fatalf("unexpected err: %v\nlogs:\n%s", err, logs.String())
+	}
+	if text != "alpha beta short title" {
+		t.Fatalf("unexpected title: %q", text)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+	out := logs.String()
+	if !strings.Contains(out, "api_stream_title") || !strings.Contains(out, "已提前中止") {
+		t.Fatalf("expected stream chunk and abort logs, got:\n%s", out)
+	}
+}
+
+func TestStreamAbortLimit(t *testing.T) {
+	rules := testRules()
+	opts := sectionGenerateOptions{CharTolerance: 20, ProviderCfg: config.ProviderConfig{Stream: true, StreamEarlyAbort: true}}
+	if got := streamAbortLimit(opts, rules.Title); got != 330 {
+		t.Fatalf("title abort limit mismatch: %d", got)
+	}
+	if got := streamAbortLimit(opts, rules.Bullets); got != 0 {
+		t.Fatalf("bullets should not abort early: %d", got)
+	}
+	opts.ProviderCfg.StreamEarlyAbort = false
+	if got := streamAbortLimit(opts, rules.Title); got != 0 {
+		t.Fatalf("abort disabled should return 0: %d", got)
+	}
+}
@@ -0,0 +1,270 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"syl-listing/internal/config"
+)
+
+// allCapsMinLetters 是判定全大写单词的最少字母数；更短的多为尺码或缩写（XL、USB）。
+const allCapsMinLetters = 4
+
+// stylePolicy 是解析后的亚马逊风格策略，只作用于英文标题与五点；nil 表示关闭。
+// 删除禁用字符与 emoji、整理空白、调整大小写属于安全修正，本地直接完成；
+// 全大写单词、单词重复、促销用语需要改写，作为问题交给模型修复。
+type stylePolicy struct {
+	maxWordRepeat  int
+	smallWords     map[string]bool
+	allowedAllCaps map[string]bool
+	promoPhrases   []string
+	promoRes       []*regexp.Regexp
+	title          styleSectionPolicy
+	bullets        styleSectionPolicy
+}
+
+type styleSectionPolicy struct {
+	disallowed   map[rune]bool
+	checkAllCaps bool
+}
+
+func newStylePolicy(cfg config.StyleConfig) *stylePolicy {
+	if !cfg.IsEnabled() {
+		return nil
+	}
+	p := &stylePolicy{
+		maxWordRepeat:  cfg.MaxWordRepeat,
+		smallWords:     map[string]bool{},
+		allowedAllCaps: map[string]bool{},
+		title:          newStyleSectionPolicy(cfg.Title),
+		bullets:        newStyleSectionPolicy(cfg.Bullets),
+	}
+	for _, w := range cfg.SmallWords {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			p.smallWords[w] = true
+		}
+	}
+	for _, w := range cfg.AllowedAllCaps {
+		if w = strings.ToUpper(strings.TrimSpace(w)); w != "" {
+			p.allowedAllCaps[w] = true
+		}
+	}
+	for _, phrase := range cfg.PromotionalPhrases {
+		phrase = strings.TrimSpace(phrase)
+		if phrase == "" {
+			continue
+		}
+		re, err := regexp.Compile(`(?i)(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(phrase) + `($|[^\p{L}\p{N}])`)
+		if err != nil {
+			continue
+		}
+		p.promoPhrases = append(p.promoPhrases, phrase)
+		p.promoRes = append(p.promoRes, re)
+	}
+	return p
+}
+
+func newStyleSectionPolicy(cfg config.StyleSectionConfig) styleSectionPolicy {
+	out := styleSectionPolicy{disallowed: map[rune]bool{}, checkAllCaps: cfg.AllCapsChecked()}
+	for _, r := range cfg.DisallowedChars {
+		if !unicode.IsSpace(r) {
+			out.disallowed[r] = true
+		}
+	}
+	return out
+}
+
+func (p *stylePolicy) covers(step, lang string) bool {
+	return p != nil && lang == "en" && (step == "title" || step == "bullets")
+}
+
+func (p *stylePolicy) section(step string) styleSectionPolicy {
+	if step == "title" {
+		return p.title
+	}
+	return p.bullets
+}
+
+// fixItem 对单条标题或五点做安全修正；不适用的分段原样返回。
+func (p *stylePolicy) fixItem(step, lang, line string) string {
+	if !p.covers(step, lang) {
+		return line
+	}
+	sec := p.section(step)
+	line = strings.Map(func(r rune) rune {
+		if sec.disallowed[r] || isEmojiRune(r) {
+			return ' '
+		}
+		return r
+	}, line)
+	line = tidyStyleSpaces(line)
+	if step == "title" {
+		return p.titleCase(line)
+	}
+	return capitalizeFirstLetter(line)
+}
+
+// itemIssues 返回单条标题或五点中无法本地修正的风格问题。
+func (p *stylePolicy) itemIssues(step, lang string, idx int, line string) []string {
+	if !p.covers(step, lang) {
+		return nil
+	}
+	label := "标题"
+	if step == "bullets" {
+		label = fmt.Sprintf("第%d条", idx)
+	}
+	issues := make([]string, 0, 3)
+	if p.section(step).checkAllCaps {
+		if words := p.allCapsWords(line); len(words) > 0 {
+			issues = append(issues, fmt.Sprintf("%s包含全大写单词：%s（改为首字母大写；缩写可加入 style.allowed_all_caps）", label, strings.Join(words, "、")))
+		}
+	}
+	if step == "title" && p.maxWordRepeat > 0 {
+		for _, w := range p.repeatedWords(line) {
+			issues = append(issues, fmt.Sprintf("%s中单词“%s”出现超过 %d 次", label, w, p.maxWordRepeat))
+		}
+	}
+	if phrases := p.promotionalHits(line); len(phrases) > 0 {
+		issues = append(issues, fmt.Sprintf("%s包含促销用语：%s", label, strings.Join(phrases, "、")))
+	}
+	return issues
+}
+
+// applySectionText 对文本协议返回的标题或五点逐条修正并收集问题；拆分失败时原样返回，交给规则校验报错。
+func (p *stylePolicy) applySectionText(step, lang string, rule config.SectionRuleFile, text string) (string, []string) {
+	if !p.covers(step, lang) {
+		return text, nil
+	}
+	if step == "title" {
+		line := p.fixItem(step, lang, cleanTitleLine(text))
+		return line, p.itemIssues(step, lang, 1, line)
+	}
+	items, err := splitSectionOutput(step, rule, text)
+	if err != nil {
+		return text, nil
+	}
+	issues := make([]string, 0, len(items))
+	for i := range items {
+		items[i] = p.fixItem(step, lang, items[i])
+		issues = append(issues, p.itemIssues(step, lang, i+1, items[i])...)
+	}
+	return strings.Join(items, "\n"), issues
+}
+
+// titleCase 把全小写单词改为首字母大写，虚词（句首与分隔符后除外）保持小写；大小写混排的词（iPhone、USB）不动。
+func (p *stylePolicy) titleCase(line string) string {
+	words := strings.Fields(line)
+	for i, w := range words {
+		start := i == 0
+		if !start {
+			last, _ := utf8.DecodeLastRuneInString(words[i-1])
+			start = strings.ContainsRune(":|-–—", last)
+		}
+		words[i] = p.titleCaseWord(w, start)
+	}
+	return strings.Join(words, " ")
+}
+
+func (p *stylePolicy) titleCaseWord(w string, start bool) string {
+	core := strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	if core == "" {
+		return w
+	}
+	lower := strings.ToLower(core)
+	if !start && p.smallWords[lower] && (core == lower || core == capitalizeFirstLetter(lower)) {
+		return strings.Replace(w, core, lower, 1)
+	}
+	if core != lower {
+		return w
+	}
+	parts := strings.Split(core, "-")
+	for i, part := range parts {
+		parts[i] = capitalizeFirstLetter(part)
+	}
+	return strings.Replace(w, core, strings.Join(parts, "-"), 1)
+}
+
+func (p *stylePolicy) allCapsWords(line string) []string {
+	out := make([]string, 0, 2)
+	seen := map[string]bool{}
+	for _, w := range strings.FieldsFunc(line, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(w)) < allCapsMinLetters || strings.ToUpper(w) != w || strings.ToLower(w) == w {
+			continue
+		}
+		if p.allowedAllCaps[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+	}
+	return out
+}
+
+// repeatedWords 按首次出现顺序返回超过重复上限的单词；虚词与纯数字不计。
+func (p *stylePolicy) repeatedWords(line string) []string {
+	counts := map[string]int{}
+	order := make([]string, 0, 8)
+	for _, w := range searchTermTokens(line) {
+		if p.smallWords[w] || strings.TrimFunc(w, unicode.IsDigit) == "" {
+			continue
+		}
+		if counts[w] == 0 {
+			order = append(order, w)
+		}
+		counts[w]++
+	}
+	out := make([]string, 0, 1)
+	for _, w := range order {
+		if counts[w] > p.maxWordRepeat {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func (p *stylePolicy) promotionalHits(line string) []string {
+	out := make([]string, 0, 1)
+	for i, re := range p.promoRes {
+		if re.MatchString(line) {
+			out = append(out, p.promoPhrases[i])
+		}
+	}
+	return out
+}
+
+var styleSpaceBeforePunctRe = regexp.MustCompile(`\s+([,.;:])`)
+
+func tidyStyleSpaces(line string) string {
+	line = strings.Join(strings.Fields(line), " ")
+	return styleSpaceBeforePunctRe.ReplaceAllString(line, "$1")
+}
+
+func capitalizeFirstLetter(s string) string {
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			return s[:i] + string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+		}
+		if unicode.IsDigit(r) {
+			return s
+		}
+	}
+	return s
+}
+
+// isEmojiRune 覆盖常见 emoji 区段、杂项符号与变体选择符。
+func isEmojiRune(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case r >= 0x2600 && r <= 0x27BF:
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF:
+		return true
+	case r == 0xFE0F || r == 0x200D || r == 0x20E3:
+		return true
+	}
+	return false
+}
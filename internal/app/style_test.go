@@ -0,0 +1,139 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/listing"
+	"syl-listing/internal/llm"
+)
+
+func testStylePolicy(t *testing.T) *stylePolicy {
+	t.Helper()
+	p := newStylePolicy(config.DefaultStyleConfig())
+	if p == nil {
+		t.Fatalf("style policy should be enabled by default")
+	}
+	return p
+}
+
+func TestStyleFixTitle(t *testing.T) {
+	p := testStylePolicy(t)
+	cases := map[string]string{
+		"brandx storage box with lids for closet!":       "Brandx Storage Box with Lids for Closet",
+		"  BrandX   non-slip mat , 2 pack 🔥 {large}  ":   "BrandX Non-Slip Mat, 2 Pack Large",
+		"BrandX Organizer For iPhone - the Travel Case?": "BrandX Organizer for iPhone - The Travel Case",
+		"10x13 inch dry erase pockets_set":               "10x13 Inch Dry Erase Pockets Set",
+	}
+	for in, want := range cases {
+		if got := p.fixItem("title", "en", in); got != want {
+			t.Fatalf("fixItem(%q)=%q want %q", in, got, want)
+		}
+	}
+	if got := p.fixItem("title", "cn", "标题！"); got != "标题！" {
+		t.Fatalf("cn title should be untouched: %q", got)
+	}
+	if got := p.fixItem("description", "en", "x!"); got != "x!" {
+		t.Fatalf("description should be untouched: %q", got)
+	}
+}
+
+func TestStyleFixBullet(t *testing.T) {
+	p := testStylePolicy(t)
+	got := p.fixItem("bullets", "en", "durable material™: made with ~ thick plastic ✅")
+	if got != "Durable material: made with thick plastic" {
+		t.Fatalf("unexpected bullet fix: %q", got)
+	}
+	if got := p.fixItem("bullets", "en", "Great value!"); got != "Great value!" {
+		t.Fatalf("bullets keep title-only disallowed chars: %q", got)
+	}
+}
+
+func TestStyleItemIssues(t *testing.T) {
+	p := testStylePolicy(t)
+	issues := p.itemIssues("title", "en", 1, "BrandX Box Box Box for Box - FREE Shipping Best Seller HDMI")
+	joined := strings.Join(issues, "; ")
+	for _, want := range []string{"全大写单词：FREE", "“box”出现超过 2 次", "促销用语：free shipping、best seller"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("missing %q in %v", want, issues)
+		}
+	}
+	if strings.Contains(joined, "HDMI") {
+		t.Fatalf("allowed acronym should pass: %v", issues)
+	}
+	if issues := p.itemIssues("title", "en", 1, "BrandX Box for the Kids and the Adults in the Home"); len(issues) != 0 {
+		t.Fatalf("small words should not count as repeats: %v", issues)
+	}
+	issues = p.itemIssues("bullets", "en", 3, "Wholesale Pack: BEST value, box box box")
+	if len(issues) != 1 || !strings.HasPrefix(issues[0], "第3条包含全大写单词：BEST") {
+		t.Fatalf("bullets only check caps and promo: %v", issues)
+	}
+
+	off := false
+	if newStylePolicy(config.StyleConfig{Enabled: &off}) != nil {
+		t.Fatalf("disabled policy should be nil")
+	}
+	var nilPolicy *stylePolicy
+	if nilPolicy.fixItem("title", "en", "a!") != "a!" || nilPolicy.itemIssues("title", "en", 1, "FREE") != nil {
+		t.Fatalf("nil policy should be a no-op")
+	}
+}
+
+func TestValidateLineSetWithChecksMarksStyleIssues(t *testing.T) {
+	opts := sectionGenerateOptions{Lang: "en", Style: testStylePolicy(t)}
+	bounds := resolveCharBounds(0, 40, 0)
+	items := []string{"Fine line", strings.Repeat("x", 50), "Top Rated pick"}
+	invalid, issues, _ := validateLineSetWithChecks(opts, "bullets", items, bounds)
+	if len(invalid) != 2 || invalid[0] != 2 || invalid[1] != 3 {
+		t.Fatalf("unexpected invalid indexes: %v issues=%v", invalid, issues)
+	}
+}
+
+func TestGenerateSectionWithRetryRepairsStyleIssues(t *testing.T) {
+	var calls int
+	var repairPrompt string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		calls++
+		text := "alpha beta organizer FREE shipping!"
+		if calls > 1 {
+			repairPrompt = body.Messages[len(body.Messages)-1].Content
+			text = "alpha beta organizer with lids!"
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, text)
+	}))
+	defer ts.Close()
+	req := listing.Requirement{SourcePath: "/tmp/a.md", Category: "Cat", Keywords: []string{"alpha", "beta"}}
+	text, _, err := generateSectionWithRetry(sectionGenerateOptions{
+		Req:         req,
+		Lang:        "en",
+		Provider:    "deepseek",
+		ProviderCfg: config.ProviderConfig{BaseURL: ts.URL, APIMode: "chat", Model: "deepseek-chat"},
+		APIKey:      "k",
+		Rules:       testRules(),
+		MaxRetries:  1,
+		Client:      llm.NewClient(10 * time.Second),
+		Candidate:   1,
+		Style:       testStylePolicy(t),
+	}, "title", ListingDocument{Category: "Cat", Keywords: req.Keywords})
+	if err != nil {
+		t.Fatalf("generate title: %v", err)
+	}
+	if text != "Alpha Beta Organizer with Lids" {
+		t.Fatalf("title should be auto-fixed: %q", text)
+	}
+	if !strings.Contains(repairPrompt, "全大写单词：FREE") || !strings.Contains(repairPrompt, "促销用语：free shipping") {
+		t.Fatalf("style issues should reach repair prompt: %q", repairPrompt)
+	}
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/logging"
+	"syl-listing/internal/output"
+	"syl-listing/internal/tm"
+)
+
+// openTranslationMemory 打开本次运行的翻译记忆；关闭、mock 翻译或记忆文件损坏时返回 nil（损坏只告警，不阻断生成）。
+func openTranslationMemory(cfg *config.Config, paths *config.Paths, translateProvider string, logger *logging.Logger) *tm.Memory {
+	if !cfg.TranslationMemory.IsEnabled() || isMockProvider(translateProvider) {
+		return nil
+	}
+	memory, err := tm.Open(paths.TMPath, tm.Options{
+		FuzzyMinScore: cfg.TranslationMemory.FuzzyMinScore,
+		FuzzyHints:    cfg.TranslationMemory.FuzzyHints,
+	})
+	if err != nil {
+		logger.Emit(logging.Event{Level: "warn", Event: "tm_unavailable", Error: err.Error()})
+		return nil
+	}
+	logger.Emit(logging.Event{Event: "tm_loaded", OutputFile: memory.Path(), Attempt: memory.Len()})
+	return memory
+}
+
+// recordTranslationMemory 把人工确认（review 保存、library add）的英中对照记入翻译记忆：分类、关键词与各分段逐条译文。
+// 按中文规则直接生成（native）的分段不是译文，不记录。
+func recordTranslationMemory(memory *tm.Memory, rules config.SectionRules, enDoc, cnDoc ListingDocument) int {
+	if memory == nil {
+		return 0
+	}
+	n := 0
+	add := func(en, cn string) {
+		if _, changed := memory.Add(tm.Entry{SourceLang: "en", TargetLang: "zh", Source: en, Target: cn}); changed {
+			n++
+		}
+	}
+	add(enDoc.Category, cnDoc.Category)
+	if len(enDoc.Keywords) == len(cnDoc.Keywords) {
+		for i := range enDoc.Keywords {
+			add(enDoc.Keywords[i], cnDoc.Keywords[i])
+		}
+	}
+	for _, sec := range rules.Sections() {
+		if rule, ok := rules.CNRule(sec.Name); ok && rule.Parsed.CNMode() == config.CNModeNative {
+			continue
+		}
+		enItems, cnItems := enDoc.SectionItems(sec.Name), cnDoc.SectionItems(sec.Name)
+		if len(enItems) != len(cnItems) {
+			continue
+		}
+		for i := range enItems {
+			add(enItems[i], cnItems[i])
+		}
+	}
+	return n
+}
+
+// saveTranslationMemory 在运行结束时写回翻译记忆。
+func saveTranslationMemory(memory *tm.Memory, logger *logging.Logger) {
+	if memory == nil {
+		return
+	}
+	if err := memory.Save(); err != nil {
+		logger.Emit(logging.Event{Level: "warn", Event: "tm_save_failed", OutputFile: memory.Path(), Error: err.Error()})
+		return
+	}
+	logger.Emit(logging.Event{Event: "tm_saved", OutputFile: memory.Path(), Attempt: memory.Len()})
+}
+
+// TMOptions 是 tm export/import 子命令的参数。
+type TMOptions struct {
+	Context    context.Context
+	ConfigPath string
+	// File 为导出目标或导入来源，相对路径按 CWD 解析。
+	File string
+	// Format 为 tmx 或 csv；为空时按 File 扩展名判断。
+	Format string
+	CWD    string
+	Stdout io.Writer
+}
+
+// ExportTM 把本地翻译记忆导出为 TMX 或 CSV。
+func ExportTM(opts TMOptions) error {
+	if opts.Stdout == nil {
+		opts.Stdout = io.Discard
+	}
+	memory, format, target, err := prepareTM(opts)
+	if err != nil {
+		return err
+	}
+	entries := memory.Entries()
+	var buf bytes.Buffer
+	if err := tm.Export(&buf, format, entries); err != nil {
+		return err
+	}
+	if err := output.WriteFilesAtomic(opts.Context, output.File{Path: target, Data: buf.Bytes()}); err != nil {
+		return fmt.Errorf("写出翻译记忆失败：%w", err)
+	}
+	fmt.Fprintf(opts.Stdout, "已导出 %d 条翻译记忆：%s\n", len(entries), target)
+	return nil
+}
+
+// ImportTM 把 TMX 或 CSV 合并进本地翻译记忆；同一原文以导入的译文为准。
+func ImportTM(opts TMOptions) error {
+	if opts.Stdout == nil {
+		opts.Stdout = io.Discard
+	}
+	memory, format, source, err := prepareTM(opts)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("读取翻译记忆文件失败：%w", err)
+	}
+	defer f.Close()
+	entries, err := tm.Import(f, format)
+	if err != nil {
+		return err
+	}
+	added, updated, skipped := memory.Merge(entries)
+	if err := memory.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Stdout, "已导入 %s：新增 %d 条，更新 %d 条，跳过 %d 条，共 %d 条\n", source, added, updated, skipped, memory.Len())
+	return nil
+}
+
+func prepareTM(opts TMOptions) (*tm.Memory, string, string, error) {
+	if strings.TrimSpace(opts.File) == "" {
+		return nil, "", "", fmt.Errorf("翻译记忆文件路径为空")
+	}
+	format, err := tm.ResolveFormat(opts.Format, opts.File)
+	if err != nil {
+		return nil, "", "", err
+	}
+	_, paths, err := config.Load(opts.ConfigPath, opts.CWD)
+	if err != nil {
+		return nil, "", "", err
+	}
+	memory, err := tm.Open(paths.TMPath, tm.Options{})
+	if err != nil {
+		return nil, "", "", err
+	}
+	return memory, format, absPath(opts.CWD, opts.File), nil
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"syl-listing/internal/config"
+	"syl-listing/internal/tm"
+)
+
+func TestRecordTranslationMemory(t *testing.T) {
+	memory, err := tm.Open(filepath.Join(t.TempDir(), "tm.json"), tm.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	en, cn := composeFixtureDocs()
+	en.Category, cn.Category = "Cat", "分类"
+	en.Keywords, cn.Keywords = []string{"alpha", "beta"}, []string{"阿尔法", "贝塔"}
+	rules := testRules()
+	rules.CN = map[string]config.SectionRuleFile{"description": {Parsed: config.SectionRule{Mode: config.CNModeNative}}}
+	n := recordTranslationMemory(memory, rules, en, cn)
+	if want := 3 + 1 + 5 + 1; n != want {
+		t.Fatalf("expected %d recorded pairs, got %d", want, n)
+	}
+	if got, ok := memory.Lookup("en", "zh", en.BulletPoints[2]); !ok || got != cn.BulletPoints[2] {
+		t.Fatalf("bullet pair missing: %q %v", got, ok)
+	}
+	if _, ok := memory.Lookup("en", "zh", en.DescriptionParagraphs[0]); ok {
+		t.Fatalf("native sections are not translations and must not be recorded")
+	}
+	if n := recordTranslationMemory(memory, rules, en, cn); n != 0 {
+		t.Fatalf("unchanged pairs should not count again, got %d", n)
+	}
+	if recordTranslationMemory(nil, rules, en, cn) != 0 {
+		t.Fatalf("nil memory should be a no-op")
+	}
+}
+
+func TestExportImportTM(t *testing.T) {
+	cfgPath, workDir := setupRunFixture(t, "")
+	_, paths, err := config.Load(cfgPath, workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory, err := tm.Open(paths.TMPath, tm.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory.Add(tm.Entry{SourceLang: "en", TargetLang: "zh", Source: "alpha", Target: "阿尔法"})
+	if err := memory.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := ExportTM(TMOptions{ConfigPath: cfgPath, File: "team.tmx", CWD: workDir, Stdout: &out}); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(workDir, "team.tmx"))
+	if err != nil || !strings.Contains(string(raw), `<seg>阿尔法</seg>`) || !strings.Contains(out.String(), "已导出 1 条") {
+		t.Fatalf("unexpected export: %s / %s %v", raw, out.String(), err)
+	}
+
+	csvPath := filepath.Join(workDir, "shared.csv")
+	if err := os.WriteFile(csvPath, []byte("source_lang,target_lang,source,target\nen,zh,alpha,甲\nen,zh-CN,beta,乙\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := ImportTM(TMOptions{ConfigPath: cfgPath, File: "shared.csv", CWD: workDir, Stdout: &out}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "新增 1 条，更新 1 条，跳过 0 条，共 2 条") {
+		t.Fatalf("unexpected import summary: %s", out.String())
+	}
+	reopened, err := tm.Open(paths.TMPath, tm.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.Lookup("en", "zh", "alpha"); got != "甲" {
+		t.Fatalf("imported translation should win, got %q", got)
+	}
+	if err := ExportTM(TMOptions{ConfigPath: cfgPath, File: "team.json", CWD: workDir}); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Mode string
+
+const (
+	ModeOff       Mode = "off"
+	ModeRead      Mode = "read"
+	ModeWrite     Mode = "write"
+	ModeReadWrite Mode = "readwrite"
+)
+
+func ParseMode(v string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "off", "none":
+		return ModeOff, nil
+	case "read", "r":
+		return ModeRead, nil
+	case "write", "w":
+		return ModeWrite, nil
+	case "readwrite", "rw", "read_write", "read-write":
+		return ModeReadWrite, nil
+	default:
+		return ModeOff, fmt.Errorf("cache 模式仅支持 off/read/write/readwrite：%s", v)
+	}
+}
+
+func (m Mode) CanRead() bool {
+	return m == ModeRead || m == ModeReadWrite
+}
+
+func (m Mode) CanWrite() bool {
+	return m == ModeWrite || m == ModeReadWrite
+}
+
+// Store 是按请求内容哈希寻址的磁盘缓存；nil Store 等价于 off。
+type Store struct {
+	dir  string
+	mode Mode
+}
+
+type entry struct {
+	Namespace string          `json:"namespace"`
+	Key       string          `json:"key"`
+	Material  json.RawMessage `json:"material"`
+	Text      string          `json:"text"`
+	CreatedAt string          `json:"created_at"`
+}
+
+func New(dir string, mode Mode) (*Store, error) {
+	if mode == ModeOff {
+		return nil, nil
+	}
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("缓存目录为空")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败（%s）：%w", dir, err)
+	}
+	return &Store{dir: dir, mode: mode}, nil
+}
+
+func (s *Store) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+func (s *Store) Mode() Mode {
+	if s == nil {
+		return ModeOff
+	}
+	return s.mode
+}
+
+// Key 对 namespace 与请求要素做稳定哈希。material 需可 JSON 编码，且不应包含密钥。
+func Key(namespace string, material any) (string, []byte, error) {
+	raw, err := json.Marshal(material)
+	if err != nil {
+		return "", nil, fmt.Errorf("编码缓存键失败：%w", err)
+	}
+	h := sha256.New()
+	h.Write([]byte(namespace))
+	h.Write([]byte{0})
+	h.Write(raw)
+	return hex.EncodeToString(h.Sum(nil)), raw, nil
+}
+
+func (s *Store) Lookup(namespace string, material any) (string, bool) {
+	if s == nil || !s.mode.CanRead() {
+		return "", false
+	}
+	key, _, err := Key(namespace, material)
+	if err != nil {
+		return "", false
+	}
+	raw, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return "", false
+	}
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil || e.Key != key {
+		return "", false
+	}
+	return e.Text, true
+}
+
+func (s *Store) Save(namespace string, material any, text string) error {
+	if s == nil || !s.mode.CanWrite() {
+		return nil
+	}
+	key, materialRaw, err := Key(namespace, material)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(entry{
+		Namespace: namespace,
+		Key:       key,
+		Material:  materialRaw,
+		Text:      text,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码缓存条目失败：%w", err)
+	}
+	target := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("创建缓存目录失败：%w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), key+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("写入缓存失败：%w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存失败：%w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存失败：%w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存失败：%w", err)
+	}
+	return nil
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key+".json")
+}
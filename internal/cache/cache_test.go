@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	cases := map[string]Mode{
+		"":          ModeOff,
+		"off":       ModeOff,
+		"READ":      ModeRead,
+		"write":     ModeWrite,
+		"readwrite": ModeReadWrite,
+		"rw":        ModeReadWrite,
+	}
+	for in, want := range cases {
+		got, err := ParseMode(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMode(%q)=%q,%v want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMode("bogus"); err == nil {
+		t.Fatalf("expected error for bogus mode")
+	}
+	if !ModeReadWrite.CanRead() || !ModeReadWrite.CanWrite() || ModeRead.CanWrite() || ModeWrite.CanRead() {
+		t.Fatalf("mode capability mismatch")
+	}
+}
+
+func TestStoreRoundTripAndModes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "responses")
+	material := map[string]any{"model": "m", "messages": []string{"a", "b"}}
+
+	rw, err := New(dir, ModeReadWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rw.Lookup("llm", material); ok {
+		t.Fatalf("expected miss on empty cache")
+	}
+	if err := rw.Save("llm", material, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := rw.Lookup("llm", material); !ok || got != "hello" {
+		t.Fatalf("expected hit, got %q %v", got, ok)
+	}
+	if _, ok := rw.Lookup("translate", material); ok {
+		t.Fatalf("namespace must be part of the key")
+	}
+
+	ro, _ := New(dir, ModeRead)
+	if err := ro.Save("llm", map[string]any{"x": 1}, "ignored"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ro.Lookup("llm", map[string]any{"x": 1}); ok {
+		t.Fatalf("read-only store must not write")
+	}
+	wo, _ := New(dir, ModeWrite)
+	if _, ok := wo.Lookup("llm", material); ok {
+		t.Fatalf("write-only store must not read")
+	}
+
+	off, err := New(dir, ModeOff)
+	if err != nil || off != nil {
+		t.Fatalf("off mode should return nil store: %v %v", off, err)
+	}
+	if _, ok := off.Lookup("llm", material); ok || off.Mode() != ModeOff || off.Dir() != "" {
+		t.Fatalf("nil store should behave as off")
+	}
+	if _, err := New(" ", ModeRead); err == nil {
+		t.Fatalf("expected empty dir error")
+	}
+}
@@ -0,0 +1,378 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const formatVersion = 1
+
+type MatchMode string
+
+const (
+	// MatchRequest 按 method + path + query + body 匹配，同一请求多次出现时按录制顺序依次返回。
+	MatchRequest MatchMode = "request"
+	// MatchOrder 严格按录制顺序返回，仅校验 method 与 path。
+	MatchOrder MatchMode = "order"
+)
+
+func ParseMatchMode(v string) (MatchMode, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "request", "req":
+		return MatchRequest, nil
+	case "order", "ordered", "sequence":
+		return MatchOrder, nil
+	default:
+		return MatchRequest, fmt.Errorf("回放匹配模式仅支持 request/order：%s", v)
+	}
+}
+
+// Cassette 是一次运行中全部 HTTP 往返的录像。
+type Cassette struct {
+	Version      int           `json:"version"`
+	RecordedAt   string        `json:"recorded_at,omitempty"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	BodyBase64 string            `json:"body_base64,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status     int               `json:"status"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	BodyBase64 string            `json:"body_base64,omitempty"`
+}
+
+// 录制时剔除的敏感请求头与 query 参数，录像文件可直接提交到仓库。
+var redactedHeaders = map[string]bool{
+	"authorization":  true,
+	"x-api-key":      true,
+	"x-goog-api-key": true,
+	"cookie":         true,
+}
+
+var redactedQuery = []string{"key", "api_key", "access_token"}
+
+func Load(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取录像失败（%s）：%w", path, err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("解析录像失败（%s）：%w", path, err)
+	}
+	if c.Version != formatVersion {
+		return nil, fmt.Errorf("录像版本不支持（%s）：%d", path, c.Version)
+	}
+	return &c, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码录像失败：%w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建录像目录失败：%w", err)
+	}
+	if err := os.WriteFile(path, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("写入录像失败（%s）：%w", path, err)
+	}
+	return nil
+}
+
+// Recorder 透传请求到 Inner，并记录每次往返。
+type Recorder struct {
+	Inner http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+func NewRecorder(inner http.RoundTripper) *Recorder {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return &Recorder{Inner: inner}
+}
+
+// RoundTrip 按请求顺序占位；响应体在读完或关闭时才写入录像，流式输出照常逐块到达。
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := drainRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL),
+			Headers: flattenHeaders(req.Header, true),
+		},
+		Response: RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: flattenHeaders(resp.Header, false),
+		},
+	}
+	in.Request.Body, in.Request.BodyBase64 = encodeBody(reqBody)
+
+	r.mu.Lock()
+	idx := len(r.interactions)
+	r.interactions = append(r.interactions, in)
+	r.mu.Unlock()
+	resp.Body = &recordingBody{ReadCloser: resp.Body, done: func(body []byte) {
+		r.mu.Lock()
+		r.interactions[idx].Response.Body, r.interactions[idx].Response.BodyBase64 = encodeBody(body)
+		r.mu.Unlock()
+	}}
+	return resp, nil
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish() {
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+}
+
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Interaction, len(r.interactions))
+	copy(out, r.interactions)
+	return &Cassette{
+		Version:      formatVersion,
+		RecordedAt:   time.Now().Format(time.RFC3339),
+		Interactions: out,
+	}
+}
+
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// Replayer 从录像返回响应，不访问网络。
+type Replayer struct {
+	mode MatchMode
+
+	mu   sync.Mutex
+	list []Interaction
+	used []bool
+	next int
+}
+
+func NewReplayer(c *Cassette, mode MatchMode) *Replayer {
+	if mode == "" {
+		mode = MatchRequest
+	}
+	list := []Interaction{}
+	if c != nil {
+		list = c.Interactions
+	}
+	return &Replayer{mode: mode, list: list, used: make([]bool, len(list))}
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := drainRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := -1
+	switch r.mode {
+	case MatchOrder:
+		if r.next < len(r.list) {
+			cand := r.list[r.next]
+			if strings.EqualFold(cand.Request.Method, req.Method) && recordedPath(cand.Request.URL) == req.URL.Path {
+				idx = r.next
+			} else {
+				return nil, fmt.Errorf("回放顺序不一致：第 %d 条期望 %s %s，实际 %s %s", r.next+1, cand.Request.Method, recordedPath(cand.Request.URL), req.Method, req.URL.Path)
+			}
+		}
+	default:
+		key := matchKey(req.Method, redactURL(req.URL), body)
+		for i, in := range r.list {
+			if r.used[i] {
+				continue
+			}
+			recBody, err := decodeBody(in.Request.Body, in.Request.BodyBase64)
+			if err != nil {
+				return nil, err
+			}
+			if matchKey(in.Request.Method, in.Request.URL, recBody) == key {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("回放中未找到匹配请求：%s %s", req.Method, req.URL.Path)
+	}
+	r.used[idx] = true
+	r.next = idx + 1
+
+	rec := r.list[idx].Response
+	respBody, err := decodeBody(rec.Body, rec.BodyBase64)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	for k, v := range rec.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining 返回尚未回放的条数。
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+func drainRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败：%w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func matchKey(method, rawURL string, body []byte) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return strings.ToUpper(method) + " " + rawURL + "\n" + string(body)
+	}
+	// host 不参与匹配：同一份录像可在测试服务器或不同 base_url 下回放。
+	return strings.ToUpper(method) + " " + u.Path + "?" + u.Query().Encode() + "\n" + string(body)
+}
+
+func recordedPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
+
+func redactURL(u *url.URL) string {
+	clone := *u
+	q := clone.Query()
+	changed := false
+	for _, k := range redactedQuery {
+		if q.Has(k) {
+			q.Del(k)
+			changed = true
+		}
+	}
+	if changed {
+		clone.RawQuery = q.Encode()
+	}
+	return clone.String()
+}
+
+func flattenHeaders(h http.Header, redact bool) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if redact && redactedHeaders[strings.ToLower(k)] {
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func encodeBody(body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return "", base64.StdEncoding.EncodeToString(body)
+}
+
+func decodeBody(text, b64 string) ([]byte, error) {
+	if b64 == "" {
+		return []byte(text), nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("解码录像 body 失败：%w", err)
+	}
+	return raw, nil
+}
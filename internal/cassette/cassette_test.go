@@ -0,0 +1,157 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordThenReplayByRequest(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"echo":%q,"path":%q}`, string(body), r.URL.Path)
+	}))
+	defer ts.Close()
+
+	rec := NewRecorder(ts.Client().Transport)
+	client := &http.Client{Transport: rec}
+	for _, body := range []string{"a", "b"} {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/chat/completions?key=secret", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer sk-secret")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("record request error: %v", err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(got), `"echo":"`+body+`"`) {
+			t.Fatalf("recorder altered response: %s", got)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "tape.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if strings.Contains(string(raw), "secret") {
+		t.Fatalf("cassette leaked credentials: %s", raw)
+	}
+
+	tape, err := Load(path)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	rp := NewReplayer(tape, MatchRequest)
+	replayClient := &http.Client{Transport: rp}
+	// 顺序颠倒 + 不同 host 也能按请求内容匹配。
+	for _, body := range []string{"b", "a"} {
+		resp, err := replayClient.Post("http://replay.invalid/chat/completions?key=other", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("replay error: %v", err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(got), `"echo":"`+body+`"`) {
+			t.Fatalf("unexpected replay response: %d %s", resp.StatusCode, got)
+		}
+		if resp.Header.Get("Content-Type") != "application/json" {
+			t.Fatalf("expected recorded headers, got %v", resp.Header)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("replay should not hit server, calls=%d", calls)
+	}
+	if rp.Remaining() != 0 {
+		t.Fatalf("expected all interactions consumed, left=%d", rp.Remaining())
+	}
+	if _, err := replayClient.Post("http://replay.invalid/chat/completions", "application/json", strings.NewReader("c")); err == nil || !strings.Contains(err.Error(), "未找到匹配请求") {
+		t.Fatalf("expected unmatched error, got %v", err)
+	}
+}
+
+func TestReplayInOrderAndBinaryBody(t *testing.T) {
+	bin := []byte{0x1f, 0x8b, 0x00, 0xff}
+	tape := &Cassette{Version: formatVersion}
+	_, b64 := encodeBody(bin)
+	tape.Interactions = []Interaction{
+		{Request: RecordedRequest{Method: "GET", URL: "https://api.github.com/repos/o/r/releases/latest"}, Response: RecordedResponse{Status: 200, Body: `{"id":1}`}},
+		{Request: RecordedRequest{Method: "GET", URL: "https://example.com/asset"}, Response: RecordedResponse{Status: 200, BodyBase64: b64}},
+	}
+	rp := NewReplayer(tape, MatchOrder)
+	client := &http.Client{Transport: rp}
+	if _, err := client.Get("http://x/asset"); err == nil || !strings.Contains(err.Error(), "回放顺序不一致") {
+		t.Fatalf("expected order mismatch, got %v", err)
+	}
+	resp, err := client.Get("http://x/repos/o/r/releases/latest")
+	if err != nil {
+		t.Fatalf("ordered replay error: %v", err)
+	}
+	resp.Body.Close()
+	resp, err = client.Get("http://x/asset")
+	if err != nil {
+		t.Fatalf("ordered replay error: %v", err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(got, bin) {
+		t.Fatalf("binary body mismatch: %v", got)
+	}
+}
+
+func TestParseMatchModeAndLoadErrors(t *testing.T) {
+	if m, err := ParseMatchMode(""); err != nil || m != MatchRequest {
+		t.Fatalf("default mode: %v %v", m, err)
+	}
+	if m, err := ParseMatchMode("order"); err != nil || m != MatchOrder {
+		t.Fatalf("order mode: %v %v", m, err)
+	}
+	if _, err := ParseMatchMode("fuzzy"); err == nil {
+		t.Fatalf("expected invalid mode error")
+	}
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatalf("expected missing file error")
+	}
+	bad := filepath.Join(dir, "bad.json")
+	_ = os.WriteFile(bad, []byte(`{"version":99}`), 0o644)
+	if _, err := Load(bad); err == nil || !strings.Contains(err.Error(), "版本") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+}
+
+func TestRecorderStreamsBodyAndRecordsReadPart(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+		fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	rec := NewRecorder(ts.Client().Transport)
+	resp, err := (&http.Client{Transport: rec}).Get(ts.URL + "/stream")
+	if err != nil {
+		t.Fatalf("record request error: %v", err)
+	}
+	// 服务端仍在等待时就能读到第一块：录制不能先把整个响应读完。
+	buf := make([]byte, len("data: first\n\n"))
+	if _, err := io.ReadFull(resp.Body, buf); err != nil || string(buf) != "data: first\n\n" {
+		t.Fatalf("first chunk not streamed: %q %v", buf, err)
+	}
+	resp.Body.Close()
+	got := rec.Cassette().Interactions
+	if len(got) != 1 || got[0].Response.Body != "data: first\n\n" {
+		t.Fatalf("expected the read part recorded on early close: %+v", got)
+	}
+}
@@ -3,20 +3,269 @@ package config
 import "strings"
 
 type Config struct {
-	Provider          string                    `yaml:"provider"`
-	APIKeyEnv         string                    `yaml:"api_key_env"`
-	RulesCenter       RulesCenterConfig         `yaml:"rules_center"`
-	CharTolerance     int                       `yaml:"char_tolerance"`
-	Concurrency       int                       `yaml:"concurrency"`
-	MaxRetries        int                       `yaml:"max_retries"`
-	RequestTimeoutSec int                       `yaml:"request_timeout_sec"`
-	Output            OutputConfig              `yaml:"output"`
-	Providers         map[string]ProviderConfig `yaml:"providers"`
+	Provider          string                  `yaml:"provider"`
+	APIKeyEnv         string                  `yaml:"api_key_env"`
+	RulesCenter       RulesCenterConfig       `yaml:"rules_center"`
+	CharTolerance     int                     `yaml:"char_tolerance"`
+	Concurrency       int                     `yaml:"concurrency"`
+	MaxRetries        int                     `yaml:"max_retries"`
+	RequestTimeoutSec int                     `yaml:"request_timeout_sec"`
+	Output            OutputConfig            `yaml:"output"`
+	Cache             CacheConfig             `yaml:"cache"`
+	Style             StyleConfig             `yaml:"style"`
+	Diversity         DiversityConfig         `yaml:"diversity"`
+	Review            ReviewConfig            `yaml:"review"`
+	TranslationQA     TranslationQAConfig     `yaml:"translation_qa"`
+	Protect           ProtectConfig           `yaml:"protect"`
+	TranslationMemory TranslationMemoryConfig `yaml:"translation_memory"`
+	ReferenceTitle    ReferenceTitleConfig    `yaml:"reference_title"`
+	Library           LibraryConfig           `yaml:"library"`
+	// FactCheck 为 nil 时默认开启英文文案数值核对。
+	FactCheck *bool                     `yaml:"fact_check"`
+	Providers map[string]ProviderConfig `yaml:"providers"`
+}
+
+type CacheConfig struct {
+	Mode string `yaml:"mode"`
+	Dir  string `yaml:"dir"`
+}
+
+// StyleConfig 是英文标题与五点的亚马逊风格策略：安全项本地自动修正，其余作为问题进入修复循环。
+type StyleConfig struct {
+	// Enabled 为 nil 时默认开启。
+	Enabled *bool `yaml:"enabled"`
+	// MaxWordRepeat 为标题中同一单词（虚词除外）允许出现的最多次数。
+	MaxWordRepeat int `yaml:"max_word_repeat"`
+	// SmallWords 为标题 Title Case 中保持小写的虚词（冠词、连词、短介词）。
+	SmallWords []string `yaml:"small_words"`
+	// AllowedAllCaps 为允许全大写的缩写，如 HDMI。
+	AllowedAllCaps     []string           `yaml:"allowed_all_caps"`
+	PromotionalPhrases []string           `yaml:"promotional_phrases"`
+	Title              StyleSectionConfig `yaml:"title"`
+	Bullets            StyleSectionConfig `yaml:"bullets"`
+}
+
+type StyleSectionConfig struct {
+	// DisallowedChars 中的每个字符都会被自动删除。
+	DisallowedChars string `yaml:"disallowed_chars"`
+	// CheckAllCaps 为 nil 时默认检查全大写单词。
+	CheckAllCaps *bool `yaml:"check_all_caps"`
+}
+
+// IsEnabled 返回风格策略是否开启。
+func (s StyleConfig) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// AllCapsChecked 返回是否检查全大写单词。
+func (s StyleSectionConfig) AllCapsChecked() bool {
+	return s.CheckAllCaps == nil || *s.CheckAllCaps
+}
+
+var (
+	defaultStyleSmallWords         = []string{"a", "an", "the", "and", "or", "nor", "but", "for", "of", "in", "on", "at", "to", "by", "with", "from", "into", "over", "per", "as", "vs"}
+	defaultStyleAllowedAllCaps     = []string{"HDMI", "SATA", "NEMA"}
+	defaultStylePromotionalPhrases = []string{"free shipping", "best seller", "best selling", "hot sale", "on sale", "limited time", "money back guarantee", "satisfaction guaranteed", "100% guaranteed", "top rated", "lowest price", "buy now", "#1"}
+)
+
+const (
+	defaultStyleTitleDisallowed   = "!$?_{}^¬¦"
+	defaultStyleBulletsDisallowed = "™®€…†‡¢£¥©±~"
+)
+
+// FactCheckEnabled 返回是否核对英文文案中的数值与需求原文一致。
+func (c Config) FactCheckEnabled() bool {
+	return c.FactCheck == nil || *c.FactCheck
+}
+
+// DefaultStyleConfig 返回填好默认值的风格策略。
+func DefaultStyleConfig() StyleConfig {
+	var s StyleConfig
+	s.applyDefaults()
+	return s
+}
+
+func (s *StyleConfig) applyDefaults() {
+	if s.MaxWordRepeat <= 0 {
+		s.MaxWordRepeat = 2
+	}
+	if s.SmallWords == nil {
+		s.SmallWords = append([]string{}, defaultStyleSmallWords...)
+	}
+	if s.AllowedAllCaps == nil {
+		s.AllowedAllCaps = append([]string{}, defaultStyleAllowedAllCaps...)
+	}
+	if s.PromotionalPhrases == nil {
+		s.PromotionalPhrases = append([]string{}, defaultStylePromotionalPhrases...)
+	}
+	if s.Title.DisallowedChars == "" {
+		s.Title.DisallowedChars = defaultStyleTitleDisallowed
+	}
+	if s.Bullets.DisallowedChars == "" {
+		s.Bullets.DisallowedChars = defaultStyleBulletsDisallowed
+	}
+}
+
+// DiversityConfig 控制多候选（output.num > 1）之间的差异：每个候选按角度列表轮流取一个写作角度，
+// 标题与同批已生成的标题过于相似时重新生成。
+type DiversityConfig struct {
+	// Enabled 为 nil 时默认开启。
+	Enabled *bool `yaml:"enabled"`
+	// Angles 为写作角度，候选 N 取第 ((N-1) mod len) 个。
+	Angles []string `yaml:"angles"`
+	// MaxTitleSimilarity 为同一需求候选标题之间允许的最大词 n-gram 相似度（Jaccard，0~1），设为 1 即不检查。
+	MaxTitleSimilarity float64 `yaml:"max_title_similarity"`
+	NGram              int     `yaml:"ngram"`
+	// MaxRegenerations 为标题因相似重新生成的最多次数，用尽后保留最后一次结果并告警；nil 时默认 2，设为 0 只检查不重新生成。
+	MaxRegenerations *int `yaml:"max_regenerations"`
+}
+
+// IsEnabled 返回是否启用候选差异化。
+func (d DiversityConfig) IsEnabled() bool {
+	return d.Enabled == nil || *d.Enabled
+}
+
+// RegenerationLimit 返回标题因相似重新生成的最多次数。
+func (d DiversityConfig) RegenerationLimit() int {
+	if d.MaxRegenerations == nil {
+		return 2
+	}
+	return max(*d.MaxRegenerations, 0)
+}
+
+var defaultDiversityAngles = []string{
+	"以最核心的功能卖点开头，语气专业直接，突出规格与性能",
+	"以具体使用场景开头，描述用户在什么场合如何使用，语气亲切",
+	"以材质与做工开头，强调耐用性、安全性与品质细节",
+	"以解决的问题开头，强调使用前后的对比与便利",
+	"以适用人群与送礼场景开头，语气温暖，突出情感价值",
+}
+
+// DefaultDiversityConfig 返回填好默认值的候选差异化配置。
+func DefaultDiversityConfig() DiversityConfig {
+	var d DiversityConfig
+	d.applyDefaults()
+	return d
+}
+
+func (d *DiversityConfig) applyDefaults() {
+	if d.Angles == nil {
+		d.Angles = append([]string{}, defaultDiversityAngles...)
+	}
+	if d.MaxTitleSimilarity <= 0 || d.MaxTitleSimilarity > 1 {
+		d.MaxTitleSimilarity = 0.6
+	}
+	if d.NGram <= 0 {
+		d.NGram = 2
+	}
+}
+
+// ReviewConfig 控制生成后的模型评审：按规则包中的 review.yaml 为英文 listing 打分。
+type ReviewConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Repair 为 true 时低分分段带评语再修复一轮，并重新翻译该分段。
+	Repair bool `yaml:"repair"`
+	// Model 为空时沿用生成所用模型。
+	Model string `yaml:"model"`
+}
+
+// TranslationQAConfig 控制中文译文的回译质检：把中文回译成英文，与英文原文比较关键词、数值与相似度。
+type TranslationQAConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MinScore 为条目质检得分（0～1）的及格线，低于它的条目带差异重新翻译；默认 0.6。
+	MinScore float64 `yaml:"min_score"`
+	// MaxRetranslations 为低分条目最多重新翻译的次数，默认 1。
+	MaxRetranslations int `yaml:"max_retranslations"`
+}
+
+func (q *TranslationQAConfig) applyDefaults() {
+	if q.MinScore <= 0 || q.MinScore > 1 {
+		q.MinScore = 0.6
+	}
+	if q.MaxRetranslations <= 0 {
+		q.MaxRetranslations = 1
+	}
+}
+
+// ProtectConfig 控制翻译时的受保护内容：品牌、Terms、型号与带单位的数值替换为占位符后翻译，译后原样还原。
+type ProtectConfig struct {
+	// Enabled 为 nil 时默认开启。
+	Enabled *bool `yaml:"enabled"`
+	// Terms 为品牌之外需要原样保留的词，如系列名、注册商标。
+	Terms []string `yaml:"terms"`
+}
+
+// IsEnabled 返回翻译时是否保护受保护内容。
+func (p ProtectConfig) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// ReferenceTitleConfig 控制需求中 # 参考标题（版本A）的用法：生成后总会输出 A/B 对比，
+// InPrompt 为 true 时还把参考标题作为风格参考写进标题提示词（默认关闭，避免版本B向版本A靠拢）。
+type ReferenceTitleConfig struct {
+	InPrompt bool `yaml:"in_prompt"`
+}
+
+// LibraryConfig 控制已审核范例库：生成英文时按分类与关键词重合度选取范例，连同品牌语气档案写进分段提示词。
+type LibraryConfig struct {
+	// Enabled 为 nil 时默认开启；范例库为空时不影响提示词。
+	Enabled *bool `yaml:"enabled"`
+	// Dir 为范例库目录，默认 ~/.syl-listing/library。
+	Dir string `yaml:"dir"`
+	// Examples 为每个分段最多注入的范例数，默认 2。
+	Examples int `yaml:"examples"`
+}
+
+// IsEnabled 返回生成时是否读取范例库。
+func (l LibraryConfig) IsEnabled() bool {
+	return l.Enabled == nil || *l.Enabled
+}
+
+func (l *LibraryConfig) applyDefaults() {
+	if l.Examples <= 0 {
+		l.Examples = 2
+	}
+}
+
+// TranslationMemoryConfig 控制本地翻译记忆：成功运行的译文按规范化原文与语言对记录，
+// 完全相同的原文直接复用，相似原文的已有译文作为翻译参考。
+type TranslationMemoryConfig struct {
+	// Enabled 为 nil 时默认开启。
+	Enabled *bool `yaml:"enabled"`
+	// Path 为记忆文件路径，默认 ~/.syl-listing/tm.json。
+	Path string `yaml:"path"`
+	// FuzzyMinScore 为相似原文的最低相似度（0～1），默认 0.6。
+	FuzzyMinScore float64 `yaml:"fuzzy_min_score"`
+	// FuzzyHints 为每次翻译最多附带的相似译文条数，默认 3。
+	FuzzyHints int `yaml:"fuzzy_hints"`
+}
+
+// IsEnabled 返回是否读写翻译记忆。
+func (m TranslationMemoryConfig) IsEnabled() bool {
+	return m.Enabled == nil || *m.Enabled
+}
+
+func (m *TranslationMemoryConfig) applyDefaults() {
+	if m.FuzzyMinScore <= 0 || m.FuzzyMinScore > 1 {
+		m.FuzzyMinScore = 0.6
+	}
+	if m.FuzzyHints <= 0 {
+		m.FuzzyHints = 3
+	}
 }
 
 type OutputConfig struct {
 	Dir string `yaml:"dir"`
 	Num int    `yaml:"num"`
+	// Best 为多候选时最佳候选的输出方式：copy、symlink 或 off（默认）。
+	Best string `yaml:"best"`
+	// Compose 为 nil 时默认开启：多候选时组合各分段得分最高的版本。
+	Compose *bool `yaml:"compose"`
+}
+
+// ComposeEnabled 返回多候选运行后是否输出跨候选组合结果。
+func (o OutputConfig) ComposeEnabled() bool {
+	return o.Compose == nil || *o.Compose
 }
 
 type ProviderConfig struct {
@@ -25,6 +274,8 @@ type ProviderConfig struct {
 	Model                string                 `yaml:"model"`
 	ModelReasoningEffort string                 `yaml:"model_reasoning_effort"`
 	ThinkingFallback     ThinkingFallbackConfig `yaml:"thinking_fallback"`
+	Stream               bool                   `yaml:"stream"`
+	StreamEarlyAbort     bool                   `yaml:"stream_early_abort"`
 }
 
 type ThinkingFallbackConfig struct {
@@ -43,6 +294,11 @@ type Paths struct {
 	EnvExample       string
 	ConfigSource     string
 	ResolvedRulesDir string
+	CacheDir         string
+	// TMPath 为翻译记忆文件路径。
+	TMPath string
+	// LibraryDir 为已审核范例库目录。
+	LibraryDir string
 }
 
 type RulesCenterConfig struct {
@@ -94,6 +350,14 @@ func (c *Config) applyDefaults() {
 	if c.Output.Num <= 0 {
 		c.Output.Num = 1
 	}
+	if strings.TrimSpace(c.Cache.Mode) == "" {
+		c.Cache.Mode = "off"
+	}
+	c.Style.applyDefaults()
+	c.Diversity.applyDefaults()
+	c.TranslationQA.applyDefaults()
+	c.TranslationMemory.applyDefaults()
+	c.Library.applyDefaults()
 	if c.Providers == nil {
 		c.Providers = map[string]ProviderConfig{}
 	}
@@ -17,6 +17,9 @@ func TestApplyDefaults(t *testing.T) {
 	if cfg.Output.Dir != "." || cfg.Output.Num != 1 {
 		t.Fatalf("output defaults mismatch: %+v", cfg.Output)
 	}
+	if cfg.Cache.Mode != "off" {
+		t.Fatalf("cache mode default mismatch: %s", cfg.Cache.Mode)
+	}
 	ds, ok := cfg.Providers["deepseek"]
 	if !ok {
 		t.Fatalf("deepseek provider missing")
@@ -33,3 +36,62 @@ func TestApplyDefaultsProviderForcedDeepseek(t *testing.T) {
 		t.Fatalf("expected deepseek, got %s", cfg.Provider)
 	}
 }
+
+func TestApplyDefaultsStyle(t *testing.T) {
+	cfg := &Config{Style: StyleConfig{PromotionalPhrases: []string{}}}
+	cfg.applyDefaults()
+	if !cfg.Style.IsEnabled() || cfg.Style.MaxWordRepeat != 2 {
+		t.Fatalf("style defaults mismatch: %+v", cfg.Style)
+	}
+	if cfg.Style.Title.DisallowedChars != "!$?_{}^¬¦" || !cfg.Style.Title.AllCapsChecked() || len(cfg.Style.SmallWords) == 0 {
+		t.Fatalf("title style defaults mismatch: %+v", cfg.Style.Title)
+	}
+	if len(cfg.Style.PromotionalPhrases) != 0 {
+		t.Fatalf("explicit empty list should be kept: %v", cfg.Style.PromotionalPhrases)
+	}
+}
+
+func TestApplyDefaultsDiversity(t *testing.T) {
+	cfg := &Config{Diversity: DiversityConfig{MaxTitleSimilarity: 1.5, Angles: []string{"a"}}}
+	cfg.applyDefaults()
+	d := cfg.Diversity
+	if !d.IsEnabled() || d.MaxTitleSimilarity != 0.6 || d.NGram != 2 || d.RegenerationLimit() != 2 {
+		t.Fatalf("diversity defaults mismatch: %+v", d)
+	}
+	zero := 0
+	if d := (DiversityConfig{MaxRegenerations: &zero}); d.RegenerationLimit() != 0 {
+		t.Fatalf("max_regenerations: 0 should disable regeneration, got %d", d.RegenerationLimit())
+	}
+	if len(d.Angles) != 1 || len(DefaultDiversityConfig().Angles) != 5 {
+		t.Fatalf("explicit angles should be kept: %+v", d.Angles)
+	}
+}
+
+func TestApplyDefaultsTranslationQA(t *testing.T) {
+	cfg := &Config{TranslationQA: TranslationQAConfig{Enabled: true, MinScore: 2}}
+	cfg.applyDefaults()
+	q := cfg.TranslationQA
+	if !q.Enabled || q.MinScore != 0.6 || q.MaxRetranslations != 1 {
+		t.Fatalf("translation qa defaults mismatch: %+v", q)
+	}
+	cfg = &Config{TranslationQA: TranslationQAConfig{MinScore: 0.8, MaxRetranslations: 3}}
+	cfg.applyDefaults()
+	if cfg.TranslationQA.MinScore != 0.8 || cfg.TranslationQA.MaxRetranslations != 3 {
+		t.Fatalf("explicit translation qa values should be kept: %+v", cfg.TranslationQA)
+	}
+}
+
+func TestApplyDefaultsTranslationMemory(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	m := cfg.TranslationMemory
+	if !m.IsEnabled() || m.FuzzyMinScore != 0.6 || m.FuzzyHints != 3 {
+		t.Fatalf("translation memory defaults mismatch: %+v", m)
+	}
+	off := false
+	cfg = &Config{TranslationMemory: TranslationMemoryConfig{Enabled: &off, FuzzyMinScore: 0.9, FuzzyHints: 1}}
+	cfg.applyDefaults()
+	if cfg.TranslationMemory.IsEnabled() || cfg.TranslationMemory.FuzzyMinScore != 0.9 || cfg.TranslationMemory.FuzzyHints != 1 {
+		t.Fatalf("explicit translation memory values should be kept: %+v", cfg.TranslationMemory)
+	}
+}
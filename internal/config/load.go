@@ -34,6 +34,15 @@ func Load(pathArg, cwd string) (*Config, *Paths, error) {
 
 	paths.ConfigSource = paths.ConfigPath
 	paths.ResolvedRulesDir = paths.RulesDir
+	if strings.TrimSpace(cfg.Cache.Dir) != "" {
+		paths.CacheDir = expandPath(cfg.Cache.Dir, paths.HomeDir, filepath.Dir(paths.ConfigPath))
+	}
+	if strings.TrimSpace(cfg.TranslationMemory.Path) != "" {
+		paths.TMPath = expandPath(cfg.TranslationMemory.Path, paths.HomeDir, filepath.Dir(paths.ConfigPath))
+	}
+	if strings.TrimSpace(cfg.Library.Dir) != "" {
+		paths.LibraryDir = expandPath(cfg.Library.Dir, paths.HomeDir, filepath.Dir(paths.ConfigPath))
+	}
 	if err := ensureRuleDir(paths.ResolvedRulesDir); err != nil {
 		return nil, nil, err
 	}
@@ -63,6 +72,9 @@ func resolvePaths(configArg string) (*Paths, error) {
 		ConfigPath:    configPath,
 		RulesDir:      filepath.Join(rulesRoot, "rules"),
 		RulesLockPath: filepath.Join(rulesRoot, "rules.lock"),
+		CacheDir:      filepath.Join(rulesRoot, "responses"),
+		TMPath:        filepath.Join(root, "tm.json"),
+		LibraryDir:    filepath.Join(root, "library"),
 		EnvPath:       filepath.Join(root, ".env"),
 		EnvExample:    filepath.Join(root, ".env.example"),
 	}, nil
@@ -109,8 +121,12 @@ func expandPath(v, home, cwd string) string {
 }
 
 func ReadSectionRules(dir string) (SectionRules, error) {
-	load := func(name string) (SectionRuleFile, error) {
-		p := filepath.Join(dir, name)
+	manifest, err := readManifest(dir)
+	if err != nil {
+		return SectionRules{}, err
+	}
+	load := func(sec ManifestSection) (SectionRuleFile, error) {
+		p := filepath.Join(dir, sec.FileName())
 		raw, err := os.ReadFile(p)
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -122,31 +138,87 @@ func ReadSectionRules(dir string) (SectionRules, error) {
 		if err := yaml.Unmarshal(raw, &rule); err != nil {
 			return SectionRuleFile{}, fmt.Errorf("规则文件格式错误（%s）：%w", p, err)
 		}
-		return SectionRuleFile{Path: p, Raw: string(raw), Parsed: rule}, validateSectionRule(rule, name, p)
+		kind := sec.Output
+		if kind == "" {
+			kind = inferOutputKind(rule)
+		}
+		return SectionRuleFile{Path: p, Raw: string(raw), Parsed: rule, Kind: kind}, validateSectionRuleAs(rule, sec.Name, kind, p)
 	}
 
-	title, err := load("title.yaml")
-	if err != nil {
-		return SectionRules{}, err
+	rules := SectionRules{}
+	for i, sec := range manifest.Sections {
+		f, err := load(sec)
+		if err != nil {
+			return SectionRules{}, err
+		}
+		manifest.Sections[i].Output = f.Kind
+		rules.set(sec.Name, f)
 	}
-	bullets, err := load("bullets.yaml")
-	if err != nil {
+	rules.Manifest = manifest
+	if rules.Review, err = readReviewRubric(dir); err != nil {
 		return SectionRules{}, err
 	}
-	desc, err := load("description.yaml")
-	if err != nil {
+	if rules.CN, err = readCNRules(dir, rules); err != nil {
 		return SectionRules{}, err
 	}
-	search, err := load("search_terms.yaml")
-	if err != nil {
-		return SectionRules{}, err
+	return rules, nil
+}
+
+// readCNRules 读取清单各分段可选的中文规则；未声明的输出行数/段数沿用英文规则，声明时必须与英文一致。
+func readCNRules(dir string, rules SectionRules) (map[string]SectionRuleFile, error) {
+	var out map[string]SectionRuleFile
+	for _, sec := range rules.Sections() {
+		p := filepath.Join(dir, sec.CNFileName())
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("读取中文规则文件失败（%s）：%w", p, err)
+		}
+		rule := SectionRule{}
+		if err := yaml.Unmarshal(raw, &rule); err != nil {
+			return nil, fmt.Errorf("中文规则文件格式错误（%s）：%w", p, err)
+		}
+		en, err := rules.Get(sec.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := normalizeCNRule(&rule, en.Parsed, sec, p); err != nil {
+			return nil, err
+		}
+		if out == nil {
+			out = map[string]SectionRuleFile{}
+		}
+		out[sec.Name] = SectionRuleFile{Path: p, Raw: string(raw), Parsed: rule, Kind: sec.Output}
 	}
-	return SectionRules{
-		Title:       title,
-		Bullets:     bullets,
-		Description: desc,
-		SearchTerms: search,
-	}, nil
+	return out, nil
+}
+
+func normalizeCNRule(rule *SectionRule, en SectionRule, sec ManifestSection, path string) error {
+	if strings.TrimSpace(rule.Section) != sec.Name {
+		return fmt.Errorf("中文规则文件 section 不匹配（%s）：期望 %s，实际 %s", path, sec.Name, strings.TrimSpace(rule.Section))
+	}
+	mode := strings.ToLower(strings.TrimSpace(rule.Mode))
+	if mode != "" && mode != CNModeTranslate && mode != CNModeNative {
+		return fmt.Errorf("中文规则 mode 仅支持 translate/native（%s）", path)
+	}
+	if rule.Output.Lines == 0 {
+		rule.Output.Lines = en.Output.Lines
+	}
+	if rule.Output.Paragraphs == 0 {
+		rule.Output.Paragraphs = en.Output.Paragraphs
+	}
+	if rule.Output.Lines != en.Output.Lines || rule.Output.Paragraphs != en.Output.Paragraphs {
+		return fmt.Errorf("中文规则 output.lines/paragraphs 必须与英文规则一致（%s）", path)
+	}
+	if rule.CNMode() != CNModeNative {
+		return nil
+	}
+	if strings.EqualFold(strings.TrimSpace(rule.Execution.Generation.Protocol), "deterministic") {
+		return fmt.Errorf("中文规则 mode=native 不支持 deterministic 协议（%s）", path)
+	}
+	return validateSectionRuleAs(*rule, sec.Name, sec.Output, path)
 }
 
 func ensureRuleDir(dir string) error {
@@ -160,6 +232,12 @@ func ensureRuleDir(dir string) error {
 }
 
 func validateSectionRule(rule SectionRule, filename, path string) error {
+	section := strings.TrimSuffix(filename, ".yaml")
+	return validateSectionRuleAs(rule, section, "", path)
+}
+
+// validateSectionRuleAs 按清单中的分段名与输出类型校验规则文件。
+func validateSectionRuleAs(rule SectionRule, expectedSection, kind, path string) error {
 	if strings.TrimSpace(rule.Instruction) == "" {
 		return fmt.Errorf("规则文件缺少 instruction（%s）", path)
 	}
@@ -167,11 +245,18 @@ func validateSectionRule(rule SectionRule, filename, path string) error {
 	if protocol == "" {
 		return fmt.Errorf("规则文件缺少 execution.generation.protocol（%s）", path)
 	}
-	if protocol != "text" && protocol != "json_lines" {
-		return fmt.Errorf("execution.generation.protocol 仅支持 text/json_lines（%s）", path)
+	if protocol != "text" && protocol != "json_lines" && protocol != "deterministic" {
+		return fmt.Errorf("execution.generation.protocol 仅支持 text/json_lines/deterministic（%s）", path)
 	}
-	if protocol == "text" && strings.TrimSpace(strings.ToLower(rule.Output.Format)) != "plain_text" {
-		return fmt.Errorf("execution.generation.protocol=text 时 output.format 必须为 plain_text（%s）", path)
+	if (protocol == "text" || protocol == "deterministic") && strings.TrimSpace(strings.ToLower(rule.Output.Format)) != "plain_text" {
+		return fmt.Errorf("execution.generation.protocol=%s 时 output.format 必须为 plain_text（%s）", protocol, path)
+	}
+	if protocol == "deterministic" && expectedSection != "search_terms" {
+		// 本地拼接只会拼出关键词搜索词，用在其他分段会静默产出关键词堆砌。
+		return fmt.Errorf("execution.generation.protocol=deterministic 仅适用于 search_terms 分段（%s）", path)
+	}
+	if protocol == "deterministic" && rule.Output.Lines != 1 {
+		return fmt.Errorf("execution.generation.protocol=deterministic 仅适用于单行分段（%s）", path)
 	}
 	if protocol == "json_lines" && strings.TrimSpace(strings.ToLower(rule.Output.Format)) != "json_object" {
 		return fmt.Errorf("execution.generation.protocol=json_lines 时 output.format 必须为 json_object（%s）", path)
@@ -192,10 +277,6 @@ func validateSectionRule(rule SectionRule, filename, path string) error {
 	if rule.Execution.Fallback.DisableThinkingOnLengthError == nil {
 		return fmt.Errorf("规则文件缺少 execution.fallback.disable_thinking_on_length_error（%s）", path)
 	}
-	expectedSection := strings.TrimSuffix(filename, ".yaml")
-	if expectedSection == "search_terms" {
-		expectedSection = "search_terms"
-	}
 	if strings.TrimSpace(rule.Section) != expectedSection {
 		return fmt.Errorf("规则文件 section 不匹配（%s）：期望 %s，实际 %s", path, expectedSection, strings.TrimSpace(rule.Section))
 	}
@@ -228,6 +309,27 @@ func validateSectionRule(rule SectionRule, filename, path string) error {
 		if rule.Constraints.MaxChars.Value <= 0 {
 			return fmt.Errorf("search_terms 规则 max_chars.value 必须 > 0（%s）", path)
 		}
+	default:
+		if kind == "" {
+			kind = inferOutputKind(rule)
+		}
+		switch kind {
+		case OutputLine:
+			if rule.Output.Lines != 1 {
+				return fmt.Errorf("%s 规则 output.lines 必须为 1（%s）", expectedSection, path)
+			}
+		case OutputLines:
+			if rule.Output.Lines <= 0 {
+				return fmt.Errorf("%s 规则 output.lines 必须 > 0（%s）", expectedSection, path)
+			}
+		case OutputParagraphs:
+			if rule.Output.Paragraphs <= 0 {
+				return fmt.Errorf("%s 规则 output.paragraphs 必须 > 0（%s）", expectedSection, path)
+			}
+		}
+		if protocol == "json_lines" && kind != OutputLines {
+			return fmt.Errorf("execution.generation.protocol=json_lines 仅适用于 output=lines 的分段（%s）", path)
+		}
 	}
 	return nil
 }
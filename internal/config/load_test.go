@@ -131,6 +131,9 @@ func TestLoadAndReadRules(t *testing.T) {
 	if runtime.GOOS != "windows" && strings.Contains(paths.ResolvedRulesDir, "~") {
 		t.Fatalf("rules dir should be expanded: %s", paths.ResolvedRulesDir)
 	}
+	if filepath.Dir(paths.CacheDir) != filepath.Dir(paths.ResolvedRulesDir) {
+		t.Fatalf("cache dir should sit next to rules dir: %s vs %s", paths.CacheDir, paths.ResolvedRulesDir)
+	}
 
 	writeRuleFiles(t, paths.ResolvedRulesDir)
 	rules, err := ReadSectionRules(paths.ResolvedRulesDir)
@@ -142,6 +145,30 @@ func TestLoadAndReadRules(t *testing.T) {
 	}
 }
 
+func TestLoadCacheConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+
+	cfgPath := filepath.Join(home, ".syl-listing", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(cfgPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, []byte("cache:\n  mode: off\n  dir: replay\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, paths, err := Load(cfgPath, home)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Cache.Mode != "off" {
+		t.Fatalf("cache mode should stay a string: %q", cfg.Cache.Mode)
+	}
+	if paths.CacheDir != filepath.Join(filepath.Dir(cfgPath), "replay") {
+		t.Fatalf("relative cache dir should resolve against config dir: %s", paths.CacheDir)
+	}
+}
+
 func TestExpandPath(t *testing.T) {
 	home := "/tmp/home"
 	if got := expandPath("~/x", home, ""); !strings.Contains(got, "home") {
@@ -253,4 +280,66 @@ func TestValidateSectionRuleCases(t *testing.T) {
 	if err := validateSectionRule(search, "search_terms.yaml", "/tmp/search_terms.yaml"); err != nil {
 		t.Fatalf("search_terms should pass: %v", err)
 	}
+
+	search.Execution.Generation = RuleGenerationSpec{Protocol: "deterministic", ExcludeTitleWords: true}
+	if err := validateSectionRule(search, "search_terms.yaml", "/tmp/search_terms.yaml"); err != nil {
+		t.Fatalf("deterministic search_terms should pass: %v", err)
+	}
+	desc.Output.Paragraphs = 2
+	desc.Execution.Generation.Protocol = "deterministic"
+	if err := validateSectionRule(desc, "description.yaml", "/tmp/description.yaml"); err == nil || !strings.Contains(err.Error(), "仅适用于 search_terms") {
+		t.Fatalf("expected deterministic search_terms-only error, got %v", err)
+	}
+	title.Execution.Generation.Protocol = "deterministic"
+	if err := validateSectionRule(title, "title.yaml", "/tmp/title.yaml"); err == nil || !strings.Contains(err.Error(), "仅适用于 search_terms") {
+		t.Fatalf("deterministic title should be rejected, got %v", err)
+	}
+	search.Output.Lines = 2
+	if err := validateSectionRule(search, "search_terms.yaml", "/tmp/search_terms.yaml"); err == nil || !strings.Contains(err.Error(), "单行") {
+		t.Fatalf("expected deterministic single-line error, got %v", err)
+	}
+}
+
+func TestReadCNRules(t *testing.T) {
+	d := t.TempDir()
+	writeRuleFiles(t, d)
+	rules, err := ReadSectionRules(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rules.CNRule("title"); ok {
+		t.Fatalf("cn rule should be optional")
+	}
+
+	write := func(name, raw string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(d, name), []byte(raw), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("title_cn.yaml", "section: title\nconstraints:\n  max_chars:\n    value: 100\n")
+	rules, err = ReadSectionRules(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, ok := rules.CNRule("title")
+	if !ok || title.Parsed.Constraints.MaxChars.Value != 100 || title.Parsed.Output.Lines != 1 || title.Parsed.CNMode() != CNModeTranslate {
+		t.Fatalf("unexpected cn title rule: %+v", title.Parsed)
+	}
+	if en, _ := rules.Get("title"); en.Parsed.Constraints.MaxChars.Value != 200 {
+		t.Fatalf("en rule should be untouched")
+	}
+
+	cases := map[string]string{
+		"section 不匹配": "section: bullets\n",
+		"mode":        "section: title\nmode: rewrite\n",
+		"必须与英文规则一致":   "section: title\noutput:\n  lines: 2\n",
+		"instruction": "section: title\nmode: native\n",
+	}
+	for want, raw := range cases {
+		write("title_cn.yaml", raw)
+		if _, err := ReadSectionRules(d); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q error, got %v", want, err)
+		}
+	}
 }
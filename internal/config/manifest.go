@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFileName 是规则包中的分段清单文件；缺失时按内置四段执行。
+const ManifestFileName = "manifest.yaml"
+
+// 分段输出类型。
+const (
+	OutputLine       = "line"
+	OutputLines      = "lines"
+	OutputParagraphs = "paragraphs"
+)
+
+// RulesManifest 描述规则包中的分段、生成顺序、依赖与输出类型。
+type RulesManifest struct {
+	Version  int               `yaml:"version"`
+	Sections []ManifestSection `yaml:"sections"`
+}
+
+type ManifestSection struct {
+	Name string `yaml:"name"`
+	// File 为空时取 <name>.yaml。
+	File string `yaml:"file"`
+	// Output 为 line/lines/paragraphs；为空时按规则文件 output 推断。
+	Output string `yaml:"output"`
+	// DependsOn 列出生成本段时作为上下文提供给模型的已生成分段，必须排在本段之前。
+	DependsOn []string       `yaml:"depends_on"`
+	Heading   SectionHeading `yaml:"heading"`
+}
+
+type SectionHeading struct {
+	EN string `yaml:"en"`
+	CN string `yaml:"cn"`
+}
+
+var builtinSections = map[string]ManifestSection{
+	"title": {
+		Name:    "title",
+		Output:  OutputLine,
+		Heading: SectionHeading{EN: "Title", CN: "标题"},
+	},
+	"bullets": {
+		Name:      "bullets",
+		Output:    OutputLines,
+		DependsOn: []string{"title"},
+		Heading:   SectionHeading{EN: "Bullet Points", CN: "五点描述"},
+	},
+	"description": {
+		Name:      "description",
+		Output:    OutputParagraphs,
+		DependsOn: []string{"title", "bullets"},
+		Heading:   SectionHeading{EN: "Product Description", CN: "产品描述"},
+	},
+	"search_terms": {
+		Name:      "search_terms",
+		Output:    OutputLine,
+		DependsOn: []string{"title", "bullets", "description"},
+		Heading:   SectionHeading{EN: "Search Terms", CN: "搜索词"},
+	},
+}
+
+var builtinSectionOrder = []string{"title", "bullets", "description", "search_terms"}
+
+var sectionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// DefaultManifest 返回与旧版规则包等价的清单：title → bullets → description → search_terms。
+func DefaultManifest() RulesManifest {
+	out := RulesManifest{Version: 1}
+	for _, name := range builtinSectionOrder {
+		out.Sections = append(out.Sections, BuiltinSection(name))
+	}
+	return out
+}
+
+// IsBuiltinSection 判断是否为 ListingDocument 中有专用字段的内置分段。
+func IsBuiltinSection(name string) bool {
+	_, ok := builtinSections[name]
+	return ok
+}
+
+// BuiltinSection 返回内置分段的清单项（输出类型、固定标题与默认依赖）；不是内置分段时返回零值。
+func BuiltinSection(name string) ManifestSection {
+	s := builtinSections[name]
+	s.File = name + ".yaml"
+	s.DependsOn = append([]string{}, s.DependsOn...)
+	return s
+}
+
+func (s ManifestSection) FileName() string {
+	if f := strings.TrimSpace(s.File); f != "" {
+		return f
+	}
+	return s.Name + ".yaml"
+}
+
+// CNFileName 返回分段中文规则的文件名：规则文件名去掉扩展名后加 _cn.yaml。
+func (s ManifestSection) CNFileName() string {
+	name := s.FileName()
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "_cn.yaml"
+}
+
+// HeadingFor 返回渲染 Markdown 时的二级标题；未声明时取分段名。
+func (s ManifestSection) HeadingFor(lang string) string {
+	if lang == "cn" && strings.TrimSpace(s.Heading.CN) != "" {
+		return strings.TrimSpace(s.Heading.CN)
+	}
+	if strings.TrimSpace(s.Heading.EN) != "" {
+		return strings.TrimSpace(s.Heading.EN)
+	}
+	words := strings.Fields(strings.ReplaceAll(s.Name, "_", " "))
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// readManifest 读取规则目录下的清单；不存在时返回默认清单。
+func readManifest(dir string) (RulesManifest, error) {
+	p := filepath.Join(dir, ManifestFileName)
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultManifest(), nil
+		}
+		return RulesManifest{}, fmt.Errorf("读取规则清单失败（%s）：%w", p, err)
+	}
+	m := RulesManifest{}
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return RulesManifest{}, fmt.Errorf("规则清单格式错误（%s）：%w", p, err)
+	}
+	if err := normalizeManifest(&m, p); err != nil {
+		return RulesManifest{}, err
+	}
+	return m, nil
+}
+
+// normalizeManifest 校验清单并为内置分段补齐输出类型、标题与默认依赖。
+func normalizeManifest(m *RulesManifest, path string) error {
+	if len(m.Sections) == 0 {
+		return fmt.Errorf("规则清单 sections 为空（%s）", path)
+	}
+	seen := map[string]bool{}
+	for i := range m.Sections {
+		s := &m.Sections[i]
+		s.Name = strings.TrimSpace(s.Name)
+		if !sectionNamePattern.MatchString(s.Name) {
+			return fmt.Errorf("规则清单第%d项 name 无效（%s）：%q", i+1, path, s.Name)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("规则清单分段重复（%s）：%s", path, s.Name)
+		}
+		s.Output = strings.ToLower(strings.TrimSpace(s.Output))
+		if builtin, ok := builtinSections[s.Name]; ok {
+			if s.Output != "" && s.Output != builtin.Output {
+				return fmt.Errorf("规则清单中 %s 的 output 必须为 %s（%s）", s.Name, builtin.Output, path)
+			}
+			// 内置分段的输出格式与标题固定，保证生成的 Listing 可被后续命令按标题解析。
+			s.Output = builtin.Output
+			s.Heading = builtin.Heading
+			// 内置分段未写 depends_on 时沿用旧版上下文：清单中排在前面的内置分段。
+			if s.DependsOn == nil {
+				for _, dep := range builtin.DependsOn {
+					if seen[dep] {
+						s.DependsOn = append(s.DependsOn, dep)
+					}
+				}
+			}
+		}
+		switch s.Output {
+		case "", OutputLine, OutputLines, OutputParagraphs:
+		default:
+			return fmt.Errorf("规则清单中 %s 的 output 仅支持 line/lines/paragraphs（%s）", s.Name, path)
+		}
+		for _, dep := range s.DependsOn {
+			if !seen[strings.TrimSpace(dep)] {
+				return fmt.Errorf("规则清单中 %s 依赖的 %s 不存在或排在其后（%s）", s.Name, dep, path)
+			}
+		}
+		seen[s.Name] = true
+	}
+	return nil
+}
+
+// inferOutputKind 在清单未声明 output 时按规则文件推断输出类型。
+func inferOutputKind(rule SectionRule) string {
+	switch {
+	case rule.Output.Paragraphs > 0:
+		return OutputParagraphs
+	case rule.Output.Lines > 1 || strings.EqualFold(strings.TrimSpace(rule.Output.Format), "json_object"):
+		return OutputLines
+	default:
+		return OutputLine
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testExtraRule = `version: 1
+section: product_name
+language: en
+output:
+  format: plain_text
+  lines: 1
+constraints:
+  max_chars:
+    value: 60
+execution:
+  generation:
+    protocol: text
+  repair:
+    granularity: whole
+  fallback:
+    disable_thinking_on_length_error: true
+instruction: |
+  only output product name
+`
+
+func writeManifestRules(t *testing.T, dir, manifest string) {
+	t.Helper()
+	writeRuleFiles(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, "product_name.yaml"), []byte(testExtraRule), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadSectionRulesWithoutManifestUsesDefault(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFiles(t, dir)
+	rules, err := ReadSectionRules(dir)
+	if err != nil {
+		t.Fatalf("ReadSectionRules error: %v", err)
+	}
+	var names []string
+	for _, s := range rules.Sections() {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "title,bullets,description,search_terms" {
+		t.Fatalf("unexpected default order: %v", names)
+	}
+	if sec, _ := rules.Section("search_terms"); len(sec.DependsOn) != 3 {
+		t.Fatalf("unexpected default deps: %+v", sec)
+	}
+}
+
+func TestReadSectionRulesWithManifestExtraSection(t *testing.T) {
+	dir := t.TempDir()
+	writeManifestRules(t, dir, `version: 1
+sections:
+  - name: title
+  - name: product_name
+    depends_on: [title]
+    heading: {en: Product Name, cn: 产品名}
+  - name: bullets
+  - name: description
+  - name: search_terms
+`)
+	rules, err := ReadSectionRules(dir)
+	if err != nil {
+		t.Fatalf("ReadSectionRules error: %v", err)
+	}
+	f, err := rules.Get("product_name")
+	if err != nil || f.OutputKind() != OutputLine {
+		t.Fatalf("extra rule not loaded: %+v err=%v", f, err)
+	}
+	sec, ok := rules.Section("product_name")
+	if !ok || sec.HeadingFor("cn") != "产品名" || sec.HeadingFor("en") != "Product Name" {
+		t.Fatalf("unexpected section: %+v", sec)
+	}
+	if b, _ := rules.Section("bullets"); strings.Join(b.DependsOn, ",") != "title" {
+		t.Fatalf("builtin default deps should be filled: %+v", b)
+	}
+}
+
+func TestManifestValidationErrors(t *testing.T) {
+	cases := map[string]string{
+		"空":    "version: 1\nsections: []\n",
+		"name": "sections:\n  - name: Bad-Name\n",
+		"重复":   "sections:\n  - name: title\n  - name: title\n",
+		"依赖":   "sections:\n  - name: product_name\n    depends_on: [title]\n  - name: title\n",
+		"必须为":  "sections:\n  - name: title\n    output: lines\n",
+		"仅支持":  "sections:\n  - name: product_name\n    output: table\n",
+	}
+	for want, manifest := range cases {
+		dir := t.TempDir()
+		writeManifestRules(t, dir, manifest)
+		if _, err := ReadSectionRules(dir); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("manifest %q: expected error containing %q, got %v", manifest, want, err)
+		}
+	}
+}
+
+func TestManifestMissingRuleFileAndHeadingFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeManifestRules(t, dir, "sections:\n  - name: title\n  - name: intended_use\n")
+	if _, err := ReadSectionRules(dir); err == nil || !strings.Contains(err.Error(), "intended_use.yaml") {
+		t.Fatalf("expected missing rule file error, got %v", err)
+	}
+	if got := (ManifestSection{Name: "intended_use"}).HeadingFor("cn"); got != "Intended Use" {
+		t.Fatalf("unexpected fallback heading: %q", got)
+	}
+}
+
+func TestApplyRulesBundleWithManifest(t *testing.T) {
+	src := t.TempDir()
+	writeManifestRules(t, src, "sections:\n  - name: title\n  - name: product_name\n")
+	files := map[string]string{}
+	for _, name := range []string{ManifestFileName, "title.yaml", "product_name.yaml"} {
+		raw, err := os.ReadFile(filepath.Join(src, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = string(raw)
+	}
+	rulesDir := filepath.Join(t.TempDir(), "rules")
+	if err := applyRulesBundle(buildRulesTarGzFiles(t, files), rulesDir); err != nil {
+		t.Fatalf("applyRulesBundle error: %v", err)
+	}
+	if !requiredRuleFilesExist(rulesDir) {
+		t.Fatalf("manifest files should be present")
+	}
+	if _, err := os.Stat(filepath.Join(rulesDir, "bullets.yaml")); err == nil {
+		t.Fatalf("bullets.yaml should not be required when manifest omits it")
+	}
+
+	delete(files, "product_name.yaml")
+	if err := applyRulesBundle(buildRulesTarGzFiles(t, files), rulesDir); err == nil || !strings.Contains(err.Error(), "product_name.yaml") {
+		t.Fatalf("expected missing manifest file error, got %v", err)
+	}
+}
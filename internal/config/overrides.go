@@ -0,0 +1,301 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RequirementOverrides 是需求文件 front matter 或 # 生成参数 块中的生成参数，只作用于该需求。
+type RequirementOverrides struct {
+	// CharTolerance 为 nil 时沿用全局 char_tolerance。
+	CharTolerance *int   `yaml:"char_tolerance"`
+	Num           int    `yaml:"num"`
+	Model         string `yaml:"model"`
+	// Bullets 是 sections.bullets.lines 的简写。
+	Bullets int `yaml:"bullets"`
+	// Forbidden 追加到所有分段的禁用词。
+	Forbidden []string                   `yaml:"forbidden"`
+	Sections  map[string]SectionOverride `yaml:"sections"`
+}
+
+// SectionOverride 覆盖单个分段规则的条数与长度限制；未填写的字段沿用规则包。
+type SectionOverride struct {
+	Lines           int      `yaml:"lines"`
+	Paragraphs      int      `yaml:"paragraphs"`
+	MaxChars        *int     `yaml:"max_chars"`
+	MinCharsPerLine *int     `yaml:"min_chars_per_line"`
+	MaxCharsPerLine *int     `yaml:"max_chars_per_line"`
+	Forbidden       []string `yaml:"forbidden"`
+}
+
+// ParseRequirementOverrides 解析生成参数 YAML；未知字段视为错误，避免拼写错误被静默忽略。
+func ParseRequirementOverrides(text string) (RequirementOverrides, error) {
+	var o RequirementOverrides
+	if strings.TrimSpace(text) == "" {
+		return o, nil
+	}
+	dec := yaml.NewDecoder(strings.NewReader(text))
+	dec.KnownFields(true)
+	if err := dec.Decode(&o); err != nil && !errors.Is(err, io.EOF) {
+		return RequirementOverrides{}, fmt.Errorf("生成参数格式错误：%w", err)
+	}
+	if o.CharTolerance != nil && *o.CharTolerance < 0 {
+		return RequirementOverrides{}, fmt.Errorf("生成参数 char_tolerance 不能为负数")
+	}
+	if o.Num < 0 {
+		return RequirementOverrides{}, fmt.Errorf("生成参数 num 必须 > 0")
+	}
+	if o.Bullets < 0 {
+		return RequirementOverrides{}, fmt.Errorf("生成参数 bullets 必须 > 0")
+	}
+	o.Model = strings.TrimSpace(o.Model)
+	if strings.ContainsAny(o.Model, " \t\r\n") {
+		return RequirementOverrides{}, fmt.Errorf("生成参数 model 不能包含空白：%q", o.Model)
+	}
+	if o.Bullets > 0 {
+		sec := o.Sections["bullets"]
+		if sec.Lines > 0 && sec.Lines != o.Bullets {
+			return RequirementOverrides{}, fmt.Errorf("生成参数 bullets=%d 与 sections.bullets.lines=%d 冲突", o.Bullets, sec.Lines)
+		}
+		sec.Lines = o.Bullets
+		if o.Sections == nil {
+			o.Sections = map[string]SectionOverride{}
+		}
+		o.Sections["bullets"] = sec
+	}
+	return o, nil
+}
+
+// IsZero 报告是否没有任何覆盖项。
+func (o RequirementOverrides) IsZero() bool {
+	return o.CharTolerance == nil && o.Num == 0 && o.Model == "" && len(o.Forbidden) == 0 && len(o.Sections) == 0
+}
+
+// WithOverrides 返回叠加生成参数后的规则副本，并列出实际改动的字段（如 bullets.lines=3）。
+// 合并后的规则按规则包同样的校验执行；分段规则原文同步改写，使 system prompt 与校验口径一致。
+// 改动英文条数或段落数时，中文规则随之调整以保持两者一致；全局 forbidden 也追加到中文规则。
+func (s SectionRules) WithOverrides(o RequirementOverrides) (SectionRules, []string, error) {
+	names := make([]string, 0, len(o.Sections))
+	for name := range o.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !s.Has(name) {
+			valid := make([]string, 0, len(s.Sections()))
+			for _, sec := range s.Sections() {
+				valid = append(valid, sec.Name)
+			}
+			return SectionRules{}, nil, fmt.Errorf("生成参数 sections.%s：未知分段（可用：%s）", name, strings.Join(valid, ", "))
+		}
+	}
+	out := s
+	var changed []string
+	for _, sec := range s.Sections() {
+		ov, has := o.Sections[sec.Name]
+		if !has && len(o.Forbidden) == 0 {
+			continue
+		}
+		f, err := out.Get(sec.Name)
+		if err != nil {
+			return SectionRules{}, nil, err
+		}
+		path := "生成参数 sections." + sec.Name
+		rule := f.Parsed
+		var patches []rulePatch
+		if ov.Lines < 0 || ov.Paragraphs < 0 {
+			return SectionRules{}, nil, fmt.Errorf("%s：lines/paragraphs 必须 > 0", path)
+		}
+		if ov.Lines > 0 {
+			if f.OutputKind() == OutputParagraphs {
+				return SectionRules{}, nil, fmt.Errorf("%s：按段落输出的分段请使用 paragraphs", path)
+			}
+			rule.Output.Lines = ov.Lines
+			patches = append(patches, rulePatch{path: []string{"output", "lines"}, value: ov.Lines})
+		}
+		if ov.Paragraphs > 0 {
+			if f.OutputKind() != OutputParagraphs {
+				return SectionRules{}, nil, fmt.Errorf("%s：按行输出的分段请使用 lines", path)
+			}
+			rule.Output.Paragraphs = ov.Paragraphs
+			patches = append(patches, rulePatch{path: []string{"output", "paragraphs"}, value: ov.Paragraphs})
+		}
+		for _, c := range []struct {
+			key    string
+			value  *int
+			target *RuleIntConstraint
+		}{
+			{"max_chars", ov.MaxChars, &rule.Constraints.MaxChars},
+			{"min_chars_per_line", ov.MinCharsPerLine, &rule.Constraints.MinCharsPerLine},
+			{"max_chars_per_line", ov.MaxCharsPerLine, &rule.Constraints.MaxCharsPerLine},
+		} {
+			if c.value == nil {
+				continue
+			}
+			if *c.value <= 0 {
+				return SectionRules{}, nil, fmt.Errorf("%s：%s 必须 > 0", path, c.key)
+			}
+			c.target.Value = *c.value
+			patches = append(patches, rulePatch{path: []string{"constraints", c.key, "value"}, value: *c.value})
+		}
+		if added := mergeForbidden(rule.Forbidden, append(append([]string{}, o.Forbidden...), ov.Forbidden...)); len(added) > len(rule.Forbidden) {
+			patches = append(patches, rulePatch{path: []string{"forbidden"}, value: added, label: fmt.Sprintf("forbidden+%d", len(added)-len(rule.Forbidden))})
+			rule.Forbidden = added
+		}
+		if len(patches) > 0 {
+			if err := validateSectionRuleAs(rule, sec.Name, f.OutputKind(), path); err != nil {
+				return SectionRules{}, nil, err
+			}
+			raw, err := patchRuleRaw(f.Raw, patches)
+			if err != nil {
+				return SectionRules{}, nil, fmt.Errorf("%s：改写规则原文失败：%w", path, err)
+			}
+			out = out.WithRule(sec.Name, SectionRuleFile{Path: f.Path, Raw: raw, Parsed: rule, Kind: f.Kind})
+			for _, p := range patches {
+				changed = append(changed, sec.Name+"."+p.String())
+			}
+		}
+		cn, ok := out.CNRule(sec.Name)
+		if !ok {
+			continue
+		}
+		// 中文规则随英文条数与段落数调整；全局禁用词与语言无关，同样追加到中文规则。
+		var cnPatches []rulePatch
+		if cn.Parsed.Output.Lines != rule.Output.Lines || cn.Parsed.Output.Paragraphs != rule.Output.Paragraphs {
+			cnPatches = append(cnPatches,
+				rulePatch{path: []string{"output", "lines"}, value: rule.Output.Lines},
+				rulePatch{path: []string{"output", "paragraphs"}, value: rule.Output.Paragraphs},
+			)
+			cn.Parsed.Output.Lines, cn.Parsed.Output.Paragraphs = rule.Output.Lines, rule.Output.Paragraphs
+		}
+		if added := mergeForbidden(cn.Parsed.Forbidden, o.Forbidden); len(added) > len(cn.Parsed.Forbidden) {
+			cnPatches = append(cnPatches, rulePatch{path: []string{"forbidden"}, value: added})
+			changed = append(changed, fmt.Sprintf("%s_cn.forbidden+%d", sec.Name, len(added)-len(cn.Parsed.Forbidden)))
+			cn.Parsed.Forbidden = added
+		}
+		if len(cnPatches) == 0 {
+			continue
+		}
+		if cn.Raw, err = patchRuleRaw(cn.Raw, cnPatches); err != nil {
+			return SectionRules{}, nil, fmt.Errorf("%s：改写中文规则原文失败：%w", path, err)
+		}
+		cnMap := make(map[string]SectionRuleFile, len(out.CN))
+		for k, v := range out.CN {
+			cnMap[k] = v
+		}
+		cnMap[sec.Name] = cn
+		out.CN = cnMap
+	}
+	return out, changed, nil
+}
+
+func mergeForbidden(base, extra []string) []string {
+	out := append([]string{}, base...)
+	seen := map[string]bool{}
+	for _, w := range base {
+		seen[strings.ToLower(strings.TrimSpace(w))] = true
+	}
+	for _, w := range extra {
+		key := strings.ToLower(strings.TrimSpace(w))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, strings.TrimSpace(w))
+	}
+	return out
+}
+
+type rulePatch struct {
+	path  []string
+	value any
+	// label 为空时按 path=value 展示。
+	label string
+}
+
+func (p rulePatch) String() string {
+	if p.label != "" {
+		return p.label
+	}
+	key := p.path[len(p.path)-1]
+	if key == "value" && len(p.path) > 1 {
+		key = p.path[len(p.path)-2]
+	}
+	return fmt.Sprintf("%s=%v", key, p.value)
+}
+
+// patchRuleRaw 在规则 YAML 原文上按路径改写字段，保留其余字段与注释。
+func patchRuleRaw(raw string, patches []rulePatch) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return "", err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	for _, p := range patches {
+		var value yaml.Node
+		if err := value.Encode(p.value); err != nil {
+			return "", err
+		}
+		if err := setYAMLPath(doc.Content[0], p.path, &value); err != nil {
+			return "", err
+		}
+	}
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func setYAMLPath(node *yaml.Node, path []string, value *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s 不是映射", path[0])
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			value.HeadComment, value.LineComment = node.Content[i+1].HeadComment, node.Content[i+1].LineComment
+			node.Content[i+1] = value
+			return nil
+		}
+		return setYAMLPath(node.Content[i+1], path[1:], value)
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	if len(path) == 1 {
+		node.Content = append(node.Content, key, value)
+		return nil
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, key, child)
+	return setYAMLPath(child, path[1:], value)
+}
+
+// Describe 按 key=value 列出生成参数中的全局项，供日志展示。
+func (o RequirementOverrides) Describe() []string {
+	var out []string
+	if o.CharTolerance != nil {
+		out = append(out, "char_tolerance="+strconv.Itoa(*o.CharTolerance))
+	}
+	if o.Num > 0 {
+		out = append(out, "num="+strconv.Itoa(o.Num))
+	}
+	if o.Model != "" {
+		out = append(out, "model="+o.Model)
+	}
+	return out
+}
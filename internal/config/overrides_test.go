@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseRequirementOverrides(t *testing.T) {
+	o, err := ParseRequirementOverrides("char_tolerance: 0\nnum: 2\nmodel: ' deepseek-reasoner '\nbullets: 3\nforbidden: [cheap]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *o.CharTolerance != 0 || o.Num != 2 || o.Model != "deepseek-reasoner" || o.Sections["bullets"].Lines != 3 || o.IsZero() {
+		t.Fatalf("unexpected overrides: %+v", o)
+	}
+	if got := strings.Join(o.Describe(), ","); got != "char_tolerance=0,num=2,model=deepseek-reasoner" {
+		t.Fatalf("Describe got %q", got)
+	}
+	if o, err := ParseRequirementOverrides("  \n"); err != nil || !o.IsZero() {
+		t.Fatalf("empty params should be zero: %+v %v", o, err)
+	}
+	for raw, want := range map[string]string{
+		"bulets: 3":          "field bulets not found",
+		"num: -1":            "num",
+		"char_tolerance: -5": "char_tolerance",
+		"bullets: 3\nsections:\n  bullets:\n    lines: 4": "冲突",
+		"model: deepseek chat":                            "model 不能包含空白",
+	} {
+		if _, err := ParseRequirementOverrides(raw); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("ParseRequirementOverrides(%q) err=%v, want %q", raw, err, want)
+		}
+	}
+}
+
+func TestSectionRulesWithOverrides(t *testing.T) {
+	d := t.TempDir()
+	writeRuleFiles(t, d)
+	if err := os.WriteFile(filepath.Join(d, "bullets_cn.yaml"), []byte("section: bullets\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := ReadSectionRules(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := ParseRequirementOverrides("bullets: 3\nforbidden: [cheap, Best]\nsections:\n  title:\n    max_chars: 150\n  bullets:\n    max_chars_per_line: 30\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, changed, err := rules.WithOverrides(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BulletCount() != 3 || got.Bullets.Parsed.Constraints.MaxCharsPerLine.Value != 30 || got.Title.Parsed.Constraints.MaxChars.Value != 150 {
+		t.Fatalf("overrides not applied: %+v %+v", got.Bullets.Parsed, got.Title.Parsed)
+	}
+	if len(got.SearchTerms.Parsed.Forbidden) != 2 || got.SearchTerms.Parsed.Forbidden[1] != "Best" {
+		t.Fatalf("global forbidden should reach every section: %v", got.SearchTerms.Parsed.Forbidden)
+	}
+	if rules.BulletCount() != 5 || len(rules.Title.Parsed.Forbidden) != 0 {
+		t.Fatalf("original rules must not change")
+	}
+	if cn, _ := got.CNRule("bullets"); cn.Parsed.Output.Lines != 3 || !strings.Contains(cn.Raw, "lines: 3") {
+		t.Fatalf("cn rule should follow en line count: %+v", cn)
+	}
+	if cn, _ := got.CNRule("bullets"); len(cn.Parsed.Forbidden) != 2 || !strings.Contains(cn.Raw, "- cheap") {
+		t.Fatalf("global forbidden should reach cn rules: %+v", cn)
+	}
+	if orig, _ := rules.CNRule("bullets"); orig.Parsed.Output.Lines != 5 {
+		t.Fatalf("original cn rule must not change")
+	}
+	var raw SectionRule
+	if err := yaml.Unmarshal([]byte(got.Bullets.Raw), &raw); err != nil || raw.Output.Lines != 3 || raw.Constraints.MaxCharsPerLine.Value != 30 || raw.Constraints.MinCharsPerLine.Value != 10 || raw.Instruction != "x" {
+		t.Fatalf("rule raw should carry effective values: %v\n%s", err, got.Bullets.Raw)
+	}
+	want := "title.max_chars=150,title.forbidden+2,bullets.lines=3,bullets.max_chars_per_line=30,bullets.forbidden+2,bullets_cn.forbidden+2,description.forbidden+2,search_terms.forbidden+2"
+	if got := strings.Join(changed, ","); got != want {
+		t.Fatalf("changed got %s, want %s", got, want)
+	}
+
+	for raw, want := range map[string]string{
+		"sections:\n  faq:\n    lines: 2":                   "未知分段",
+		"sections:\n  title:\n    lines: 2":                 "title 规则 output.lines 必须为 1",
+		"sections:\n  description:\n    lines: 2":           "paragraphs",
+		"sections:\n  bullets:\n    min_chars_per_line: 50": "最小长度不能大于最大长度",
+		"sections:\n  title:\n    max_chars: 0":             "max_chars 必须 > 0",
+		"bullets: 1":                                        "granularity=item",
+	} {
+		o, err := ParseRequirementOverrides(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := rules.WithOverrides(o); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("WithOverrides(%q) err=%v, want %q", raw, err, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReviewRubricFileName 是规则包中评审量表的文件名；不存在时不提供评审。
+const ReviewRubricFileName = "review.yaml"
+
+// ReviewRubric 是模型评审英文 listing 的量表，随规则包分发。
+type ReviewRubric struct {
+	Version     int               `yaml:"version"`
+	Instruction string            `yaml:"instruction"`
+	Criteria    []ReviewCriterion `yaml:"criteria"`
+	// Scale 为每项评分的满分，最低 1 分；默认 5。
+	Scale int `yaml:"scale"`
+	// MinScore 为分段加权得分的及格线，低于它的分段视为低分；默认满分的 60%。
+	MinScore float64 `yaml:"min_score"`
+	// Path 与 Raw 由读取时填充，Raw 作为评审请求的 system prompt。
+	Path string `yaml:"-"`
+	Raw  string `yaml:"-"`
+}
+
+type ReviewCriterion struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Weight 为空或非正数时按 1 计。
+	Weight float64 `yaml:"weight"`
+}
+
+// EffectiveWeight 返回评分项权重。
+func (c ReviewCriterion) EffectiveWeight() float64 {
+	if c.Weight <= 0 {
+		return 1
+	}
+	return c.Weight
+}
+
+// readReviewRubric 读取规则目录下的评审量表；不存在时返回 nil。
+func readReviewRubric(dir string) (*ReviewRubric, error) {
+	p := filepath.Join(dir, ReviewRubricFileName)
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取评审量表失败（%s）：%w", p, err)
+	}
+	r := &ReviewRubric{}
+	if err := yaml.Unmarshal(raw, r); err != nil {
+		return nil, fmt.Errorf("评审量表格式错误（%s）：%w", p, err)
+	}
+	r.Path, r.Raw = p, string(raw)
+	if err := r.normalize(); err != nil {
+		return nil, fmt.Errorf("%w（%s）", err, p)
+	}
+	return r, nil
+}
+
+func (r *ReviewRubric) normalize() error {
+	if len(r.Criteria) == 0 {
+		return fmt.Errorf("评审量表缺少 criteria")
+	}
+	seen := map[string]bool{}
+	for i := range r.Criteria {
+		name := strings.TrimSpace(r.Criteria[i].Name)
+		if name == "" {
+			return fmt.Errorf("评审量表第%d项缺少 name", i+1)
+		}
+		if seen[name] {
+			return fmt.Errorf("评审量表评分项重复：%s", name)
+		}
+		seen[name] = true
+		r.Criteria[i].Name = name
+	}
+	if r.Scale <= 1 {
+		r.Scale = 5
+	}
+	if r.MinScore <= 0 || r.MinScore > float64(r.Scale) {
+		r.MinScore = float64(r.Scale) * 0.6
+	}
+	return nil
+}
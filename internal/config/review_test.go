@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadReviewRubric(t *testing.T) {
+	dir := t.TempDir()
+	if r, err := readReviewRubric(dir); err != nil || r != nil {
+		t.Fatalf("missing rubric should be nil: %v %v", r, err)
+	}
+	p := filepath.Join(dir, ReviewRubricFileName)
+	raw := "version: 1\ncriteria:\n  - name: \" clarity \"\n    description: 清晰\n  - name: grammar\n    weight: 2\n"
+	if err := os.WriteFile(p, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := readReviewRubric(dir)
+	if err != nil {
+		t.Fatalf("read rubric failed: %v", err)
+	}
+	if r.Scale != 5 || r.MinScore != 3 || r.Criteria[0].Name != "clarity" || r.Raw != raw || r.Path != p {
+		t.Fatalf("unexpected rubric: %+v", r)
+	}
+	if r.Criteria[0].EffectiveWeight() != 1 || r.Criteria[1].EffectiveWeight() != 2 {
+		t.Fatalf("unexpected weights: %+v", r.Criteria)
+	}
+
+	for body, want := range map[string]string{
+		"version: 1\n":                          "缺少 criteria",
+		"criteria:\n  - description: x\n":       "缺少 name",
+		"criteria:\n  - name: a\n  - name: a\n": "重复",
+		"criteria: [":                           "格式错误",
+	} {
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readReviewRubric(dir); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: want %q, got %v", body, want, err)
+		}
+	}
+}
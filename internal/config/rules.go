@@ -1,11 +1,37 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+// 长度计数口径。
+const (
+	CountRunes = "runes"
+	CountBytes = "bytes"
+	CountWords = "words"
+)
+
+// 关键词匹配方式。
+const (
+	MatchExact           = "exact"
+	MatchCaseInsensitive = "case_insensitive"
+	MatchWordBoundary    = "word_boundary"
+	MatchStem            = "stem"
+)
+
+// 中文规则（*_cn.yaml）的执行方式。
+const (
+	// CNModeTranslate 沿用翻译，译文超出中文规则时带字数要求重新翻译。
+	CNModeTranslate = "translate"
+	// CNModeNative 不翻译，按中文规则直接从需求生成中文分段。
+	CNModeNative = "native"
+)
 
 type RuleIntConstraint struct {
 	Value     int    `yaml:"value"`
 	Count     string `yaml:"count"`
-	Hard      bool   `yaml:"hard"`
+	Hard      *bool  `yaml:"hard"`
 	ExplainZH string `yaml:"explain_zh"`
 }
 
@@ -13,10 +39,49 @@ type RuleKeywordConstraint struct {
 	Value     int    `yaml:"value"`
 	Source    string `yaml:"source"`
 	Match     string `yaml:"match"`
-	Hard      bool   `yaml:"hard"`
+	Hard      *bool  `yaml:"hard"`
 	ExplainZH string `yaml:"explain_zh"`
 }
 
+// IsHard 未声明 hard 时按硬约束处理，与旧版规则行为一致。
+func (c RuleIntConstraint) IsHard() bool {
+	return c.Hard == nil || *c.Hard
+}
+
+// CountMode 返回归一化的计数口径：runes（默认）、bytes（UTF-8 字节）或 words。
+func (c RuleIntConstraint) CountMode() string {
+	return NormalizeCountMode(c.Count)
+}
+
+func NormalizeCountMode(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "bytes", "byte", "utf8_bytes", "utf-8_bytes", "utf8":
+		return CountBytes
+	case "words", "word":
+		return CountWords
+	default:
+		return CountRunes
+	}
+}
+
+func (c RuleKeywordConstraint) IsHard() bool {
+	return c.Hard == nil || *c.Hard
+}
+
+// MatchMode 返回归一化的匹配方式；未声明时为 case_insensitive（子串，不区分大小写）。
+func (c RuleKeywordConstraint) MatchMode() string {
+	switch strings.ToLower(strings.TrimSpace(c.Match)) {
+	case "exact", "case_sensitive":
+		return MatchExact
+	case "word", "word_boundary", "whole_word":
+		return MatchWordBoundary
+	case "stem", "stemmed", "plural", "lemma":
+		return MatchStem
+	default:
+		return MatchCaseInsensitive
+	}
+}
+
 type RuleOutputSpec struct {
 	Format             string `yaml:"format"`
 	Lines              int    `yaml:"lines"`
@@ -34,6 +99,8 @@ type RuleExecutionSpec struct {
 
 type RuleGenerationSpec struct {
 	Protocol string `yaml:"protocol"`
+	// ExcludeTitleWords 仅用于 deterministic：剔除标题中已出现的词。
+	ExcludeTitleWords bool `yaml:"exclude_title_words"`
 }
 
 type RuleRepairPolicySpec struct {
@@ -62,12 +129,35 @@ type SectionRule struct {
 	Forbidden   []string          `yaml:"forbidden"`
 	Execution   RuleExecutionSpec `yaml:"execution"`
 	Instruction string            `yaml:"instruction"`
+	// Mode 仅用于中文规则：translate（默认）或 native。
+	Mode string `yaml:"mode"`
+}
+
+// CNMode 返回中文规则的执行方式，未声明时为 translate。
+func (r SectionRule) CNMode() string {
+	if strings.EqualFold(strings.TrimSpace(r.Mode), CNModeNative) {
+		return CNModeNative
+	}
+	return CNModeTranslate
 }
 
 type SectionRuleFile struct {
 	Path   string
 	Raw    string
 	Parsed SectionRule
+	// Kind 为清单声明的输出类型（line/lines/paragraphs）；为空时按 Parsed.Output 推断。
+	Kind string
+}
+
+// OutputKind 返回分段输出类型。
+func (f SectionRuleFile) OutputKind() string {
+	if k := strings.TrimSpace(f.Kind); k != "" {
+		return k
+	}
+	if b, ok := builtinSections[f.Parsed.Section]; ok {
+		return b.Output
+	}
+	return inferOutputKind(f.Parsed)
 }
 
 type SectionRules struct {
@@ -75,6 +165,14 @@ type SectionRules struct {
 	Bullets     SectionRuleFile
 	Description SectionRuleFile
 	SearchTerms SectionRuleFile
+	// Extra 保存清单中内置四段以外的分段规则，键为分段名。
+	Extra map[string]SectionRuleFile
+	// Manifest 为空时按内置四段的默认清单执行。
+	Manifest RulesManifest
+	// Review 为规则包中的评审量表；nil 表示规则包未提供。
+	Review *ReviewRubric
+	// CN 为规则包中可选的中文规则（<分段>_cn.yaml），键为分段名；未提供的分段中文只做非空校验。
+	CN map[string]SectionRuleFile
 }
 
 func (s SectionRules) Get(step string) (SectionRuleFile, error) {
@@ -88,10 +186,73 @@ func (s SectionRules) Get(step string) (SectionRuleFile, error) {
 	case "search_terms":
 		return s.SearchTerms, nil
 	default:
+		if f, ok := s.Extra[step]; ok {
+			return f, nil
+		}
 		return SectionRuleFile{}, fmt.Errorf("未知分段：%s", step)
 	}
 }
 
+// Sections 按生成顺序返回清单中的分段。
+func (s SectionRules) Sections() []ManifestSection {
+	if len(s.Manifest.Sections) == 0 {
+		return DefaultManifest().Sections
+	}
+	return s.Manifest.Sections
+}
+
+// Section 返回分段的清单项；ok=false 表示清单未包含该分段。
+func (s SectionRules) Section(name string) (ManifestSection, bool) {
+	for _, sec := range s.Sections() {
+		if sec.Name == name {
+			return sec, true
+		}
+	}
+	return ManifestSection{}, false
+}
+
+// CNRule 返回分段的中文规则；ok=false 表示规则包没有该分段的中文规则。
+func (s SectionRules) CNRule(name string) (SectionRuleFile, bool) {
+	f, ok := s.CN[name]
+	return f, ok
+}
+
+// WithRule 返回把分段规则替换为 f 的副本，不修改原规则。
+func (s SectionRules) WithRule(name string, f SectionRuleFile) SectionRules {
+	out := s
+	if s.Extra != nil {
+		out.Extra = make(map[string]SectionRuleFile, len(s.Extra))
+		for k, v := range s.Extra {
+			out.Extra[k] = v
+		}
+	}
+	out.set(name, f)
+	return out
+}
+
+func (s SectionRules) Has(name string) bool {
+	_, ok := s.Section(name)
+	return ok
+}
+
+func (s *SectionRules) set(name string, f SectionRuleFile) {
+	switch name {
+	case "title":
+		s.Title = f
+	case "bullets":
+		s.Bullets = f
+	case "description":
+		s.Description = f
+	case "search_terms":
+		s.SearchTerms = f
+	default:
+		if s.Extra == nil {
+			s.Extra = map[string]SectionRuleFile{}
+		}
+		s.Extra[name] = f
+	}
+}
+
 func (s SectionRules) BulletCount() int {
 	return s.Bullets.Parsed.Output.Lines
 }
@@ -100,6 +261,29 @@ func (s SectionRules) DescriptionParagraphs() int {
 	return s.Description.Parsed.Output.Paragraphs
 }
 
+// EffectiveHard 结合 execution.hard_rule 决定某条约束是否为硬约束：
+// all/strict 全部视为硬约束；none/soft 全部降级为警告；其他值按约束自身的 hard。
+func (r SectionRule) EffectiveHard(constraintHard bool) bool {
+	switch strings.ToLower(strings.TrimSpace(r.Execution.HardRule)) {
+	case "all", "strict":
+		return true
+	case "none", "soft", "advisory":
+		return false
+	default:
+		return constraintHard
+	}
+}
+
+// PriorityIndex 返回约束名在 execution.priority 中的位置；未列出的排在最后。
+func (r SectionRule) PriorityIndex(name string) int {
+	for i, p := range r.Execution.Priority {
+		if strings.EqualFold(strings.TrimSpace(p), name) {
+			return i
+		}
+	}
+	return len(r.Execution.Priority)
+}
+
 func (r SectionRule) DisableThinkingFallbackOnLengthError() bool {
 	if r.Execution.Fallback.DisableThinkingOnLengthError == nil {
 		return false
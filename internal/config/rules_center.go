@@ -38,6 +38,11 @@ type githubRelease struct {
 }
 
 func SyncRulesFromCenter(cfg *Config, paths *Paths) (RulesSyncResult, error) {
+	return SyncRulesFromCenterVia(cfg, paths, nil)
+}
+
+// SyncRulesFromCenterVia 同 SyncRulesFromCenter，请求经 transport 发出（nil 为默认 Transport），供录制与回放使用。
+func SyncRulesFromCenterVia(cfg *Config, paths *Paths, transport http.RoundTripper) (RulesSyncResult, error) {
 	out := RulesSyncResult{}
 	if cfg == nil || paths == nil {
 		return out, nil
@@ -69,7 +74,7 @@ func SyncRulesFromCenter(cfg *Config, paths *Paths) (RulesSyncResult, error) {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
 	defer cancel()
-	client := &http.Client{Timeout: time.Duration(timeoutSec) * time.Second}
+	client := &http.Client{Timeout: time.Duration(timeoutSec) * time.Second, Transport: transport}
 
 	release, err := fetchGitHubRelease(ctx, client, owner, repo, releaseRef)
 	if err != nil {
@@ -214,7 +219,15 @@ func applyRulesBundle(raw []byte, rulesDir string) error {
 	if err := extractRuleFiles(raw, tmpDir); err != nil {
 		return err
 	}
-	for _, name := range requiredRuleFiles() {
+	if _, err := os.Stat(filepath.Join(tmpDir, ManifestFileName)); err == nil {
+		if _, err := readManifest(tmpDir); err != nil {
+			return err
+		}
+	}
+	if _, err := readReviewRubric(tmpDir); err != nil {
+		return err
+	}
+	for _, name := range requiredRuleFilesIn(tmpDir) {
 		p := filepath.Join(tmpDir, name)
 		if _, err := os.Stat(p); err != nil {
 			return fmt.Errorf("规则包缺少 %s", name)
@@ -236,10 +249,6 @@ func extractRuleFiles(raw []byte, outDir string) error {
 	}
 	defer gz.Close()
 	tr := tar.NewReader(gz)
-	required := map[string]struct{}{}
-	for _, n := range requiredRuleFiles() {
-		required[n] = struct{}{}
-	}
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
@@ -252,7 +261,8 @@ func extractRuleFiles(raw []byte, outDir string) error {
 			continue
 		}
 		base := filepath.Base(strings.TrimSpace(hdr.Name))
-		if _, ok := required[base]; !ok {
+		// 清单可引用任意分段规则文件，因此规则包中的 YAML 全部解出，是否齐全由清单决定。
+		if ext := strings.ToLower(filepath.Ext(base)); ext != ".yaml" && ext != ".yml" {
 			continue
 		}
 		target := filepath.Join(outDir, base)
@@ -275,8 +285,25 @@ func requiredRuleFiles() []string {
 	return []string{"title.yaml", "bullets.yaml", "description.yaml", "search_terms.yaml"}
 }
 
+// requiredRuleFilesIn 返回目录中规则清单要求的文件；没有清单时为内置四段。
+func requiredRuleFilesIn(dir string) []string {
+	if _, err := os.Stat(filepath.Join(dir, ManifestFileName)); err != nil {
+		return requiredRuleFiles()
+	}
+	m, err := readManifest(dir)
+	if err != nil {
+		// 清单损坏时仍要求清单本身，后续读取规则时再报出具体错误。
+		return []string{ManifestFileName}
+	}
+	out := []string{ManifestFileName}
+	for _, sec := range m.Sections {
+		out = append(out, sec.FileName())
+	}
+	return out
+}
+
 func requiredRuleFilesExist(dir string) bool {
-	for _, name := range requiredRuleFiles() {
+	for _, name := range requiredRuleFilesIn(dir) {
 		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
 			return false
 		}
@@ -17,15 +17,19 @@ import (
 
 func buildRulesTarGz(t *testing.T) []byte {
 	t.Helper()
-	buf := &bytes.Buffer{}
-	gz := gzip.NewWriter(buf)
-	tw := tar.NewWriter(gz)
-	files := map[string]string{
+	return buildRulesTarGzFiles(t, map[string]string{
 		"title.yaml":        "x",
 		"bullets.yaml":      "x",
 		"description.yaml":  "x",
 		"search_terms.yaml": "x",
-	}
+	})
+}
+
+func buildRulesTarGzFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
 	for name, content := range files {
 		h := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}
 		if err := tw.WriteHeader(h); err != nil {
@@ -31,3 +31,41 @@ func TestSectionRulesGet(t *testing.T) {
 		t.Fatalf("counts mismatch")
 	}
 }
+
+func TestRuleConstraintModes(t *testing.T) {
+	soft := false
+	if !(RuleIntConstraint{}).IsHard() || (RuleIntConstraint{Hard: &soft}).IsHard() {
+		t.Fatalf("unexpected int constraint hardness")
+	}
+	if !(RuleKeywordConstraint{}).IsHard() || (RuleKeywordConstraint{Hard: &soft}).IsHard() {
+		t.Fatalf("unexpected keyword constraint hardness")
+	}
+	for in, want := range map[string]string{"": CountRunes, "runes": CountRunes, "bytes": CountBytes, "UTF8_bytes": CountBytes, "words": CountWords} {
+		if got := (RuleIntConstraint{Count: in}).CountMode(); got != want {
+			t.Fatalf("count %q => %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string]string{"": MatchCaseInsensitive, "exact": MatchExact, "whole_word": MatchWordBoundary, "plural": MatchStem} {
+		if got := (RuleKeywordConstraint{Match: in}).MatchMode(); got != want {
+			t.Fatalf("match %q => %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSectionRuleHardRuleAndPriority(t *testing.T) {
+	r := SectionRule{Execution: RuleExecutionSpec{Priority: []string{"must_contain_top_n_keywords", "max_chars"}}}
+	if !r.EffectiveHard(true) || r.EffectiveHard(false) {
+		t.Fatalf("default hard_rule should follow constraint")
+	}
+	r.Execution.HardRule = "all"
+	if !r.EffectiveHard(false) {
+		t.Fatalf("hard_rule=all should force hard")
+	}
+	r.Execution.HardRule = "none"
+	if r.EffectiveHard(true) {
+		t.Fatalf("hard_rule=none should force soft")
+	}
+	if r.PriorityIndex("MAX_CHARS") != 1 || r.PriorityIndex("output") != 2 {
+		t.Fatalf("unexpected priority index")
+	}
+}
@@ -0,0 +1,242 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"syl-listing/internal/output"
+	"syl-listing/internal/textsim"
+)
+
+// 范例库目录结构：examples.json 存放已审核的英文 listing，voices/<品牌>.md 为手写的品牌语气档案。
+const (
+	examplesFile = "examples.json"
+	voicesDir    = "voices"
+	// defaultVoice 为没有对应品牌档案时使用的语气档案（voices/default.md）。
+	defaultVoice = "default"
+)
+
+// Section 是范例中一个分段的条目。
+type Section struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+// Example 是一份已审核通过的英文 listing。
+type Example struct {
+	Brand    string    `json:"brand"`
+	Category string    `json:"category"`
+	Keywords []string  `json:"keywords"`
+	Sections []Section `json:"sections"`
+	// Source 为加入范例库时的文件名，仅用于展示。
+	Source  string `json:"source,omitempty"`
+	AddedAt string `json:"added_at,omitempty"`
+}
+
+// SectionItems 返回分段 name 的条目；没有该分段时为 nil。
+func (e Example) SectionItems(name string) []string {
+	for _, s := range e.Sections {
+		if s.Name == name {
+			return s.Items
+		}
+	}
+	return nil
+}
+
+func (e Example) key() string {
+	body := strings.Join(e.SectionItems("title"), " ")
+	if body == "" {
+		for _, s := range e.Sections {
+			body += strings.Join(s.Items, " ")
+		}
+	}
+	return textsim.Normalize(e.Brand) + "\x00" + textsim.Normalize(body)
+}
+
+type Options struct {
+	// Examples 为 Select 最多返回的范例数。
+	Examples int
+}
+
+// Library 是本地范例库；nil Library 等价于关闭。
+type Library struct {
+	dir      string
+	opts     Options
+	examples []Example
+	voices   map[string]string
+	dirty    bool
+}
+
+type examplesFileData struct {
+	Version  int       `json:"version"`
+	Examples []Example `json:"examples"`
+}
+
+// Open 读取范例与品牌语气档案；目录或文件不存在时返回空库，Save 时创建。
+func Open(dir string, opts Options) (*Library, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("范例库目录为空")
+	}
+	l := &Library{dir: dir, opts: opts, voices: map[string]string{}}
+	raw, err := os.ReadFile(filepath.Join(dir, examplesFile))
+	switch {
+	case err == nil:
+		var f examplesFileData
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, fmt.Errorf("范例库格式错误（%s）：%w", filepath.Join(dir, examplesFile), err)
+		}
+		l.examples = f.Examples
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("读取范例库失败（%s）：%w", dir, err)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, voicesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取品牌语气目录失败：%w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, voicesDir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取品牌语气档案失败（%s）：%w", e.Name(), err)
+		}
+		if text := strings.TrimSpace(string(raw)); text != "" {
+			l.voices[textsim.Normalize(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))] = text
+		}
+	}
+	return l, nil
+}
+
+func (l *Library) Dir() string {
+	if l == nil {
+		return ""
+	}
+	return l.dir
+}
+
+func (l *Library) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.examples)
+}
+
+// Voice 返回品牌的语气档案（文件名与品牌名不区分大小写匹配），没有时退回 default.md。
+func (l *Library) Voice(brand string) string {
+	if l == nil {
+		return ""
+	}
+	if v, ok := l.voices[textsim.Normalize(brand)]; ok {
+		return v
+	}
+	return l.voices[defaultVoice]
+}
+
+// Add 加入一份范例；同一品牌下标题相同的范例视为同一份，以新加入的为准，返回 replaced=true。
+func (l *Library) Add(e Example) (replaced bool) {
+	if l == nil {
+		return false
+	}
+	e.Brand, e.Category = strings.TrimSpace(e.Brand), strings.TrimSpace(e.Category)
+	if strings.TrimSpace(e.AddedAt) == "" {
+		e.AddedAt = time.Now().Format(time.RFC3339)
+	}
+	l.dirty = true
+	k := e.key()
+	for i := range l.examples {
+		if l.examples[i].key() == k {
+			l.examples[i] = e
+			return true
+		}
+	}
+	l.examples = append(l.examples, e)
+	return false
+}
+
+// Select 返回与需求最相关的至多 Examples 份范例：按分类路径重合层级、关键词重合度与是否同品牌打分，
+// 分类与关键词都不沾边的范例不选；同分时新加入的在前。
+func (l *Library) Select(brand, category string, keywords []string) []Example {
+	if l == nil || l.opts.Examples <= 0 {
+		return nil
+	}
+	type scored struct {
+		Example
+		score float64
+	}
+	want := textsim.WordSet(keywords...)
+	var out []scored
+	for _, e := range l.examples {
+		relevance := 2*categoryOverlap(category, e.Category) + textsim.Dice(want, textsim.WordSet(e.Keywords...))
+		if relevance <= 0 {
+			continue
+		}
+		if textsim.Normalize(brand) != "" && textsim.Normalize(brand) == textsim.Normalize(e.Brand) {
+			relevance++
+		}
+		out = append(out, scored{Example: e, score: relevance})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].score != out[j].score {
+			return out[i].score > out[j].score
+		}
+		return out[i].AddedAt > out[j].AddedAt
+	})
+	if len(out) > l.opts.Examples {
+		out = out[:l.opts.Examples]
+	}
+	examples := make([]Example, len(out))
+	for i, s := range out {
+		examples[i] = s.Example
+	}
+	return examples
+}
+
+// Save 在有改动时原子写回 examples.json。
+func (l *Library) Save(ctx context.Context) error {
+	if l == nil || !l.dirty {
+		return nil
+	}
+	raw, err := json.MarshalIndent(examplesFileData{Version: 1, Examples: l.examples}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码范例库失败：%w", err)
+	}
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return fmt.Errorf("创建范例库目录失败：%w", err)
+	}
+	if err := output.WriteFilesAtomic(ctx, output.File{Path: filepath.Join(l.dir, examplesFile), Data: append(raw, '\n')}); err != nil {
+		return fmt.Errorf("写入范例库失败：%w", err)
+	}
+	l.dirty = false
+	return nil
+}
+
+// categoryOverlap 返回两个分类路径（A > B > C）自顶层起相同的层数占较长路径层数的比例。
+func categoryOverlap(a, b string) float64 {
+	pa, pb := categoryPath(a), categoryPath(b)
+	if len(pa) == 0 || len(pb) == 0 {
+		return 0
+	}
+	same := 0
+	for same < len(pa) && same < len(pb) && pa[same] == pb[same] {
+		same++
+	}
+	return float64(same) / float64(max(len(pa), len(pb)))
+}
+
+func categoryPath(category string) []string {
+	var out []string
+	for _, p := range strings.Split(category, ">") {
+		if p = textsim.Normalize(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func example(brand, category, title string, keywords ...string) Example {
+	return Example{Brand: brand, Category: category, Keywords: keywords, Sections: []Section{{Name: "title", Items: []string{title}}}}
+}
+
+func TestLibrarySelectAndSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "library")
+	l, err := Open(dir, Options{Examples: 2})
+	if err != nil || l.Len() != 0 {
+		t.Fatalf("missing dir should open empty: %v", err)
+	}
+	l.Add(example("BrandX", "Office > Boards > Pockets", "X Pockets", "dry erase pockets", "classroom"))
+	l.Add(example("BrandY", "Office > Boards > Pockets", "Y Pockets", "dry erase sleeves"))
+	l.Add(example("BrandX", "Garden > Hoses", "X Hose", "garden hose"))
+	if replaced := l.Add(example("brandx", "Office > Boards > Pockets", "x  pockets", "dry erase pockets")); !replaced {
+		t.Fatalf("same brand and title should replace")
+	}
+	got := l.Select("BrandX", "Office > Boards > Pockets", []string{"dry erase pockets", "teacher"})
+	if len(got) != 2 || got[0].Brand != "brandx" || got[1].Brand != "BrandY" {
+		t.Fatalf("unexpected selection: %+v", got)
+	}
+	if got := l.Select("BrandZ", "Toys", []string{"kite"}); len(got) != 0 {
+		t.Fatalf("unrelated examples should not be selected: %+v", got)
+	}
+	if err := l.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(dir, Options{})
+	if err != nil || reopened.Len() != 3 {
+		t.Fatalf("reopen failed: %v %d", err, reopened.Len())
+	}
+	if got := reopened.Select("BrandX", "Office", nil); got != nil {
+		t.Fatalf("zero examples should disable selection: %+v", got)
+	}
+}
+
+func TestLibraryVoice(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "voices"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "voices", "BrandX.md"), []byte("Warm, playful, second person.\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "voices", "default.md"), []byte("Plain and factual."), 0o644)
+	l, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Voice("brandx"); got != "Warm, playful, second person." {
+		t.Fatalf("brand voice mismatch: %q", got)
+	}
+	if got := l.Voice("Other"); got != "Plain and factual." {
+		t.Fatalf("default voice expected: %q", got)
+	}
+	var nilLib *Library
+	if nilLib.Voice("BrandX") != "" || nilLib.Select("a", "b", nil) != nil || nilLib.Save(context.Background()) != nil {
+		t.Fatalf("nil library should be a no-op")
+	}
+}
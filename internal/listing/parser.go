@@ -16,7 +16,13 @@ type Requirement struct {
 	Brand           string
 	Category        string
 	Keywords        []string
-	Warnings        []string
+	// ExistingListing 为 # 现有Listing 块的原文（编辑模式的基准文案），没有该块时为空。
+	ExistingListing string
+	// ReferenceTitle 为 # 参考标题 块中运营参照竞品写的标题（版本A），没有该块时为空。
+	ReferenceTitle string
+	// GenerationParams 为 front matter 或 # 生成参数 块中的 YAML 原文，只作用于本需求；没有时为空。
+	GenerationParams string
+	Warnings         []string
 }
 
 func ParseFile(path string) (Requirement, error) {
@@ -24,19 +30,32 @@ func ParseFile(path string) (Requirement, error) {
 	if err != nil {
 		return Requirement{}, fmt.Errorf("读取文件失败（%s）：%w", path, err)
 	}
-	raw := string(rawBytes)
+	return Parse(path, string(rawBytes))
+}
+
+// Parse 解析需求文件内容；path 只用于记录来源与错误信息。
+func Parse(path, raw string) (Requirement, error) {
 	body, ok := BodyAfterMarker(raw)
 	if !ok {
 		return Requirement{}, fmt.Errorf("文件不是 listing 需求格式（缺少首行标志 %s）：%s", Marker, path)
 	}
 
+	params, body, err := parseGenerationParams(body)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("%w：%s", err, path)
+	}
+	referenceTitle, body := parseReferenceTitle(body)
+	existing, body := parseExistingListing(body)
 	req := Requirement{
-		SourcePath:      path,
-		Raw:             raw,
-		BodyAfterMarker: body,
-		Brand:           parseBrand(body),
-		Category:        parseCategory(body),
-		Keywords:        parseKeywords(body),
+		SourcePath:       path,
+		Raw:              raw,
+		BodyAfterMarker:  body,
+		ReferenceTitle:   referenceTitle,
+		GenerationParams: params,
+		Brand:            parseBrand(body),
+		Category:         parseCategory(body),
+		Keywords:         parseKeywords(body),
+		ExistingListing:  existing,
 	}
 	if len(req.Keywords) < 15 || len(req.Keywords) > 20 {
 		req.Warnings = append(req.Warnings, fmt.Sprintf("关键词数量是 %d，不在 15-20 范围，继续生成", len(req.Keywords)))
@@ -129,8 +148,7 @@ func parseKeywords(body string) []string {
 		if strings.HasPrefix(line, "#") {
 			break
 		}
-		kw := keywordPrefixRe.ReplaceAllString(line, "")
-		kw = strings.TrimSpace(kw)
+		kw := TrimListMarker(line)
 		if kw == "" {
 			continue
 		}
@@ -138,3 +156,133 @@ func parseKeywords(body string) []string {
 	}
 	return out
 }
+
+// parseExistingListing 返回 # 现有Listing 之后到下一个一级标题之前的内容（块内用 ## 标题区分分段），以及去掉该块后的正文。
+// 旧文案只作为编辑基准，留在需求原文里会被当作需求事实发给模型，也会让数值核对把旧数值当成已知。
+func parseExistingListing(body string) (string, string) {
+	lines := splitLines(body)
+	start, end, ok := FindBlock(lines, "现有Listing")
+	if !ok {
+		return "", body
+	}
+	rest := append(append([]string{}, lines[:start-1]...), lines[end:]...)
+	return strings.TrimSpace(strings.Join(lines[start:end], "\n")), strings.Join(rest, "\n")
+}
+
+// parseReferenceTitle 从 # 参考标题（版本A）块中取出运营手写的参考标题，并返回去掉该块后的正文。
+// 参考标题只用于与生成标题对比，是否作为风格参考由配置决定，因此不留在需求原文里。
+func parseReferenceTitle(body string) (string, string) {
+	lines := splitLines(body)
+	start, end, ok := FindBlock(lines, "参考标题")
+	if !ok {
+		return "", body
+	}
+	parts := make([]string, 0, 1)
+	for _, line := range lines[start:end] {
+		if line = TrimListMarker(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	rest := append(append([]string{}, lines[:start-1]...), lines[end:]...)
+	return strings.Join(parts, " "), strings.Join(rest, "\n")
+}
+
+// parseGenerationParams 取出标志行后的 front matter（--- 包围）或 # 生成参数 块（可用 ```yaml 围栏），
+// 返回 YAML 原文与去掉该部分后的正文；参数由程序解析生效，不再作为需求原文发给模型。
+func parseGenerationParams(body string) (string, string, error) {
+	lines := splitLines(body)
+	front, frontEnd := "", 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.TrimSpace(line) == "---" {
+			for j := i + 1; j < len(lines); j++ {
+				if t := strings.TrimSpace(lines[j]); t == "---" || t == "..." {
+					front, frontEnd = strings.Join(lines[i+1:j], "\n"), j+1
+					break
+				}
+			}
+			if frontEnd == 0 {
+				return "", body, fmt.Errorf("front matter 缺少结束行 ---")
+			}
+		}
+		break
+	}
+	lines = lines[frontEnd:]
+	start, end, ok := FindBlock(lines, "生成参数")
+	if !ok {
+		return strings.TrimSpace(front), strings.Join(lines, "\n"), nil
+	}
+	if strings.TrimSpace(front) != "" {
+		return "", body, fmt.Errorf("front matter 与 # 生成参数 只能写一处")
+	}
+	block := lines[start:end]
+	for i := start; i < len(lines); i++ {
+		t := strings.TrimSpace(lines[i])
+		if t == "" {
+			continue
+		}
+		if strings.HasPrefix(t, "```") {
+			// 围栏内的 # 是 YAML 注释，不作为下一个标题；围栏之后的内容仍属于需求正文。
+			closing := -1
+			for j := i + 1; j < len(lines); j++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[j]), "```") {
+					closing = j
+					break
+				}
+			}
+			if closing < 0 {
+				return "", body, fmt.Errorf("# 生成参数 代码块缺少结束围栏")
+			}
+			block, end = lines[i+1:closing], closing+1
+		}
+		break
+	}
+	rest := append(append([]string{}, lines[:start-1]...), lines[end:]...)
+	return strings.TrimSpace(strings.Join(block, "\n")), strings.Join(rest, "\n"), nil
+}
+
+// TrimListMarker 去掉行首的列表编号（1. / 1) / - / * / •）与首尾空白。
+func TrimListMarker(line string) string {
+	return strings.TrimSpace(keywordPrefixRe.ReplaceAllString(strings.TrimSpace(line), ""))
+}
+
+// HeadingName 返回一级标题的名称（去掉空格与括号注释），用于与 关键词库、功能卖点 等块名比较；不是一级标题时 ok=false。
+func HeadingName(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "# ") {
+		return "", false
+	}
+	heading := strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+	if i := strings.IndexAny(heading, "（("); i > 0 {
+		heading = heading[:i]
+	}
+	return strings.ReplaceAll(heading, " ", ""), true
+}
+
+func splitLines(body string) []string {
+	return strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+}
+
+// FindBlock 查找一级标题 name（忽略空格与括号注释、不区分大小写），返回标题下一行到下一个一级标题之前的行区间。
+func FindBlock(lines []string, name string) (int, int, bool) {
+	start := -1
+	for i, line := range lines {
+		if heading, ok := HeadingName(line); ok && strings.EqualFold(heading, name) {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return 0, 0, false
+	}
+	end := len(lines)
+	for i := start; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "# ") {
+			end = i
+			break
+		}
+	}
+	return start, end, true
+}
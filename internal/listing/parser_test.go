@@ -93,3 +93,55 @@ func TestParseCategoryFromSectionHeader(t *testing.T) {
 		t.Fatalf("parseCategory got %q", got)
 	}
 }
+
+func TestParseExistingListing(t *testing.T) {
+	body := "品牌名: A\n# 现有 Listing\n## Title\nOld Title\n\n## Search Terms\nold terms\n# 关键词库\n- a"
+	got, rest := parseExistingListing(body)
+	if got != "## Title\nOld Title\n\n## Search Terms\nold terms" {
+		t.Fatalf("parseExistingListing got %q", got)
+	}
+	if rest != "品牌名: A\n# 关键词库\n- a" {
+		t.Fatalf("existing listing should be removed from body: %q", rest)
+	}
+	if got, rest := parseExistingListing("# 关键词库\n- a"); got != "" || rest != "# 关键词库\n- a" {
+		t.Fatalf("expected empty without block: %q %q", got, rest)
+	}
+}
+
+func TestParseReferenceTitle(t *testing.T) {
+	body := "品牌名: A\n# 参考标题（版本A）\n- BrandA Dry Erase Pockets 10 Pack\n\n# 关键词库\n- a"
+	title, rest := parseReferenceTitle(body)
+	if title != "BrandA Dry Erase Pockets 10 Pack" {
+		t.Fatalf("parseReferenceTitle got %q", title)
+	}
+	if rest != "品牌名: A\n# 关键词库\n- a" {
+		t.Fatalf("reference block should be removed from body: %q", rest)
+	}
+	if title, rest := parseReferenceTitle("# 关键词库\n- a"); title != "" || rest != "# 关键词库\n- a" {
+		t.Fatalf("expected no reference title: %q %q", title, rest)
+	}
+}
+
+func TestParseGenerationParams(t *testing.T) {
+	params, rest, err := parseGenerationParams("\n---\nbullets: 3\nnum: 2\n---\n品牌名: A\n# 关键词库\n- a")
+	if err != nil || params != "bullets: 3\nnum: 2" || rest != "品牌名: A\n# 关键词库\n- a" {
+		t.Fatalf("front matter: %q %q %v", params, rest, err)
+	}
+	body := "# 生成参数\n```yaml\n# 本品只写三条\nbullets: 3\n```\n品牌名: A\n# 关键词库\n- a"
+	params, rest, err = parseGenerationParams(body)
+	if err != nil || params != "# 本品只写三条\nbullets: 3" || rest != "品牌名: A\n# 关键词库\n- a" {
+		t.Fatalf("fenced block: %q %q %v", params, rest, err)
+	}
+	params, rest, err = parseGenerationParams("品牌名: A\n# 生成参数\nchar_tolerance: 5\n# 关键词库\n- a")
+	if err != nil || params != "char_tolerance: 5" || rest != "品牌名: A\n# 关键词库\n- a" {
+		t.Fatalf("plain block: %q %q %v", params, rest, err)
+	}
+	if params, rest, err := parseGenerationParams("# 关键词库\n- a"); err != nil || params != "" || rest != "# 关键词库\n- a" {
+		t.Fatalf("expected no params: %q %q %v", params, rest, err)
+	}
+	for _, bad := range []string{"---\nnum: 2\n品牌名: A", "---\nnum: 2\n---\n# 生成参数\nnum: 3", "# 生成参数\n```yaml\nnum: 2"} {
+		if _, _, err := parseGenerationParams(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
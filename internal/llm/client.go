@@ -10,6 +10,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"syl-listing/internal/cache"
 )
 
 type Request struct {
@@ -24,6 +26,14 @@ type Request struct {
 	Messages        []Message
 	JSONMode        bool
 	Timeout         time.Duration
+	// Stream 为 true 时 deepseek 与 openai chat 接口走 SSE 流式返回。
+	Stream bool
+	// OnChunk 在流式模式下每收到一段增量文本时回调。
+	OnChunk func(delta string)
+	// AbortAfterChars > 0 时，流式累计文本超过该字符数即提前中止。
+	AbortAfterChars int
+	// Candidate 为多候选时的候选序号，只计入缓存键，使相同提示词的不同候选各自缓存。
+	Candidate int
 }
 
 type Message struct {
@@ -34,10 +44,16 @@ type Message struct {
 type Response struct {
 	Text      string
 	LatencyMS int64
+	// Aborted 表示流式输出因超过 AbortAfterChars 被提前中止，Text 为已收到的部分。
+	Aborted bool
+	// Cached 表示结果来自本地响应缓存，未发起网络请求。
+	Cached bool
 }
 
 type Client struct {
 	httpClient *http.Client
+	cache      *cache.Store
+	mock       bool
 }
 
 func NewClient(timeout time.Duration) *Client {
@@ -47,53 +63,109 @@ func NewClient(timeout time.Duration) *Client {
 	return &Client{httpClient: &http.Client{Timeout: timeout}}
 }
 
+// SetTransport 指定发请求用的 Transport（录制或回放）；传 nil 即使用默认 Transport。
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.httpClient.Transport = rt
+}
+
+// SetCache 为 Generate 挂载响应缓存；传 nil 即关闭。
+func (c *Client) SetCache(store *cache.Store) {
+	c.cache = store
+}
+
+// UseMock 让 Generate 不论请求的 provider 都改由 mock 生成，提示词仍按原 provider 构造。
+func (c *Client) UseMock() {
+	c.mock = true
+}
+
+type cacheMaterial struct {
+	Provider        string    `json:"provider"`
+	BaseURL         string    `json:"base_url,omitempty"`
+	Model           string    `json:"model"`
+	APIMode         string    `json:"api_mode,omitempty"`
+	ReasoningEffort string    `json:"reasoning_effort,omitempty"`
+	JSONMode        bool      `json:"json_mode,omitempty"`
+	Messages        []Message `json:"messages"`
+	Candidate       int       `json:"candidate,omitempty"`
+}
+
 func (c *Client) Generate(ctx context.Context, req Request) (Response, error) {
 	provider := strings.ToLower(strings.TrimSpace(req.Provider))
 	if provider == "" {
 		provider = "openai"
 	}
+	if c.mock {
+		provider = "mock"
+	}
+	material := cacheMaterial{
+		Provider:        provider,
+		BaseURL:         strings.TrimRight(strings.TrimSpace(req.BaseURL), "/"),
+		Model:           strings.TrimSpace(req.Model),
+		APIMode:         strings.ToLower(strings.TrimSpace(req.APIMode)),
+		ReasoningEffort: strings.TrimSpace(req.ReasoningEffort),
+		JSONMode:        req.JSONMode,
+		Messages:        resolveMessages(req),
+		Candidate:       req.Candidate,
+	}
+	if text, ok := c.cache.Lookup("llm", material); ok {
+		if req.OnChunk != nil {
+			req.OnChunk(text)
+		}
+		return Response{Text: text, Cached: true}, nil
+	}
 	start := time.Now()
 	var (
-		text string
-		err  error
+		text    string
+		aborted bool
+		err     error
 	)
 
 	switch provider {
 	case "openai":
-		text, err = c.generateOpenAI(ctx, req)
+		text, aborted, err = c.generateOpenAI(ctx, req)
 	case "deepseek":
-		text, err = c.generateDeepSeek(ctx, req)
+		text, aborted, err = c.generateDeepSeek(ctx, req)
 	case "gemini":
 		text, err = c.generateGemini(ctx, req)
 	case "claude":
 		text, err = c.generateClaude(ctx, req)
+	case "mock":
+		text, err = generateMock(req)
 	default:
 		err = fmt.Errorf("不支持的 provider：%s", provider)
 	}
 	if err != nil {
 		return Response{}, err
 	}
-	return Response{Text: strings.TrimSpace(text), LatencyMS: time.Since(start).Milliseconds()}, nil
+	text = strings.TrimSpace(text)
+	if !aborted {
+		_ = c.cache.Save("llm", material, text)
+	}
+	return Response{Text: text, LatencyMS: time.Since(start).Milliseconds(), Aborted: aborted}, nil
 }
 
-func (c *Client) generateOpenAI(ctx context.Context, req Request) (string, error) {
+func (c *Client) generateOpenAI(ctx context.Context, req Request) (string, bool, error) {
 	mode := strings.ToLower(strings.TrimSpace(req.APIMode))
 	if mode == "" {
 		mode = "auto"
 	}
 	switch mode {
 	case "responses":
-		return c.openAIResponses(ctx, req)
+		text, err := c.openAIResponses(ctx, req)
+		return text, false, err
 	case "chat":
 		return c.openAIChat(ctx, req)
 	case "auto":
+		if req.Stream {
+			return c.openAIChat(ctx, req)
+		}
 		text, err := c.openAIResponses(ctx, req)
 		if err == nil {
-			return text, nil
+			return text, false, nil
 		}
 		return c.openAIChat(ctx, req)
 	default:
-		return "", fmt.Errorf("openai api_mode 不支持：%s", req.APIMode)
+		return "", false, fmt.Errorf("openai api_mode 不支持：%s", req.APIMode)
 	}
 }
 
@@ -152,7 +224,7 @@ func (c *Client) openAIResponses(ctx context.Context, req Request) (string, erro
 	return b.String(), nil
 }
 
-func (c *Client) openAIChat(ctx context.Context, req Request) (string, error) {
+func (c *Client) openAIChat(ctx context.Context, req Request) (string, bool, error) {
 	msgs := resolveMessages(req)
 	chatMsgs := make([]map[string]string, 0, len(msgs))
 	for _, m := range msgs {
@@ -169,6 +241,11 @@ func (c *Client) openAIChat(ctx context.Context, req Request) (string, error) {
 		payload["reasoning_effort"] = req.ReasoningEffort
 		payload["reasoning"] = map[string]any{"effort": req.ReasoningEffort}
 	}
+	endpoint := joinURL(req.BaseURL, "/v1/chat/completions")
+	if req.Stream {
+		payload["stream"] = true
+		return c.doStream(ctx, endpoint, req.APIKey, nil, payload, req, "chat completions")
+	}
 
 	var resp struct {
 		Choices []struct {
@@ -180,20 +257,20 @@ func (c *Client) openAIChat(ctx context.Context, req Request) (string, error) {
 			Message string `json:"message"`
 		} `json:"error"`
 	}
-	if err := c.doJSON(ctx, http.MethodPost, joinURL(req.BaseURL, "/v1/chat/completions"), req.APIKey, map[string]string{}, payload, &resp); err != nil {
-		return "", err
+	if err := c.doJSON(ctx, http.MethodPost, endpoint, req.APIKey, map[string]string{}, payload, &resp); err != nil {
+		return "", false, err
 	}
 	if resp.Error != nil {
-		return "", fmt.Errorf("chat completions 错误：%s", resp.Error.Message)
+		return "", false, fmt.Errorf("chat completions 错误：%s", resp.Error.Message)
 	}
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("chat completions 返回为空")
+		return "", false, fmt.Errorf("chat completions 返回为空")
 	}
 	text := strings.TrimSpace(resp.Choices[0].Message.Content)
 	if text == "" {
-		return "", fmt.Errorf("chat completions 内容为空")
+		return "", false, fmt.Errorf("chat completions 内容为空")
 	}
-	return text, nil
+	return text, false, nil
 }
 
 func (c *Client) generateGemini(ctx context.Context, req Request) (string, error) {
@@ -278,7 +355,7 @@ func (c *Client) generateClaude(ctx context.Context, req Request) (string, error
 	return "", fmt.Errorf("claude 返回文本为空")
 }
 
-func (c *Client) generateDeepSeek(ctx context.Context, req Request) (string, error) {
+func (c *Client) generateDeepSeek(ctx context.Context, req Request) (string, bool, error) {
 	msgs := resolveMessages(req)
 	chatMsgs := make([]map[string]string, 0, len(msgs))
 	for _, m := range msgs {
@@ -291,11 +368,15 @@ func (c *Client) generateDeepSeek(ctx context.Context, req Request) (string, err
 		"model":       req.Model,
 		"messages":    chatMsgs,
 		"temperature": 1.0,
-		"stream":      false,
+		"stream":      req.Stream,
 	}
 	if req.JSONMode {
 		payload["response_format"] = map[string]string{"type": "json_object"}
 	}
+	endpoint := joinURL(req.BaseURL, "/chat/completions")
+	if req.Stream {
+		return c.doStream(ctx, endpoint, req.APIKey, nil, payload, req, "deepseek chat completions")
+	}
 	var resp struct {
 		Choices []struct {
 			Message struct {
@@ -306,20 +387,20 @@ func (c *Client) generateDeepSeek(ctx context.Context, req Request) (string, err
 			Message string `json:"message"`
 		} `json:"error"`
 	}
-	if err := c.doJSON(ctx, http.MethodPost, joinURL(req.BaseURL, "/chat/completions"), req.APIKey, nil, payload, &resp); err != nil {
-		return "", err
+	if err := c.doJSON(ctx, http.MethodPost, endpoint, req.APIKey, nil, payload, &resp); err != nil {
+		return "", false, err
 	}
 	if resp.Error != nil {
-		return "", fmt.Errorf("deepseek chat completions 错误：%s", resp.Error.Message)
+		return "", false, fmt.Errorf("deepseek chat completions 错误：%s", resp.Error.Message)
 	}
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("deepseek chat completions 返回为空")
+		return "", false, fmt.Errorf("deepseek chat completions 返回为空")
 	}
 	text := strings.TrimSpace(resp.Choices[0].Message.Content)
 	if text == "" {
-		return "", fmt.Errorf("deepseek chat completions 内容为空")
+		return "", false, fmt.Errorf("deepseek chat completions 内容为空")
 	}
-	return text, nil
+	return text, false, nil
 }
 
 func (c *Client) doJSON(ctx context.Context, method, endpoint, bearer string, extraHeaders map[string]string, in any, out any) error {
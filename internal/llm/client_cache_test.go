@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"syl-listing/internal/cache"
+)
+
+func TestGenerateUsesResponseCache(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":"answer-%d"}}]}`, n)
+	}))
+	defer ts.Close()
+
+	store, err := cache.New(t.TempDir(), cache.ModeReadWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(0)
+	c.SetCache(store)
+	req := Request{Provider: "deepseek", BaseURL: ts.URL, Model: "deepseek-chat", APIKey: "k", SystemPrompt: "s", UserPrompt: "u"}
+	first, err := c.Generate(context.Background(), req)
+	if err != nil || first.Cached || first.Text != "answer-1" {
+		t.Fatalf("first call: %+v %v", first, err)
+	}
+	second, err := c.Generate(context.Background(), req)
+	if err != nil || !second.Cached || second.Text != "answer-1" {
+		t.Fatalf("second call should hit cache: %+v %v", second, err)
+	}
+
+	req.APIKey = "other-key"
+	req.BaseURL = ts.URL + "/"
+	if resp, _ := c.Generate(context.Background(), req); !resp.Cached {
+		t.Fatalf("api key and a trailing slash on base url must not change the cache key")
+	}
+	req.JSONMode = true
+	if resp, _ := c.Generate(context.Background(), req); resp.Cached {
+		t.Fatalf("json mode must be part of the cache key")
+	}
+	req.Candidate = 2
+	if resp, _ := c.Generate(context.Background(), req); resp.Cached {
+		t.Fatalf("candidate must be part of the cache key")
+	}
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"other"}}]}`)
+	}))
+	defer other.Close()
+	req.BaseURL = other.URL
+	if resp, _ := c.Generate(context.Background(), req); resp.Cached || resp.Text != "other" {
+		t.Fatalf("base url must be part of the cache key: %+v", resp)
+	}
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Fatalf("expected 4 network calls, got %d", got)
+	}
+}
@@ -106,7 +106,7 @@ func TestGenerateGeminiAndClaudeAndUnsupported(t *testing.T) {
 
 func TestGenerateOpenAIUnsupportedMode(t *testing.T) {
 	c := NewClient(0)
-	_, err := c.generateOpenAI(context.Background(), Request{APIMode: "x"})
+	_, _, err := c.generateOpenAI(context.Background(), Request{APIMode: "x"})
 	if err == nil {
 		t.Fatalf("expected api_mode error")
 	}
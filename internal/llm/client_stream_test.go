@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newSSEServer(t *testing.T, path string, deltas []string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["stream"] != true {
+			http.Error(w, "expected stream=true", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		fmt.Fprint(w, ": keep-alive\n\n")
+		for _, d := range deltas {
+			raw, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]any{"content": d}}}})
+			fmt.Fprintf(w, "data: %s\n\n", raw)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+func TestGenerateDeepSeekStream(t *testing.T) {
+	ts := newSSEServer(t, "/chat/completions", []string{"hello ", "stream ", "world"})
+	defer ts.Close()
+
+	var chunks []string
+	c := NewClient(0)
+	resp, err := c.Generate(context.Background(), Request{
+		Provider:     "deepseek",
+		BaseURL:      ts.URL,
+		Model:        "deepseek-chat",
+		APIKey:       "k",
+		SystemPrompt: "s",
+		UserPrompt:   "u",
+		Stream:       true,
+		OnChunk:      func(d string) { chunks = append(chunks, d) },
+	})
+	if err != nil || resp.Text != "hello stream world" || resp.Aborted {
+		t.Fatalf("stream failed: resp=%+v err=%v", resp, err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %v", chunks)
+	}
+}
+
+func TestGenerateOpenAIChatStreamEarlyAbort(t *testing.T) {
+	ts := newSSEServer(t, "/v1/chat/completions", []string{"aaaaa", "bbbbb", "ccccc", "ddddd"})
+	defer ts.Close()
+
+	c := NewClient(0)
+	resp, err := c.Generate(context.Background(), Request{
+		Provider:        "openai",
+		APIMode:         "auto",
+		BaseURL:         ts.URL,
+		Model:           "m",
+		APIKey:          "k",
+		UserPrompt:      "u",
+		Stream:          true,
+		AbortAfterChars: 8,
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !resp.Aborted || resp.Text != "aaaaabbbbb" {
+		t.Fatalf("expected early abort after second chunk, got %+v", resp)
+	}
+}
+
+func TestDoStreamErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/http/chat/completions":
+			http.Error(w, "bad", http.StatusBadRequest)
+		case "/apierr/chat/completions":
+			fmt.Fprint(w, "data: {\"error\":{\"message\":\"boom\"}}\n\n")
+		case "/badjson/chat/completions":
+			fmt.Fprint(w, "data: {not-json\n\n")
+		default:
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(0)
+	cases := map[string]string{
+		"/http":    "HTTP 400",
+		"/apierr":  "boom",
+		"/badjson": "解析流式响应失败",
+		"/empty":   "内容为空",
+	}
+	for prefix, want := range cases {
+		_, err := c.Generate(context.Background(), Request{Provider: "deepseek", BaseURL: ts.URL + prefix, APIKey: "k", UserPrompt: "u", Stream: true})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", prefix, want, err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mockRule 只解析 mock 生成所需的字段：system 消息开头的分段规则或评审量表 YAML。
+type mockRule struct {
+	Section string `yaml:"section"`
+	Output  struct {
+		Format     string `yaml:"format"`
+		Lines      int    `yaml:"lines"`
+		Paragraphs int    `yaml:"paragraphs"`
+	} `yaml:"output"`
+	Constraints struct {
+		MaxChars        mockIntValue `yaml:"max_chars"`
+		MinCharsPerLine mockIntValue `yaml:"min_chars_per_line"`
+		MaxCharsPerLine mockIntValue `yaml:"max_chars_per_line"`
+	} `yaml:"constraints"`
+	Scale    int `yaml:"scale"`
+	Criteria []struct {
+		Name string `yaml:"name"`
+	} `yaml:"criteria"`
+	ReviewSections []string `yaml:"review_sections"`
+}
+
+type mockIntValue struct {
+	Value int `yaml:"value"`
+}
+
+var (
+	mockFillerWords    = []string{"mock", "placeholder", "sample", "text", "rule", "preview", "draft", "demo", "filler", "content", "layout", "check", "offline", "stub", "example", "value", "line", "entry", "field", "output"}
+	mockDefaultLineLen = 80
+)
+
+// generateMock 不访问网络，按 system 消息开头的 YAML 与用户消息中的关键词，返回满足行数与长度约束的占位文本；有评分项时按评审量表打满分。
+func generateMock(req Request) (string, error) {
+	system, user := "", ""
+	for _, m := range resolveMessages(req) {
+		switch m.Role {
+		case "system":
+			if system == "" {
+				system = m.Content
+			}
+		case "user":
+			if user == "" {
+				user = m.Content
+			}
+		}
+	}
+	// YAML 之后的协议说明以空行加【】标题开始。
+	if idx := strings.Index(system, "\n\n【"); idx >= 0 {
+		system = system[:idx]
+	}
+	var rule mockRule
+	_ = yaml.Unmarshal([]byte(system), &rule)
+	if len(rule.Criteria) > 0 {
+		return mockReview(rule)
+	}
+	step := strings.TrimSpace(rule.Section)
+	keywords := mockKeywords(user)
+
+	if rule.Output.Paragraphs > 0 {
+		paras := make([]string, 0, rule.Output.Paragraphs)
+		for i := 1; i <= rule.Output.Paragraphs; i++ {
+			paras = append(paras, mockLine(fmt.Sprintf("%s paragraph %d.", step, i), keywords, mockDefaultLineLen*2))
+		}
+		return strings.Join(paras, "\n\n"), nil
+	}
+
+	lines := rule.Output.Lines
+	if lines <= 0 {
+		lines = 1
+	}
+	if lines == 1 && rule.Constraints.MinCharsPerLine.Value == 0 && rule.Constraints.MaxCharsPerLine.Value == 0 {
+		return mockLine("", keywords, mockLineTarget(0, rule.Constraints.MaxChars.Value)), nil
+	}
+	target := mockLineTarget(rule.Constraints.MinCharsPerLine.Value, rule.Constraints.MaxCharsPerLine.Value)
+	items := make([]string, 0, lines)
+	for i := 1; i <= lines; i++ {
+		items = append(items, mockLine(fmt.Sprintf("%s %d", step, i), keywords, target))
+	}
+	if strings.EqualFold(strings.TrimSpace(rule.Output.Format), "json_object") || req.JSONMode {
+		return mockJSON(map[string]any{step: items})
+	}
+	var b strings.Builder
+	for i, it := range items {
+		b.WriteString(fmt.Sprintf("%d) %s\n", i+1, it))
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// mockReview 给 review_sections 中每个分段的每个评分项打满分。
+func mockReview(rule mockRule) (string, error) {
+	scale := rule.Scale
+	if scale <= 1 {
+		scale = 5
+	}
+	scores := map[string]int{}
+	for _, c := range rule.Criteria {
+		scores[strings.TrimSpace(c.Name)] = scale
+	}
+	sections := make([]map[string]any, 0, len(rule.ReviewSections))
+	for _, name := range rule.ReviewSections {
+		if name = strings.TrimSpace(name); name != "" {
+			sections = append(sections, map[string]any{"section": name, "scores": scores, "comment": "mock 评审：未发现问题"})
+		}
+	}
+	return mockJSON(map[string]any{"sections": sections})
+}
+
+// mockKeywords 读取用户消息中 keywords: 列表，mock 文本以它们打头。
+func mockKeywords(user string) []string {
+	out := make([]string, 0)
+	idx := strings.Index(user, "\nkeywords:\n")
+	if idx < 0 {
+		return out
+	}
+	for _, line := range strings.Split(user[idx+len("\nkeywords:\n"):], "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "- ") {
+			break
+		}
+		if kw := strings.TrimSpace(strings.TrimPrefix(line, "- ")); kw != "" {
+			out = append(out, kw)
+		}
+	}
+	return out
+}
+
+// mockLineTarget 取规则区间中点；只有上限时取上限的 3/4。
+func mockLineTarget(minV, maxV int) int {
+	switch {
+	case minV > 0 && maxV > 0:
+		return (minV + maxV) / 2
+	case maxV > 0:
+		return maxV * 3 / 4
+	case minV > 0:
+		return minV + 10
+	default:
+		return mockDefaultLineLen
+	}
+}
+
+// mockLine 以关键词打头、填充词补齐，生成长度不超过 target 的单行文本。
+func mockLine(prefix string, keywords []string, target int) string {
+	words := make([]string, 0, 16)
+	words = append(words, keywords...)
+	if p := strings.TrimSpace(prefix); p != "" {
+		words = append(words, p)
+	}
+	parts := make([]string, 0, 32)
+	n := 0
+	appendWord := func(w string) bool {
+		size := utf8.RuneCountInString(w)
+		if n > 0 {
+			size++
+		}
+		if n > 0 && n+size > target {
+			return false
+		}
+		parts = append(parts, w)
+		n += size
+		return true
+	}
+	for _, w := range words {
+		if !appendWord(w) {
+			break
+		}
+	}
+	// 放不下的填充词跳过，继续尝试更短的词，直到贴近 target；每个填充词最多用两次，避免触发单词重复检查。
+	for i := 0; n < target && i < 2*len(mockFillerWords); i++ {
+		appendWord(mockFillerWords[i%len(mockFillerWords)])
+	}
+	if len(parts) == 0 {
+		return "mock"
+	}
+	return strings.Join(parts, " ")
+}
+
+func mockJSON(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("mock 输出编码失败：%w", err)
+	}
+	return string(raw), nil
+}
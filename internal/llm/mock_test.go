@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const mockTitleRule = `section: title
+output:
+  format: plain_text
+  lines: 1
+constraints:
+  max_chars:
+    value: 120
+`
+
+const mockBulletsRule = `section: bullets
+output:
+  format: json_object
+  lines: 3
+constraints:
+  min_chars_per_line:
+    value: 60
+  max_chars_per_line:
+    value: 100
+`
+
+func TestMockTitleRespectsMaxChars(t *testing.T) {
+	c := NewClient(0)
+	resp, err := c.Generate(context.Background(), Request{Provider: "mock", SystemPrompt: mockTitleRule, UserPrompt: "task: title\nkeywords:\n  - alpha\n  - beta\n"})
+	if err != nil {
+		t.Fatalf("mock title error: %v", err)
+	}
+	n := utf8.RuneCountInString(resp.Text)
+	if n > 120 || n < 80 || !strings.HasPrefix(resp.Text, "alpha beta") {
+		t.Fatalf("unexpected mock title (%d): %q", n, resp.Text)
+	}
+}
+
+func TestMockBulletsJSONAndItemRepair(t *testing.T) {
+	c := NewClient(0)
+	system := mockBulletsRule + "\n\n【JSON协议】只输出 JSON。"
+	resp, err := c.Generate(context.Background(), Request{Provider: "mock", SystemPrompt: system, UserPrompt: "bullets", JSONMode: true})
+	if err != nil {
+		t.Fatalf("mock bullets error: %v", err)
+	}
+	if !strings.HasPrefix(resp.Text, `{"bullets":[`) || strings.Count(resp.Text, `"bullets `) != 3 {
+		t.Fatalf("unexpected mock bullets: %s", resp.Text)
+	}
+}
+
+func TestUseMockKeepsPromptButSkipsNetwork(t *testing.T) {
+	c := NewClient(0)
+	c.UseMock()
+	resp, err := c.Generate(context.Background(), Request{Provider: "deepseek", BaseURL: "http://127.0.0.1:1", SystemPrompt: mockTitleRule, UserPrompt: "user"})
+	if err != nil {
+		t.Fatalf("mock generate error: %v", err)
+	}
+	if utf8.RuneCountInString(resp.Text) > 120 || !strings.HasPrefix(resp.Text, "mock") {
+		t.Fatalf("unexpected mock output: %q", resp.Text)
+	}
+}
+
+func TestMockParagraphsAndLineTarget(t *testing.T) {
+	c := NewClient(0)
+	rule := "section: description\noutput:\n  paragraphs: 2\n"
+	resp, err := c.Generate(context.Background(), Request{Provider: "mock", SystemPrompt: rule, UserPrompt: "description"})
+	if err != nil {
+		t.Fatalf("mock description error: %v", err)
+	}
+	if len(strings.Split(resp.Text, "\n\n")) != 2 {
+		t.Fatalf("expected 2 paragraphs, got %q", resp.Text)
+	}
+	if got := mockLineTarget(230, 320); got != 275 {
+		t.Fatalf("unexpected target: %d", got)
+	}
+	if got := mockLineTarget(0, 200); got != 150 {
+		t.Fatalf("unexpected target: %d", got)
+	}
+	if got := mockLine("", nil, 0); got != "mock" {
+		t.Fatalf("unexpected empty mock line: %q", got)
+	}
+}
+
+func TestMockReviewScoresListedSections(t *testing.T) {
+	c := NewClient(0)
+	system := "scale: 5\ncriteria:\n  - name: clarity\nreview_sections: [title, bullets]\n\n【JSON协议】只输出 JSON。"
+	resp, err := c.Generate(context.Background(), Request{Provider: "mock", SystemPrompt: system, UserPrompt: "review", JSONMode: true})
+	if err != nil {
+		t.Fatalf("mock review error: %v", err)
+	}
+	if strings.Count(resp.Text, `"clarity":5`) != 2 || !strings.Contains(resp.Text, `"section":"bullets"`) {
+		t.Fatalf("unexpected mock review: %s", resp.Text)
+	}
+}
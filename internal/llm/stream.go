@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// doStream 发送 chat completions 流式请求并按 SSE 协议逐段读取增量文本。
+// 返回值 aborted 为 true 表示因累计长度超过 req.AbortAfterChars 而主动断开。
+func (c *Client) doStream(ctx context.Context, endpoint, bearer string, extraHeaders map[string]string, payload map[string]any, req Request, label string) (string, bool, error) {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(payload); err != nil {
+		return "", false, fmt.Errorf("编码请求失败：%w", err)
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(streamCtx, http.MethodPost, endpoint, buf)
+	if err != nil {
+		return "", false, fmt.Errorf("创建请求失败：%w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if strings.TrimSpace(bearer) != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	for k, v := range extraHeaders {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return "", false, fmt.Errorf("请求失败：%w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", false, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", false, fmt.Errorf("解析流式响应失败：%w; 原始片段: %s", err, truncate(data, 800))
+		}
+		if chunk.Error != nil {
+			return "", false, fmt.Errorf("%s 错误：%s", label, chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			delta := choice.Delta.Content
+			if delta == "" {
+				continue
+			}
+			text.WriteString(delta)
+			if req.OnChunk != nil {
+				req.OnChunk(delta)
+			}
+		}
+		if req.AbortAfterChars > 0 && utf8.RuneCountInString(strings.TrimSpace(text.String())) > req.AbortAfterChars {
+			cancel()
+			return text.String(), true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", false, fmt.Errorf("读取流式响应失败：%w", err)
+	}
+	out := strings.TrimSpace(text.String())
+	if out == "" {
+		return "", false, fmt.Errorf("%s 内容为空", label)
+	}
+	return out, false, nil
+}
@@ -43,6 +43,7 @@ type Event struct {
 	ResponseText  string   `json:"response_text,omitempty"`
 	ResponseTexts []string `json:"response_texts,omitempty"`
 	Balance       string   `json:"balance,omitempty"`
+	Cache         string   `json:"cache,omitempty"`
 }
 
 func New(stdout io.Writer, logFile string, verbose bool, showCandidate bool) (*Logger, io.Closer, error) {
@@ -131,12 +132,74 @@ func (l *Logger) formatHuman(ev Event) string {
 		return fmt.Sprintf("[%s] 输出文件名分配失败：%s", l.jobTag(ev), fallback(ev.Error, "-"))
 	case "generate_failed":
 		return fmt.Sprintf("[%s] 生成失败：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "generate_cancelled":
+		return fmt.Sprintf("[%s] 已取消", l.jobTag(ev))
+	case "run_cancelled":
+		return fmt.Sprintf("任务中断：%s", fallback(ev.Error, "-"))
 	case "generate_ok":
 		return fmt.Sprintf("[%s] %s生成完成（%s）", l.jobTag(ev), strings.ToUpper(fallback(ev.Lang, "-")), formatHumanDurationMS(ev.LatencyMS))
 	case "write_failed":
 		return fmt.Sprintf("[%s] %s 写入失败：%s", l.jobTag(ev), fallback(ev.OutputFile, "-"), fallback(ev.Error, "-"))
 	case "write_ok":
 		return fmt.Sprintf("[%s] %s 已写入：%s", l.jobTag(ev), strings.ToUpper(fallback(ev.Lang, "-")), fallback(ev.OutputFile, "-"))
+	case "dry_run_prompts":
+		return fmt.Sprintf("[%s] 提示词已导出：%s", l.jobTag(ev), fallback(ev.OutputFile, "-"))
+	case "ranking_written":
+		return fmt.Sprintf("[%s] 候选评分已写入：%s（最佳：候选 %d）", l.jobTag(ev), fallback(ev.OutputFile, "-"), ev.Attempt)
+	case "ranking_failed":
+		return fmt.Sprintf("[%s] 候选评分输出失败：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "best_written":
+		return fmt.Sprintf("[%s] 最佳候选 %d 已输出：%s", l.jobTag(ev), ev.Attempt, fallback(ev.OutputFile, "-"))
+	case "review_ok":
+		return fmt.Sprintf("[%s] 评审完成：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "review_failed":
+		return fmt.Sprintf("[%s] 评审失败：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "review_repaired":
+		return fmt.Sprintf("[%s] 低分分段已按评语修复：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "review_repair_failed":
+		return fmt.Sprintf("[%s] 低分分段修复失败，保留原文：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "review_unavailable":
+		return fmt.Sprintf("[%s] 评审未启用：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "translation_qa":
+		return fmt.Sprintf("[%s] 回译质检：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "translation_qa_low":
+		return fmt.Sprintf("[%s] 回译质检低分：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "translation_qa_failed":
+		return fmt.Sprintf("[%s] 回译质检失败，保留原译文：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "translation_qa_done":
+		return fmt.Sprintf("[%s] 回译质检完成：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "effective_rules":
+		return fmt.Sprintf("[%s] 生效规则：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "edit_mode":
+		return fmt.Sprintf("[%s] 编辑模式：以现有 Listing 为基准（%d 个分段）", l.jobTag(ev), ev.Attempt)
+	case "edit_section_kept":
+		return fmt.Sprintf("[%s] 现有%s已满足规则，保留不改", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "edit_section_revise":
+		return fmt.Sprintf("[%s] 按最小改动修订现有分段：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "edit_diff":
+		return fmt.Sprintf("[%s] 修订对比：%s", l.jobTag(ev), fallback(ev.OutputFile, "-"))
+	case "regen_written":
+		return fmt.Sprintf("[%s] 已重新生成 %s，原文件备份为 .bak：%s", l.jobTag(ev), fallback(ev.Error, "-"), fallback(ev.OutputFile, "-"))
+	case "title_compare":
+		return fmt.Sprintf("[%s] 已写出标题 A/B 对比：%s", l.jobTag(ev), fallback(ev.OutputFile, "-"))
+	case "inspect_written":
+		return fmt.Sprintf("[%s] 审阅改动已保存（%s），原文件备份为 .bak：%s", l.jobTag(ev), fallback(ev.Error, "-"), fallback(ev.OutputFile, "-"))
+	case "tm_unavailable":
+		return fmt.Sprintf("翻译记忆不可用，本次不复用：%s", fallback(ev.Error, "-"))
+	case "library_unavailable":
+		return fmt.Sprintf("范例库不可用，本次不注入范例：%s", fallback(ev.Error, "-"))
+	case "tm_save_failed":
+		return fmt.Sprintf("翻译记忆保存失败（%s）：%s", fallback(ev.OutputFile, "-"), fallback(ev.Error, "-"))
+	case "title_too_similar":
+		return fmt.Sprintf("[%s] 标题与同批候选过于相似，重新生成（第 %d 次）：%s", l.jobTag(ev), ev.Attempt, fallback(ev.Error, "-"))
+	case "title_similar_kept":
+		return fmt.Sprintf("[%s] 标题重新生成 %d 次后仍相似，保留：%s", l.jobTag(ev), ev.Attempt, fallback(ev.Error, "-"))
+	case "compose_written":
+		return fmt.Sprintf("[%s] 组合 listing 已写入：%s（%s）", l.jobTag(ev), fallback(ev.OutputFile, "-"), fallback(ev.Error, "-"))
+	case "compose_skipped":
+		return fmt.Sprintf("[%s] 跳过组合：%s", l.jobTag(ev), fallback(ev.Error, "-"))
+	case "compose_failed":
+		return fmt.Sprintf("[%s] 组合失败：%s", l.jobTag(ev), fallback(ev.Error, "-"))
 	case "balance":
 		return ""
 	case "balance_failed":
@@ -163,6 +226,10 @@ func (l *Logger) formatHuman(ev Event) string {
 		step := strings.TrimPrefix(ev.Event, "validate_error_")
 		return fmt.Sprintf("[%s] %s 校验失败：%s", l.jobTag(ev), humanStepLabel(step), fallback(ev.Error, "-"))
 	}
+	if strings.HasPrefix(ev.Event, "compose_") {
+		step := strings.TrimPrefix(ev.Event, "compose_")
+		return fmt.Sprintf("[%s] %s完成（本地拼接）", l.jobTag(ev), humanStepLabel(step))
+	}
 	if strings.HasPrefix(ev.Event, "thinking_fallback_") {
 		step := strings.TrimPrefix(ev.Event, "thinking_fallback_")
 		return fmt.Sprintf("[%s] %s 启用思考兜底（model=%s，第%d次）", l.jobTag(ev), humanStepLabel(step), fallback(ev.Model, "-"), ev.Attempt)
@@ -176,6 +243,7 @@ func (l *Logger) humanAPIRequest(ev Event) string {
 	if !show {
 		return ""
 	}
+	label, groupKey = nativeCNLabel(ev, label, groupKey)
 	if ev.Attempt > 1 {
 		return fmt.Sprintf("[%s] %s（第%d次）", l.jobTag(ev), label, ev.Attempt)
 	}
@@ -188,6 +256,7 @@ func (l *Logger) humanAPIResponse(ev Event) string {
 	if !show {
 		return ""
 	}
+	label, groupKey = nativeCNLabel(ev, label, groupKey)
 	return l.onceLine(
 		l.jobTag(ev)+":resp:"+groupKey,
 		fmt.Sprintf("[%s] %s（%s）", l.jobTag(ev), label, formatHumanDurationMS(ev.LatencyMS)),
@@ -202,6 +271,11 @@ func (l *Logger) onceLine(key, line string) string {
 	return line
 }
 
+// ShowCandidates 让人类可读输出带上候选编号；需在并发输出开始前调用。
+func (l *Logger) ShowCandidates() {
+	l.showCandidate = true
+}
+
 func (l *Logger) jobTag(ev Event) string {
 	base := strings.TrimSpace(ev.Input)
 	if base == "" {
@@ -215,6 +289,14 @@ func (l *Logger) jobTag(ev Event) string {
 	return base
 }
 
+// nativeCNLabel 把按中文规则直接生成（Lang=cn 的生成请求）的标签从“英文”改为“中文”，并与英文分组区分。
+func nativeCNLabel(ev Event, label, groupKey string) (string, string) {
+	if ev.Lang != "cn" || !strings.HasPrefix(groupKey, "gen_") {
+		return label, groupKey
+	}
+	return strings.Replace(label, "英文", "中文", 1), "cn_" + groupKey
+}
+
 func humanRequestLabel(step string) (string, string, bool) {
 	if strings.HasPrefix(step, "translate_") {
 		label, key, ok := humanTranslateLabel(strings.TrimPrefix(step, "translate_"))
@@ -261,6 +343,8 @@ func humanGenerateLabel(step string) (string, string, bool) {
 		return "英文产品描述生成", "gen_description", true
 	case step == "search_terms":
 		return "英文搜索词生成", "gen_search_terms", true
+	case step == "review":
+		return "英文评审", "gen_review", true
 	default:
 		return "", "", false
 	}
@@ -291,6 +375,8 @@ func humanStepLabel(step string) string {
 			return "英文五点描述修复"
 		}
 		return fmt.Sprintf("英文五点第%s条修复", idx)
+	case strings.HasPrefix(step, "translate_back_"):
+		return "中文回译质检"
 	case strings.HasPrefix(step, "translate_bullet_"):
 		return "中文五点描述翻译"
 	case strings.HasPrefix(step, "description_"), strings.HasPrefix(step, "translate_description_"):
@@ -84,3 +84,18 @@ func TestLoggerConcurrentOnceBehavior(t *testing.T) {
 		t.Fatalf("expected only one once-line, got %d lines: %s", cnt, text)
 	}
 }
+
+func TestNativeCNGenerateLabel(t *testing.T) {
+	var out bytes.Buffer
+	l, _, err := New(&out, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Emit(Event{Event: "api_request_title", Input: "/tmp/a.md"})
+	l.Emit(Event{Event: "api_request_title", Input: "/tmp/a.md", Lang: "cn"})
+	l.Emit(Event{Event: "api_response_title", Input: "/tmp/a.md", Lang: "cn", LatencyMS: 10})
+	got := out.String()
+	if !strings.Contains(got, "开始英文标题生成") || !strings.Contains(got, "开始中文标题生成") || !strings.Contains(got, "中文标题生成完成") {
+		t.Fatalf("native cn labels missing: %s", got)
+	}
+}
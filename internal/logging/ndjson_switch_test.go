@@ -15,9 +15,40 @@ func TestFormatHumanSwitchCases(t *testing.T) {
 		"validation_warning",
 		"name_failed",
 		"generate_failed",
+		"generate_cancelled",
+		"run_cancelled",
 		"generate_ok",
 		"write_failed",
 		"write_ok",
+		"dry_run_prompts",
+		"ranking_written",
+		"ranking_failed",
+		"best_written",
+		"review_ok",
+		"review_failed",
+		"review_repaired",
+		"review_repair_failed",
+		"review_unavailable",
+		"translation_qa",
+		"translation_qa_low",
+		"translation_qa_failed",
+		"translation_qa_done",
+		"effective_rules",
+		"edit_mode",
+		"edit_section_kept",
+		"edit_section_revise",
+		"edit_diff",
+		"regen_written",
+		"inspect_written",
+		"title_compare",
+		"tm_unavailable",
+		"tm_save_failed",
+		"library_unavailable",
+		"title_too_similar",
+		"title_similar_kept",
+		"compose_written",
+		"compose_skipped",
+		"compose_failed",
 		"balance",
 		"balance_failed",
 		"finished",
@@ -26,3 +57,11 @@ func TestFormatHumanSwitchCases(t *testing.T) {
 		_ = l.formatHuman(Event{Event: c, Input: "/tmp/a.md", Candidate: 1, Lang: "en", Error: "err", LatencyMS: 1000, OutputFile: "f"})
 	}
 }
+
+func TestFormatHumanComposeEvent(t *testing.T) {
+	l := &Logger{onceKeys: map[string]struct{}{}}
+	got := l.formatHuman(Event{Event: "compose_search_terms", Input: "/tmp/a.md"})
+	if got != "[a.md] 英文搜索词生成完成（本地拼接）" {
+		t.Fatalf("unexpected compose line: %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type File struct {
+	Path string
+	Data []byte
+}
+
+// rename 供测试替换。
+var rename = os.Rename
+
+// WriteFilesAtomic 经同目录临时文件 rename 写入；多个文件时某个 rename 失败会倒序恢复已替换的文件。
+func WriteFilesAtomic(ctx context.Context, files ...File) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	temps := make([]string, 0, len(files))
+	backups := make([]string, len(files))
+	cleanup := func() {
+		for _, tmp := range temps {
+			_ = os.Remove(tmp)
+		}
+		for _, b := range backups {
+			if b != "" {
+				_ = os.Remove(b)
+			}
+		}
+	}
+	for _, f := range files {
+		tmp, err := writeTemp(f)
+		if err != nil {
+			cleanup()
+			return err
+		}
+		temps = append(temps, tmp)
+	}
+	if len(files) > 1 {
+		for i, f := range files {
+			orig, err := os.ReadFile(f.Path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				cleanup()
+				return fmt.Errorf("写入 %s 失败：%w", f.Path, err)
+			}
+			if backups[i], err = writeTemp(File{Path: f.Path, Data: orig}); err != nil {
+				cleanup()
+				return err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		cleanup()
+		return err
+	}
+	for i, f := range files {
+		if err := rename(temps[i], f.Path); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if backups[j] != "" {
+					_ = rename(backups[j], files[j].Path)
+				} else {
+					_ = os.Remove(files[j].Path)
+				}
+			}
+			cleanup()
+			return fmt.Errorf("写入 %s 失败：%w", f.Path, err)
+		}
+	}
+	cleanup()
+	return nil
+}
+
+func writeTemp(f File) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), "."+filepath.Base(f.Path)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("写入 %s 失败：%w", f.Path, err)
+	}
+	if _, err := tmp.Write(f.Data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入 %s 失败：%w", f.Path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入 %s 失败：%w", f.Path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入 %s 失败：%w", f.Path, err)
+	}
+	return tmp.Name(), nil
+}
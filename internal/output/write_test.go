@@ -0,0 +1,87 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFilesAtomic(t *testing.T) {
+	d := t.TempDir()
+	en := filepath.Join(d, "listing_x_en.md")
+	cn := filepath.Join(d, "listing_x_cn.md")
+	if err := WriteFilesAtomic(context.Background(), File{Path: en, Data: []byte("en")}, File{Path: cn, Data: []byte("cn")}); err != nil {
+		t.Fatalf("WriteFilesAtomic error: %v", err)
+	}
+	raw, _ := os.ReadFile(cn)
+	if string(raw) != "cn" {
+		t.Fatalf("unexpected content: %q", raw)
+	}
+	entries, _ := os.ReadDir(d)
+	if len(entries) != 2 {
+		t.Fatalf("temp files left behind: %v", entries)
+	}
+}
+
+func TestWriteFilesAtomicCancelledLeavesNothing(t *testing.T) {
+	d := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := WriteFilesAtomic(ctx, File{Path: filepath.Join(d, "a_en.md"), Data: []byte("en")})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled error, got %v", err)
+	}
+	entries, _ := os.ReadDir(d)
+	if len(entries) != 0 {
+		t.Fatalf("expected empty dir, got %v", entries)
+	}
+	if err := WriteFilesAtomic(nil, File{Path: filepath.Join(d, "missing", "a.md"), Data: []byte("x")}); err == nil {
+		t.Fatalf("expected error for missing dir")
+	}
+}
+
+func TestWriteFilesAtomicRollsBackPairOnRenameFailure(t *testing.T) {
+	d := t.TempDir()
+	en := filepath.Join(d, "listing_x_en.md")
+	cn := filepath.Join(d, "listing_x_cn.md")
+	cnNew := filepath.Join(d, "listing_y_cn.md")
+	if err := os.WriteFile(en, []byte("old en"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cn, []byte("old cn"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	rename = func(from, to string) error {
+		calls++
+		if calls == 2 {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	if err := WriteFilesAtomic(context.Background(), File{Path: en, Data: []byte("new en")}, File{Path: cn, Data: []byte("new cn")}); err == nil {
+		t.Fatalf("expected rename failure")
+	}
+	for path, want := range map[string]string{en: "old en", cn: "old cn"} {
+		if raw, _ := os.ReadFile(path); string(raw) != want {
+			t.Fatalf("%s should be restored, got %q", path, raw)
+		}
+	}
+
+	calls = 0
+	enNew := filepath.Join(d, "listing_y_en.md")
+	if err := WriteFilesAtomic(context.Background(), File{Path: enNew, Data: []byte("en")}, File{Path: cnNew, Data: []byte("cn")}); err == nil {
+		t.Fatalf("expected rename failure")
+	}
+	if _, err := os.Stat(enNew); !os.IsNotExist(err) {
+		t.Fatalf("new en without cn should be removed: %v", err)
+	}
+	entries, _ := os.ReadDir(d)
+	if len(entries) != 2 {
+		t.Fatalf("temp or backup files left behind: %v", entries)
+	}
+}
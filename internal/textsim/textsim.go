@@ -0,0 +1,86 @@
+package textsim
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize 去掉首尾空白、合并连续空白并转小写，用作比较与去重的键。
+func Normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// Words 把文本切成小写词：英文与数字按连续字母数字成词，中文逐字成词。
+func Words(text string) []string {
+	var out []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			out = append(out, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			out = append(out, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// WordSet 返回若干文本的词集合。
+func WordSet(texts ...string) map[string]bool {
+	out := map[string]bool{}
+	for _, text := range texts {
+		for _, w := range Words(text) {
+			out[w] = true
+		}
+	}
+	return out
+}
+
+// Dice 计算两个词集合的 Dice 系数；任一为空时为 0。
+func Dice(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range a {
+		if b[t] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+// NgramJaccard 计算两段文本的词 n-gram Jaccard 相似度；较短文本词数不足 n 时按其词数取 n。
+func NgramJaccard(a, b string, n int) float64 {
+	wa, wb := Words(a), Words(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	n = min(max(n, 1), len(wa), len(wb))
+	ga, gb := ngrams(wa, n), ngrams(wb, n)
+	inter := 0
+	for g := range ga {
+		if gb[g] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(ga)+len(gb)-inter)
+}
+
+func ngrams(words []string, n int) map[string]bool {
+	out := map[string]bool{}
+	for i := 0; i+n <= len(words); i++ {
+		out[strings.Join(words[i:i+n], " ")] = true
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package textsim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordsSplitsLatinWordsAndHanChars(t *testing.T) {
+	got := Words("USB-C 收纳盒, 2 Pack")
+	want := []string{"usb", "c", "收", "纳", "盒", "2", "pack"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected words: %v", got)
+	}
+	if Normalize("  Foo   BAR ") != "foo bar" {
+		t.Fatalf("unexpected normalize: %q", Normalize("  Foo   BAR "))
+	}
+}
+
+func TestDice(t *testing.T) {
+	if got := Dice(WordSet("alpha beta"), WordSet("beta", "gamma")); got != 0.5 {
+		t.Fatalf("unexpected dice: %v", got)
+	}
+	if Dice(WordSet(""), WordSet("alpha")) != 0 {
+		t.Fatalf("empty set should not match")
+	}
+}
+
+func TestNgramJaccard(t *testing.T) {
+	if got := NgramJaccard("Alpha Beta Pocket Organizer", "alpha beta pocket organizer!", 2); got != 1 {
+		t.Fatalf("same words should be identical: %v", got)
+	}
+	if got := NgramJaccard("Alpha Beta Pocket Organizer", "Sturdy Storage Box for Alpha Beta", 2); got >= 0.2 {
+		t.Fatalf("different structure should score low: %v", got)
+	}
+	if got := NgramJaccard("alpha", "alpha beta", 2); got != 0.5 {
+		t.Fatalf("short text should fall back to unigrams: %v", got)
+	}
+	if NgramJaccard("", "alpha", 2) != 0 {
+		t.Fatalf("empty text should not match")
+	}
+}
@@ -0,0 +1,264 @@
+package tm
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	FormatTMX = "tmx"
+	FormatCSV = "csv"
+
+	tmxDateLayout = "20060102T150405Z"
+)
+
+// csvHeader 为 CSV 交换格式的列；导入时 updated_at 可省略，列顺序不限。
+var csvHeader = []string{"source_lang", "target_lang", "source", "target", "updated_at"}
+
+// ResolveFormat 返回交换格式：format 非空时以它为准，否则按文件扩展名判断。
+func ResolveFormat(format, path string) (string, error) {
+	f := strings.ToLower(strings.TrimSpace(format))
+	if f == "" {
+		f = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch f {
+	case FormatTMX, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("翻译记忆格式仅支持 tmx/csv：%s", fallbackText(f, path))
+	}
+}
+
+// Export 按格式写出记忆条目。
+func Export(w io.Writer, format string, entries []Entry) error {
+	switch format {
+	case FormatTMX:
+		return writeTMX(w, entries)
+	case FormatCSV:
+		return writeCSV(w, entries)
+	default:
+		return fmt.Errorf("翻译记忆格式仅支持 tmx/csv：%s", format)
+	}
+}
+
+// Import 按格式读取记忆条目。
+func Import(r io.Reader, format string) ([]Entry, error) {
+	switch format {
+	case FormatTMX:
+		return readTMX(r)
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return nil, fmt.Errorf("翻译记忆格式仅支持 tmx/csv：%s", format)
+	}
+}
+
+type tmxDoc struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTMF                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+type tmxUnit struct {
+	SrcLang    string       `xml:"srclang,attr,omitempty"`
+	ChangeDate string       `xml:"changedate,attr,omitempty"`
+	Variants   []tmxVariant `xml:"tuv"`
+}
+
+// tmxVariant 写出时用 xml:lang；读取时 xml:lang 的命名空间会被展开，改从 Attrs 中按本地名 lang 取值（兼容 TMX 1.1 的 lang）。
+type tmxVariant struct {
+	Lang  string     `xml:"xml:lang,attr,omitempty"`
+	Attrs []xml.Attr `xml:",any,attr"`
+	Seg   string     `xml:"seg"`
+}
+
+func (v tmxVariant) lang() string {
+	if v.Lang != "" {
+		return v.Lang
+	}
+	for _, a := range v.Attrs {
+		if a.Name.Local == "lang" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func writeTMX(w io.Writer, entries []Entry) error {
+	doc := tmxDoc{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "syl-listing",
+			CreationToolVersion: "1",
+			SegType:             "sentence",
+			OTMF:                "syl-listing",
+			AdminLang:           "en",
+			SrcLang:             "en",
+			DataType:            "plaintext",
+		},
+	}
+	for _, e := range entries {
+		doc.Units = append(doc.Units, tmxUnit{
+			SrcLang:    e.SourceLang,
+			ChangeDate: tmxDate(e.UpdatedAt),
+			Variants: []tmxVariant{
+				{Lang: e.SourceLang, Seg: e.Source},
+				{Lang: e.TargetLang, Seg: e.Target},
+			},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("写出 TMX 失败：%w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("写出 TMX 失败：%w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// readTMX 以 tu 的 srclang（缺省取 header 的 srclang，再缺省取第一个 tuv）为原文，其余每个 tuv 各成一条译文。
+func readTMX(r io.Reader) ([]Entry, error) {
+	var doc tmxDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析 TMX 失败：%w", err)
+	}
+	var out []Entry
+	for i, tu := range doc.Units {
+		srcLang := NormalizeLang(fallbackText(tu.SrcLang, doc.Header.SrcLang))
+		src := -1
+		for j, v := range tu.Variants {
+			if NormalizeLang(v.lang()) == srcLang {
+				src = j
+				break
+			}
+		}
+		if src < 0 && len(tu.Variants) > 0 && (srcLang == "" || srcLang == "*all*") {
+			src = 0
+		}
+		if src < 0 {
+			return nil, fmt.Errorf("TMX 第%d个 tu 缺少原文语言 %s", i+1, srcLang)
+		}
+		for j, v := range tu.Variants {
+			if j == src {
+				continue
+			}
+			out = append(out, Entry{
+				SourceLang: tu.Variants[src].lang(),
+				TargetLang: v.lang(),
+				Source:     tu.Variants[src].Seg,
+				Target:     v.Seg,
+				UpdatedAt:  fromTMXDate(tu.ChangeDate),
+			})
+		}
+	}
+	return out, nil
+}
+
+func writeCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("写出 CSV 失败：%w", err)
+	}
+	for _, e := range entries {
+		if err := cw.Write([]string{e.SourceLang, e.TargetLang, e.Source, e.Target, e.UpdatedAt}); err != nil {
+			return fmt.Errorf("写出 CSV 失败：%w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("写出 CSV 失败：%w", err)
+	}
+	return nil
+}
+
+func readCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析 CSV 失败：%w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	// Excel 导出的 CSV 常带 UTF-8 BOM。
+	col := map[string]int{}
+	for i, name := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range csvHeader[:4] {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("CSV 表头缺少列 %s（需要 %s）", name, strings.Join(csvHeader, ","))
+		}
+	}
+	get := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	out := make([]Entry, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		out = append(out, Entry{
+			SourceLang: get(row, "source_lang"),
+			TargetLang: get(row, "target_lang"),
+			Source:     get(row, "source"),
+			Target:     get(row, "target"),
+			UpdatedAt:  get(row, "updated_at"),
+		})
+	}
+	return out, nil
+}
+
+// tmxDate 把 RFC3339 时间转为 TMX 的 YYYYMMDDThhmmssZ；无法解析时省略。
+func tmxDate(v string) string {
+	t, ok := parseTime(v)
+	if !ok {
+		return ""
+	}
+	return t.UTC().Format(tmxDateLayout)
+}
+
+func fromTMXDate(v string) string {
+	t, ok := parseTime(v)
+	if !ok {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func parseTime(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	for _, layout := range []string{time.RFC3339, tmxDateLayout} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func fallbackText(v, def string) string {
+	if strings.TrimSpace(v) == "" {
+		return def
+	}
+	return v
+}
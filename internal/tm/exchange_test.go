@@ -0,0 +1,64 @@
+package tm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{SourceLang: "en", TargetLang: "zh", Source: `12" ruler, "metal" & wood`, Target: "12\" 尺子，金属与木头", UpdatedAt: "2026-01-02T03:04:05Z"},
+		{SourceLang: "en", TargetLang: "zh", Source: "alpha", Target: "阿尔法"},
+	}
+	for _, format := range []string{FormatTMX, FormatCSV} {
+		var buf bytes.Buffer
+		if err := Export(&buf, format, entries); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Import(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, entries) {
+			t.Fatalf("%s round trip mismatch: %+v", format, got)
+		}
+	}
+}
+
+func TestImportTMXVariants(t *testing.T) {
+	raw := `<?xml version="1.0"?>
+<tmx version="1.4"><header srclang="en-US"/><body>
+<tu><tuv xml:lang="zh-CN"><seg>桌面收纳盒</seg></tuv><tuv xml:lang="en-US"><seg>Desk organizer</seg></tuv><tuv lang="ja"><seg>デスクオーガナイザー</seg></tuv></tu>
+</body></tmx>`
+	got, err := Import(strings.NewReader(raw), FormatTMX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Source != "Desk organizer" || got[0].TargetLang != "zh-CN" || got[1].TargetLang != "ja" {
+		t.Fatalf("unexpected tmx entries: %+v", got)
+	}
+	if _, err := Import(strings.NewReader(`<tmx><header srclang="fr"/><body><tu><tuv xml:lang="en"><seg>x</seg></tuv></tu></body></tmx>`), FormatTMX); err == nil {
+		t.Fatalf("expected missing source language error")
+	}
+}
+
+func TestImportCSVHeaderAndFormat(t *testing.T) {
+	got, err := Import(strings.NewReader("\ufefftarget,source,source_lang,target_lang\n笔,pen,en,zh\n"), FormatCSV)
+	if err != nil || len(got) != 1 || got[0].Source != "pen" || got[0].Target != "笔" {
+		t.Fatalf("csv columns should be matched by name: %+v %v", got, err)
+	}
+	if _, err := Import(strings.NewReader("source,target\npen,笔\n"), FormatCSV); err == nil || !strings.Contains(err.Error(), "source_lang") {
+		t.Fatalf("expected missing column error, got %v", err)
+	}
+	if f, err := ResolveFormat("", "team.TMX"); err != nil || f != FormatTMX {
+		t.Fatalf("format should follow extension: %q %v", f, err)
+	}
+	if f, err := ResolveFormat("csv", "team.txt"); err != nil || f != FormatCSV {
+		t.Fatalf("explicit format should win: %q %v", f, err)
+	}
+	if _, err := ResolveFormat("", "team.json"); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
@@ -0,0 +1,277 @@
+package tm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"syl-listing/internal/output"
+	"syl-listing/internal/textsim"
+)
+
+// Entry 是一条已确认的译文。
+type Entry struct {
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+}
+
+// Match 是一条相似原文的记忆及其相似度（0～1）。
+type Match struct {
+	Entry
+	Score float64
+}
+
+type Options struct {
+	// FuzzyMinScore 为 Similar 返回的最低相似度。
+	FuzzyMinScore float64
+	// FuzzyHints 为 Similar 最多返回的条数。
+	FuzzyHints int
+}
+
+// Memory 是本地翻译记忆：按规范化原文与语言对存放已确认的译文，可并发读写；nil Memory 等价于关闭。
+type Memory struct {
+	path    string
+	opts    Options
+	mu      sync.RWMutex
+	entries map[string]Entry
+	// changed 记录本进程新增或修改的键，Save 时只用它们覆盖磁盘上的记忆。
+	changed map[string]bool
+}
+
+type memoryFile struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Open 读取记忆文件；文件不存在时返回空记忆，Save 时创建。
+func Open(path string, opts Options) (*Memory, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("翻译记忆路径为空")
+	}
+	entries, err := readEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Memory{path: path, opts: opts, entries: entries, changed: map[string]bool{}}, nil
+}
+
+// readEntries 读取记忆文件中的条目；文件不存在时返回空表。
+func readEntries(path string) (map[string]Entry, error) {
+	entries := map[string]Entry{}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("读取翻译记忆失败（%s）：%w", path, err)
+	}
+	var f memoryFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("翻译记忆格式错误（%s）：%w", path, err)
+	}
+	for _, e := range f.Entries {
+		e.SourceLang, e.TargetLang = NormalizeLang(e.SourceLang), NormalizeLang(e.TargetLang)
+		if k, ok := entryKey(e); ok {
+			entries[k] = e
+		}
+	}
+	return entries, nil
+}
+
+func (m *Memory) Path() string {
+	if m == nil {
+		return ""
+	}
+	return m.path
+}
+
+func (m *Memory) Len() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.entries)
+}
+
+// Lookup 返回与 text 规范化后完全相同的原文的译文。
+func (m *Memory) Lookup(sourceLang, targetLang, text string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	k, ok := entryKey(Entry{SourceLang: sourceLang, TargetLang: targetLang, Source: text})
+	if !ok {
+		return "", false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[k]
+	return e.Target, ok
+}
+
+// Similar 返回同一语言对中与 text 相似（不完全相同）的记忆，按相似度从高到低，最多 FuzzyHints 条。
+func (m *Memory) Similar(sourceLang, targetLang, text string) []Match {
+	if m == nil || m.opts.FuzzyHints <= 0 {
+		return nil
+	}
+	norm := textsim.Normalize(text)
+	if norm == "" {
+		return nil
+	}
+	sourceLang, targetLang = NormalizeLang(sourceLang), NormalizeLang(targetLang)
+	want := textsim.WordSet(norm)
+	m.mu.RLock()
+	var out []Match
+	for _, e := range m.entries {
+		if e.SourceLang != sourceLang || e.TargetLang != targetLang {
+			continue
+		}
+		other := textsim.Normalize(e.Source)
+		if other == norm {
+			continue
+		}
+		if score := textsim.Dice(want, textsim.WordSet(other)); score >= m.opts.FuzzyMinScore {
+			out = append(out, Match{Entry: e, Score: score})
+		}
+	}
+	m.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Source < out[j].Source
+	})
+	if len(out) > m.opts.FuzzyHints {
+		out = out[:m.opts.FuzzyHints]
+	}
+	return out
+}
+
+// Add 记录一条译文，覆盖同一原文的旧译文；返回 added=true 表示新增，changed=false 表示内容未变。
+func (m *Memory) Add(e Entry) (added, changed bool) {
+	if m == nil {
+		return false, false
+	}
+	e.SourceLang, e.TargetLang = NormalizeLang(e.SourceLang), NormalizeLang(e.TargetLang)
+	e.Source, e.Target = strings.TrimSpace(e.Source), strings.TrimSpace(e.Target)
+	k, ok := entryKey(e)
+	if !ok || e.Target == "" {
+		return false, false
+	}
+	if strings.TrimSpace(e.UpdatedAt) == "" {
+		e.UpdatedAt = time.Now().Format(time.RFC3339)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, exists := m.entries[k]
+	if exists && old.Target == e.Target {
+		return false, false
+	}
+	m.entries[k] = e
+	m.changed[k] = true
+	return !exists, true
+}
+
+// Merge 逐条 Add 导入的条目，返回新增、更新与跳过（原文或译文为空、与现有译文相同）的条数。
+func (m *Memory) Merge(entries []Entry) (added, updated, skipped int) {
+	for _, e := range entries {
+		isNew, changed := m.Add(e)
+		switch {
+		case isNew:
+			added++
+		case changed:
+			updated++
+		default:
+			skipped++
+		}
+	}
+	return added, updated, skipped
+}
+
+// Entries 返回全部记忆，按语言对与原文排序。
+func (m *Memory) Entries() []Entry {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sorted()
+}
+
+func (m *Memory) sorted() []Entry {
+	out := make([]Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SourceLang != out[j].SourceLang {
+			return out[i].SourceLang < out[j].SourceLang
+		}
+		if out[i].TargetLang != out[j].TargetLang {
+			return out[i].TargetLang < out[j].TargetLang
+		}
+		return out[i].Source < out[j].Source
+	})
+	return out
+}
+
+// Save 在有改动时原子写回记忆文件。写前重新读取磁盘上的记忆，只用本进程改动的条目覆盖，
+// 避免同时运行的多个进程互相丢失对方写入的条目。
+func (m *Memory) Save() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.changed) == 0 {
+		return nil
+	}
+	onDisk, err := readEntries(m.path)
+	if err != nil {
+		return err
+	}
+	for k := range m.changed {
+		onDisk[k] = m.entries[k]
+	}
+	m.entries = onDisk
+	raw, err := json.MarshalIndent(memoryFile{Version: 1, Entries: m.sorted()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码翻译记忆失败：%w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("创建翻译记忆目录失败：%w", err)
+	}
+	// 记忆在运行结束（含中断）时写回，不随运行取消。
+	if err := output.WriteFilesAtomic(context.Background(), output.File{Path: m.path, Data: append(raw, '\n')}); err != nil {
+		return fmt.Errorf("写入翻译记忆失败：%w", err)
+	}
+	m.changed = map[string]bool{}
+	return nil
+}
+
+// NormalizeLang 把语言代码规范为小写主标签，如 zh-CN → zh。
+func NormalizeLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+func entryKey(e Entry) (string, bool) {
+	src, tgt, norm := NormalizeLang(e.SourceLang), NormalizeLang(e.TargetLang), textsim.Normalize(e.Source)
+	if src == "" || tgt == "" || norm == "" {
+		return "", false
+	}
+	return src + "\x00" + tgt + "\x00" + norm, true
+}
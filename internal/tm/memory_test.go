@@ -0,0 +1,76 @@
+package tm
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryLookupSimilarAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "tm.json")
+	m, err := Open(path, Options{FuzzyMinScore: 0.5, FuzzyHints: 2})
+	if err != nil || m.Len() != 0 {
+		t.Fatalf("missing file should open empty: %v", err)
+	}
+	if added, changed := m.Add(Entry{SourceLang: "en", TargetLang: "zh-CN", Source: "  Desk  Organizer ", Target: "桌面收纳盒"}); !added || !changed {
+		t.Fatalf("first add should be new")
+	}
+	m.Add(Entry{SourceLang: "en", TargetLang: "zh", Source: "Desk organizer with drawers", Target: "带抽屉的桌面收纳盒"})
+	m.Add(Entry{SourceLang: "en", TargetLang: "zh", Source: "Garden hose", Target: "花园水管"})
+	if added, changed := m.Add(Entry{SourceLang: "en", TargetLang: "zh", Source: "desk organizer", Target: "桌面收纳盒"}); added || changed {
+		t.Fatalf("same normalized source and target should be unchanged")
+	}
+	if got, ok := m.Lookup("EN", "zh", "desk ORGANIZER"); !ok || got != "桌面收纳盒" {
+		t.Fatalf("exact lookup should ignore case and spacing: %q %v", got, ok)
+	}
+	if _, ok := m.Lookup("zh", "en", "desk organizer"); ok {
+		t.Fatalf("lookup should respect language pair")
+	}
+	similar := m.Similar("en", "zh", "Wooden desk organizer")
+	if len(similar) != 2 || similar[0].Target != "桌面收纳盒" || similar[0].Score < similar[1].Score {
+		t.Fatalf("unexpected similar matches: %+v", similar)
+	}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(path, Options{})
+	if err != nil || reopened.Len() != 3 {
+		t.Fatalf("reopen failed: %v %d", err, reopened.Len())
+	}
+	if got := reopened.Similar("en", "zh", "Wooden desk organizer"); got != nil {
+		t.Fatalf("zero fuzzy hints should disable similar: %+v", got)
+	}
+	added, updated, skipped := reopened.Merge([]Entry{
+		{SourceLang: "en", TargetLang: "zh", Source: "Garden hose", Target: "园艺水管"},
+		{SourceLang: "en", TargetLang: "zh", Source: "Pen", Target: "笔"},
+		{SourceLang: "en", TargetLang: "zh", Source: "Pen"},
+	})
+	if added != 1 || updated != 1 || skipped != 1 {
+		t.Fatalf("unexpected merge counts: %d %d %d", added, updated, skipped)
+	}
+
+	var nilMemory *Memory
+	if _, ok := nilMemory.Lookup("en", "zh", "x"); ok || nilMemory.Save() != nil || nilMemory.Len() != 0 {
+		t.Fatalf("nil memory should be a no-op")
+	}
+}
+
+func TestMemorySaveMergesConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tm.json")
+	a, _ := Open(path, Options{})
+	b, _ := Open(path, Options{})
+	a.Add(Entry{SourceLang: "en", TargetLang: "zh", Source: "Pen", Target: "笔"})
+	b.Add(Entry{SourceLang: "en", TargetLang: "zh", Source: "Desk", Target: "书桌"})
+	if err := a.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(path, Options{})
+	if err != nil || reopened.Len() != 2 {
+		t.Fatalf("second save should keep the first writer's entries: %v %d", err, reopened.Len())
+	}
+	if got, ok := b.Lookup("en", "zh", "pen"); !ok || got != "笔" {
+		t.Fatalf("save should refresh entries from disk: %q %v", got, ok)
+	}
+}
@@ -14,7 +14,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"syl-listing/internal/cache"
+	"syl-listing/internal/textsim"
+	"syl-listing/internal/tm"
 )
 
 type Request struct {
@@ -29,11 +34,23 @@ type Request struct {
 	Target     string
 	ProjectID  int64
 	UserPrompt string
+	// Instruction 为附加翻译要求，只有 deepseek 会写入 system prompt，腾讯翻译与 mock 忽略。
+	Instruction string
+	// Protect 为 true 时翻译前把 ProtectedTerms（品牌等）、型号与带单位的数值替换为占位符，译后原样还原；
+	// 占位符丢失时返回 ErrPlaceholderMissing。
+	Protect        bool
+	ProtectedTerms []string
 }
 
 type Response struct {
 	Text      string
 	LatencyMS int64
+	// Cached 表示结果来自本地响应缓存，未发起网络请求。
+	Cached bool
+	// Memory 表示结果来自翻译记忆的完全匹配。
+	Memory bool
+	// Deduped 表示复用了同一运行内相同原文正在进行的请求，未单独发请求。
+	Deduped bool
 }
 
 type BatchResponse struct {
@@ -43,6 +60,18 @@ type BatchResponse struct {
 
 type Client struct {
 	httpClient *http.Client
+	cache      *cache.Store
+	memory     *tm.Memory
+
+	flightMu sync.Mutex
+	flights  map[string]*flight
+}
+
+// flight 是一次进行中的无附加要求翻译，供同时请求相同原文的调用方等待复用。
+type flight struct {
+	done chan struct{}
+	resp Response
+	err  error
 }
 
 func NewClient(timeout time.Duration) *Client {
@@ -52,50 +81,268 @@ func NewClient(timeout time.Duration) *Client {
 	return &Client{httpClient: &http.Client{Timeout: timeout}}
 }
 
+// SetTransport 指定发请求用的 Transport（录制或回放）；传 nil 即使用默认 Transport。
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.httpClient.Transport = rt
+}
+
+// SetCache 为 Translate 挂载响应缓存；传 nil 即关闭。
+func (c *Client) SetCache(store *cache.Store) {
+	c.cache = store
+}
+
+// SetMemory 为 Translate 与 TranslateBatch 挂载翻译记忆；传 nil 即关闭。
+// 无附加要求的请求先查完全匹配，命中即不发请求；支持附加要求的 provider 会附带相似原文的已有译文作参考。
+func (c *Client) SetMemory(memory *tm.Memory) {
+	c.memory = memory
+}
+
+type cacheMaterial struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Text     string `json:"text"`
+	// Instruction 与 Protected 为空时省略，保持与旧缓存键一致。
+	Instruction string   `json:"instruction,omitempty"`
+	Protected   []string `json:"protected,omitempty"`
+	// System 为实际发送的 system prompt（不含翻译记忆提示），模板改动后旧缓存随之失效；不发 system prompt 的 provider 为空。
+	System string `json:"system,omitempty"`
+}
+
 func (c *Client) Translate(ctx context.Context, req Request) (Response, error) {
 	provider := normalizeProvider(req.Provider)
+	source, target := normalizeLang(req.Source, req.Target)
+	if c.memory == nil || strings.TrimSpace(req.Instruction) != "" {
+		return c.translate(ctx, req)
+	}
+	if text, ok := c.memory.Lookup(source, target, req.UserPrompt); ok {
+		return Response{Text: text, Memory: true}, nil
+	}
+	key := strings.Join([]string{provider, strings.TrimSpace(req.Model), source, target, textsim.Normalize(req.UserPrompt)}, "\x00")
+	c.flightMu.Lock()
+	if f, ok := c.flights[key]; ok {
+		c.flightMu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return Response{}, ctx.Err()
+		}
+		if f.err != nil {
+			return Response{}, f.err
+		}
+		resp := f.resp
+		resp.Deduped, resp.LatencyMS = true, 0
+		return resp, nil
+	}
+	if c.flights == nil {
+		c.flights = map[string]*flight{}
+	}
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	c.flightMu.Unlock()
+
+	f.resp, f.err = c.translateWithHint(ctx, req, func() string {
+		return memoryHint(c.memory.Similar(source, target, req.UserPrompt))
+	})
+	c.flightMu.Lock()
+	delete(c.flights, key)
+	c.flightMu.Unlock()
+	close(f.done)
+	return f.resp, f.err
+}
+
+// memoryHint 把相似原文的已有译文写成翻译参考。
+func memoryHint(matches []tm.Match) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("以下是翻译记忆中相似原文的已确认译文，仅供参考术语与风格，按当前原文翻译：")
+	for _, m := range matches {
+		fmt.Fprintf(&b, "\n- 原文：%s\n  译文：%s", m.Source, m.Target)
+	}
+	return b.String()
+}
+
+func (c *Client) translate(ctx context.Context, req Request) (Response, error) {
+	return c.translateWithHint(ctx, req, nil)
+}
+
+// translateWithHint 在缓存未命中、真正发请求时才调用 hint，把翻译记忆的相似译文附加到 Instruction；
+// 参考译文不计入缓存键，记忆增长不会让已缓存的翻译失效。
+func (c *Client) translateWithHint(ctx context.Context, req Request, hint func() string) (Response, error) {
+	provider := normalizeProvider(req.Provider)
+	source, target := normalizeLang(req.Source, req.Target)
+	material := cacheMaterial{
+		Provider: provider,
+		Model:    strings.TrimSpace(req.Model),
+		Source:   source,
+		Target:   target,
+		Text:     req.UserPrompt,
+	}
+	if SupportsInstruction(provider) {
+		material.Instruction = strings.TrimSpace(req.Instruction)
+		material.System = deepSeekSystemPrompt(source, target, material.Instruction)
+	}
+	sent, tokens := maskRequest(req)
+	material.Protected = tokens
+	if text, ok := c.cache.Lookup("translate", material); ok {
+		return Response{Text: text, Cached: true}, nil
+	}
+	if hint != nil && SupportsInstruction(provider) {
+		if h := hint(); h != "" {
+			sent.Instruction = strings.TrimSpace(sent.Instruction + "\n" + h)
+		}
+	}
+	var (
+		resp Response
+		err  error
+	)
 	switch provider {
 	case "tencent_tmt":
-		return c.translateTencent(ctx, req)
+		resp, err = c.translateTencent(ctx, sent)
 	case "deepseek":
-		return c.translateDeepSeek(ctx, req)
+		resp, err = c.translateDeepSeek(ctx, sent)
+	case "mock":
+		resp = Response{Text: translateMock(sent.UserPrompt)}
 	default:
 		return Response{}, fmt.Errorf("不支持的翻译 provider：%s", req.Provider)
 	}
+	if err != nil {
+		return Response{}, err
+	}
+	if resp.Text, err = unmaskProtected(resp.Text, tokens); err != nil {
+		return Response{}, err
+	}
+	_ = c.cache.Save("translate", material, resp.Text)
+	return resp, nil
 }
 
+// maskRequest 按 req.Protect 替换受保护内容；有占位符时为 DeepSeek 追加保留占位符的要求。
+func maskRequest(req Request) (Request, []string) {
+	if !req.Protect {
+		return req, nil
+	}
+	var tokens []string
+	req.UserPrompt, tokens = maskProtected(req.UserPrompt, req.ProtectedTerms)
+	if len(tokens) > 0 {
+		req.Instruction = strings.TrimSpace(placeholderInstruction + "\n" + req.Instruction)
+	}
+	return req, tokens
+}
+
+// TranslateBatch 批量翻译非空原文；挂载翻译记忆且无附加要求时，完全匹配的条目不再送翻。
 func (c *Client) TranslateBatch(ctx context.Context, req Request, sourceTexts []string) (BatchResponse, error) {
+	if c.memory == nil || strings.TrimSpace(req.Instruction) != "" {
+		return c.translateBatch(ctx, req, sourceTexts)
+	}
+	source, target := normalizeLang(req.Source, req.Target)
+	var (
+		out     []string
+		misses  []string
+		missIdx []int
+	)
+	for _, t := range sourceTexts {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		if text, ok := c.memory.Lookup(source, target, t); ok {
+			out = append(out, text)
+			continue
+		}
+		missIdx = append(missIdx, len(out))
+		out = append(out, "")
+		misses = append(misses, t)
+	}
+	if len(out) == 0 {
+		return BatchResponse{}, fmt.Errorf("批量翻译输入为空")
+	}
+	if len(misses) == 0 {
+		return BatchResponse{Texts: out}, nil
+	}
+	resp, err := c.translateBatch(ctx, req, misses)
+	if err != nil {
+		return BatchResponse{}, err
+	}
+	for i, idx := range missIdx {
+		out[idx] = resp.Texts[i]
+	}
+	return BatchResponse{Texts: out, LatencyMS: resp.LatencyMS}, nil
+}
+
+func (c *Client) translateBatch(ctx context.Context, req Request, sourceTexts []string) (BatchResponse, error) {
 	texts := make([]string, 0, len(sourceTexts))
+	reqs := make([]Request, 0, len(sourceTexts))
+	tokens := make([][]string, 0, len(sourceTexts))
 	for _, t := range sourceTexts {
 		if strings.TrimSpace(t) != "" {
-			texts = append(texts, t)
+			oneReq := req
+			oneReq.UserPrompt = t
+			masked, toks := maskRequest(oneReq)
+			texts = append(texts, masked.UserPrompt)
+			reqs = append(reqs, masked)
+			tokens = append(tokens, toks)
 		}
 	}
 	if len(texts) == 0 {
 		return BatchResponse{}, fmt.Errorf("批量翻译输入为空")
 	}
 
+	var (
+		out BatchResponse
+		err error
+	)
 	provider := normalizeProvider(req.Provider)
 	switch provider {
 	case "tencent_tmt":
-		return c.translateTencentBatch(ctx, req, texts)
+		out, err = c.translateTencentBatch(ctx, req, texts)
 	case "deepseek":
-		out := make([]string, 0, len(texts))
-		var total int64
-		for _, src := range texts {
-			oneReq := req
-			oneReq.UserPrompt = src
-			resp, err := c.translateDeepSeek(ctx, oneReq)
-			if err != nil {
-				return BatchResponse{}, err
+		out.Texts = make([]string, 0, len(texts))
+		for _, oneReq := range reqs {
+			resp, callErr := c.translateDeepSeek(ctx, oneReq)
+			if callErr != nil {
+				return BatchResponse{}, callErr
 			}
-			total += resp.LatencyMS
-			out = append(out, strings.TrimSpace(resp.Text))
+			out.LatencyMS += resp.LatencyMS
+			out.Texts = append(out.Texts, strings.TrimSpace(resp.Text))
+		}
+	case "mock":
+		out.Texts = make([]string, 0, len(texts))
+		for _, src := range texts {
+			out.Texts = append(out.Texts, translateMock(src))
 		}
-		return BatchResponse{Texts: out, LatencyMS: total}, nil
 	default:
 		return BatchResponse{}, fmt.Errorf("不支持的翻译 provider：%s", req.Provider)
 	}
+	if err != nil {
+		return BatchResponse{}, err
+	}
+	for i := range out.Texts {
+		if out.Texts[i], err = unmaskProtected(out.Texts[i], tokens[i]); err != nil {
+			return BatchResponse{}, fmt.Errorf("第%d条：%w", i+1, err)
+		}
+	}
+	return out, nil
+}
+
+// SupportsInstruction 返回翻译 provider 是否支持 Request.Instruction。
+func SupportsInstruction(provider string) bool {
+	return normalizeProvider(provider) == "deepseek"
+}
+
+// deepSeekSystemPrompt 返回 deepseek 翻译的 system prompt，附加要求追加在末尾。
+func deepSeekSystemPrompt(source, target, instruction string) string {
+	systemPrompt := fmt.Sprintf("你是专业翻译。将用户输入从 %s 翻译到 %s。只输出翻译结果，不要解释。", source, target)
+	if extra := strings.TrimSpace(instruction); extra != "" {
+		systemPrompt += "\n" + extra
+	}
+	return systemPrompt
+}
+
+// translateMock 是离线占位翻译：原文加前缀，便于在 dry-run 与冒烟测试中辨认。
+func translateMock(text string) string {
+	return "【模拟译文】" + strings.TrimSpace(text)
 }
 
 func normalizeProvider(provider string) string {
@@ -125,7 +372,7 @@ func (c *Client) translateDeepSeek(ctx context.Context, req Request) (Response,
 		model = "deepseek-chat"
 	}
 	source, target := normalizeLang(req.Source, req.Target)
-	systemPrompt := fmt.Sprintf("你是专业翻译。将用户输入从 %s 翻译到 %s。只输出翻译结果，不要解释。", source, target)
+	systemPrompt := deepSeekSystemPrompt(source, target, req.Instruction)
 	payload := map[string]any{
 		"model": model,
 		"messages": []map[string]string{
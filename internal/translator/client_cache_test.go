@@ -0,0 +1,76 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"syl-listing/internal/cache"
+)
+
+func TestTranslateUsesResponseCache(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"你好"}}]}`)
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	writer, _ := cache.New(dir, cache.ModeWrite)
+	c := NewClient(0)
+	c.SetCache(writer)
+	req := Request{Provider: "deepseek", Endpoint: ts.URL, APIKey: "k", UserPrompt: "hello"}
+	if resp, err := c.Translate(context.Background(), req); err != nil || resp.Cached {
+		t.Fatalf("write-only first call: %+v %v", resp, err)
+	}
+
+	reader, _ := cache.New(dir, cache.ModeRead)
+	c.SetCache(reader)
+	resp, err := c.Translate(context.Background(), req)
+	if err != nil || !resp.Cached || resp.Text != "你好" {
+		t.Fatalf("expected cached translation: %+v %v", resp, err)
+	}
+	req.Target = "ja"
+	if resp, _ := c.Translate(context.Background(), req); resp.Cached {
+		t.Fatalf("target language must be part of the cache key")
+	}
+	req.Target = ""
+	req.Instruction = "保留数值"
+	if resp, _ := c.Translate(context.Background(), req); resp.Cached {
+		t.Fatalf("instruction must be part of the cache key")
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("expected 3 network calls, got %d", got)
+	}
+}
+
+func TestTranslateCacheKeyIncludesSystemPrompt(t *testing.T) {
+	deepseek := cacheMaterial{Provider: "deepseek", Source: "en", Target: "zh", Text: "hello", System: deepSeekSystemPrompt("en", "zh", "")}
+	withoutSystem := deepseek
+	withoutSystem.System = ""
+	a, _, _ := cache.Key("translate", deepseek)
+	b, _, _ := cache.Key("translate", withoutSystem)
+	if a == b {
+		t.Fatalf("system prompt must be part of the cache key")
+	}
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"你好"}}]}`)
+	}))
+	defer ts.Close()
+	store, _ := cache.New(t.TempDir(), cache.ModeReadWrite)
+	c := NewClient(0)
+	c.SetCache(store)
+	if _, err := c.Translate(context.Background(), Request{Provider: "deepseek", Endpoint: ts.URL, APIKey: "k", UserPrompt: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Lookup("translate", deepseek); !ok {
+		t.Fatalf("deepseek translation should be cached under a key carrying its system prompt")
+	}
+}
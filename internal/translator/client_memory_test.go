@@ -0,0 +1,121 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"syl-listing/internal/cache"
+	"syl-listing/internal/tm"
+)
+
+func TestTranslateUsesTranslationMemory(t *testing.T) {
+	var (
+		calls   int32
+		systems []string
+		mu      sync.Mutex
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		systems = append(systems, body.Messages[0].Content)
+		mu.Unlock()
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"木质桌面收纳盒"}}]}`)
+	}))
+	defer ts.Close()
+
+	memory, err := tm.Open(filepath.Join(t.TempDir(), "tm.json"), tm.Options{FuzzyMinScore: 0.5, FuzzyHints: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory.Add(tm.Entry{SourceLang: "en", TargetLang: "zh", Source: "Desk organizer", Target: "桌面收纳盒"})
+	c := NewClient(0)
+	c.SetMemory(memory)
+	req := Request{Provider: "deepseek", Endpoint: ts.URL, APIKey: "k", UserPrompt: "desk  organizer"}
+	if resp, err := c.Translate(context.Background(), req); err != nil || !resp.Memory || resp.Text != "桌面收纳盒" {
+		t.Fatalf("exact match should be served from memory: %+v %v", resp, err)
+	}
+	if calls != 0 {
+		t.Fatalf("exact match must not call the provider")
+	}
+
+	req.UserPrompt = "Wooden desk organizer"
+	var wg sync.WaitGroup
+	var reused int32
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Translate(context.Background(), req)
+			if err != nil || resp.Text != "木质桌面收纳盒" {
+				t.Errorf("unexpected translation: %+v %v", resp, err)
+			}
+			if resp.Deduped && !resp.Memory {
+				atomic.AddInt32(&reused, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 || reused != 2 {
+		t.Fatalf("identical in-flight requests should share one call: calls=%d reused=%d", calls, reused)
+	}
+	if !strings.Contains(systems[0], "原文：Desk organizer\n  译文：桌面收纳盒") {
+		t.Fatalf("similar memory should be offered as a hint: %s", systems[0])
+	}
+
+	req.UserPrompt, req.Instruction = "Desk organizer", "译文不得超过 4 字"
+	if resp, err := c.Translate(context.Background(), req); err != nil || resp.Memory || calls != 2 {
+		t.Fatalf("requests with instructions must bypass memory: %+v %v", resp, err)
+	}
+
+	batch, err := c.TranslateBatch(context.Background(), Request{Provider: "mock"}, []string{"Desk organizer", " ", "pen"})
+	if err != nil || len(batch.Texts) != 2 || batch.Texts[0] != "桌面收纳盒" || batch.Texts[1] != "【模拟译文】pen" {
+		t.Fatalf("batch should mix memory hits with translated misses: %+v %v", batch, err)
+	}
+}
+
+func TestTranslateMemoryHintStaysOutOfCacheKey(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"木质桌面收纳盒"}}]}`)
+	}))
+	defer ts.Close()
+
+	memory, err := tm.Open(filepath.Join(t.TempDir(), "tm.json"), tm.Options{FuzzyMinScore: 0.3, FuzzyHints: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := cache.New(t.TempDir(), cache.ModeReadWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(0)
+	c.SetMemory(memory)
+	c.SetCache(store)
+	req := Request{Provider: "deepseek", Endpoint: ts.URL, APIKey: "k", UserPrompt: "Wooden desk organizer"}
+	if _, err := c.Translate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	// 记忆增长后相似参考变化，同一原文仍应命中响应缓存。
+	memory.Add(tm.Entry{SourceLang: "en", TargetLang: "zh", Source: "Desk organizer", Target: "桌面收纳盒"})
+	resp, err := c.Translate(context.Background(), req)
+	if err != nil || !resp.Cached || calls != 1 {
+		t.Fatalf("memory growth should not change the cache key: %+v %v calls=%d", resp, err, calls)
+	}
+}
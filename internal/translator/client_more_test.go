@@ -50,3 +50,15 @@ func TestTencentErrorBranches(t *testing.T) {
 		t.Fatalf("expected http error")
 	}
 }
+
+func TestTranslateMockProvider(t *testing.T) {
+	c := NewClient(0)
+	resp, err := c.Translate(context.Background(), Request{Provider: "mock", UserPrompt: " hello "})
+	if err != nil || resp.Text != "【模拟译文】hello" {
+		t.Fatalf("mock translate failed: resp=%+v err=%v", resp, err)
+	}
+	batch, err := c.TranslateBatch(context.Background(), Request{Provider: "mock"}, []string{"a", "", "b"})
+	if err != nil || len(batch.Texts) != 2 || batch.Texts[1] != "【模拟译文】b" {
+		t.Fatalf("mock batch failed: resp=%+v err=%v", batch, err)
+	}
+}
@@ -14,6 +14,9 @@ func TestNormalizeProviderAndLang(t *testing.T) {
 	if normalizeProvider("") != "tencent_tmt" || normalizeProvider("tencent") != "tencent_tmt" || normalizeProvider("deepseek") != "deepseek" {
 		t.Fatalf("normalizeProvider mismatch")
 	}
+	if !SupportsInstruction(" DeepSeek ") || SupportsInstruction("tencent") || SupportsInstruction("mock") {
+		t.Fatalf("SupportsInstruction mismatch")
+	}
 	s, t2 := normalizeLang("", "")
 	if s != "en" || t2 != "zh" {
 		t.Fatalf("normalizeLang mismatch")
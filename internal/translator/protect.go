@@ -0,0 +1,143 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrPlaceholderMissing 表示译文丢失了受保护内容的占位符，调用方应按校验失败处理并重试。
+var ErrPlaceholderMissing = errors.New("译文缺少占位符")
+
+// placeholderInstruction 在有占位符时追加到 DeepSeek 的 system prompt。
+const placeholderInstruction = "文本中形如 {{P0}} 的占位符是不可翻译的品牌、型号或数值，必须原样保留在译文对应位置，不得改写、拆分或删除。"
+
+var (
+	// measureRe 匹配带单位的数值与尺寸，如 10x13 inches、1.5 kg、12"。
+	measureRe = regexp.MustCompile(`(?i)\d+(?:[.,]\d+)?((?:\s*(?:x|×|\*)\s*\d+(?:[.,]\d+)?)*)(?:\s*(inches|inch|in|feet|ft|cm|mm|m|oz|ounces?|lbs?|pounds?|kg|g|ml|l|gallons?|gal|pcs|pieces?|pack|count|ct|mah|w|v)\b|\s*")`)
+	// ambiguousUnits 也是常见英文词或字母，非尺寸时后面紧跟数字或单词就不当作单位，如 2 in 1、3 in a row。
+	ambiguousUnits = map[string]bool{"in": true, "m": true, "l": true, "g": true, "w": true, "v": true}
+	// skuRe 匹配大写字母、数字与连字符组成的词，再由 isSKU 筛出字母数字混合的型号，如 AB-1234、X200。
+	skuRe = regexp.MustCompile(`\b[A-Z0-9]+(?:-[A-Z0-9]+)*\b`)
+	// placeholderRe 容忍译文在占位符内外加入空格或改变大小写。
+	placeholderRe = regexp.MustCompile(`\{\{\s*[Pp]\s*(\d+)\s*\}\}`)
+)
+
+// followedByWord 报告 end 之后（跳过空格）是否紧跟 ASCII 字母或数字。
+func followedByWord(text string, end int) bool {
+	rest := strings.TrimLeft(text[end:], " \t")
+	if rest == "" {
+		return false
+	}
+	c := rest[0] | 0x20
+	return (c >= 'a' && c <= 'z') || (rest[0] >= '0' && rest[0] <= '9')
+}
+
+type protectSpan struct {
+	start, end int
+}
+
+// maskProtected 把受保护内容替换为 {{P0}}、{{P1}}… 占位符，返回替换后的文本与按序号排列的原文片段。
+// 优先级为：受保护词（不区分大小写，长词优先）、带单位的数值、型号；重叠时保留先匹配的片段。
+func maskProtected(text string, terms []string) (string, []string) {
+	var spans []protectSpan
+	taken := func(s, e int) bool {
+		for _, sp := range spans {
+			if s < sp.end && sp.start < e {
+				return true
+			}
+		}
+		return false
+	}
+	add := func(s, e int) {
+		if s < e && !taken(s, e) {
+			spans = append(spans, protectSpan{s, e})
+		}
+	}
+
+	sorted := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t = strings.TrimSpace(t); t != "" {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, t := range sorted {
+		re := regexp.MustCompile(`(?i)` + termBoundary(t, true) + regexp.QuoteMeta(t) + termBoundary(t, false))
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			add(loc[0], loc[1])
+		}
+	}
+	for _, m := range measureRe.FindAllStringSubmatchIndex(text, -1) {
+		if m[4] >= 0 && m[2] == m[3] && ambiguousUnits[strings.ToLower(text[m[4]:m[5]])] && followedByWord(text, m[1]) {
+			continue
+		}
+		add(m[0], m[1])
+	}
+	for _, loc := range skuRe.FindAllStringIndex(text, -1) {
+		if isSKU(text[loc[0]:loc[1]]) {
+			add(loc[0], loc[1])
+		}
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b strings.Builder
+	tokens := make([]string, 0, len(spans))
+	last := 0
+	for i, sp := range spans {
+		b.WriteString(text[last:sp.start])
+		b.WriteString("{{P" + strconv.Itoa(i) + "}}")
+		tokens = append(tokens, text[sp.start:sp.end])
+		last = sp.end
+	}
+	b.WriteString(text[last:])
+	return b.String(), tokens
+}
+
+// termBoundary 为以字母数字开头/结尾的词加单词边界，避免 Pro 命中 Professional。
+func termBoundary(term string, head bool) string {
+	r := term[len(term)-1]
+	if head {
+		r = term[0]
+	}
+	if r < 0x80 && (r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+		return `\b`
+	}
+	return ""
+}
+
+func isSKU(s string) bool {
+	return len(s) >= 3 && strings.ContainsAny(s, "0123456789") && strings.ContainsAny(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+// unmaskProtected 把译文中的占位符还原为原文片段；任一占位符缺失时返回 ErrPlaceholderMissing。
+func unmaskProtected(text string, tokens []string) (string, error) {
+	if len(tokens) == 0 {
+		return text, nil
+	}
+	seen := make([]bool, len(tokens))
+	out := placeholderRe.ReplaceAllStringFunc(text, func(m string) string {
+		idx, err := strconv.Atoi(placeholderRe.FindStringSubmatch(m)[1])
+		if err != nil || idx >= len(tokens) {
+			return m
+		}
+		seen[idx] = true
+		return tokens[idx]
+	})
+	var missing []string
+	for i, ok := range seen {
+		if !ok {
+			missing = append(missing, fmt.Sprintf("{{P%d}}（%s）", i, tokens[i]))
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w：%s", ErrPlaceholderMissing, strings.Join(missing, "、"))
+	}
+	return out, nil
+}
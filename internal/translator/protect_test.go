@@ -0,0 +1,98 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaskAndUnmaskProtected(t *testing.T) {
+	text := `SYL Pro dry erase pockets, 10x13 inches, model AB-1234, pack of 12 pcs, Professional grade, 12" ruler`
+	masked, tokens := maskProtected(text, []string{"Pro", "SYL Pro", " "})
+	want := []string{"SYL Pro", "10x13 inches", "AB-1234", "12 pcs", `12"`}
+	if strings.Join(tokens, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected tokens: %q", tokens)
+	}
+	if masked != "{{P0}} dry erase pockets, {{P1}}, model {{P2}}, pack of {{P3}}, Professional grade, {{P4}} ruler" {
+		t.Fatalf("unexpected masked text: %s", masked)
+	}
+	restored, err := unmaskProtected("{{P0}} 干擦口袋，{{ p1 }}，型号 {{P2}}，{{P3}}，专业级，{{P4}} 尺子", tokens)
+	if err != nil || restored != `SYL Pro 干擦口袋，10x13 inches，型号 AB-1234，12 pcs，专业级，12" 尺子` {
+		t.Fatalf("unexpected restore: %q %v", restored, err)
+	}
+	_, err = unmaskProtected("{{P0}} 干擦口袋", tokens)
+	if !errors.Is(err, ErrPlaceholderMissing) || !strings.Contains(err.Error(), "{{P1}}（10x13 inches）") {
+		t.Fatalf("missing placeholder should fail: %v", err)
+	}
+	if same, toks := maskProtected("plain words", nil); same != "plain words" || toks != nil {
+		t.Fatalf("nothing to protect should keep text: %q %v", same, toks)
+	}
+}
+
+func TestMaskProtectedSkipsWordLikeUnits(t *testing.T) {
+	for text, want := range map[string]string{
+		"2 in 1 organizer":          "",
+		"2-in-1 organizer":          "",
+		"fits 3 in a row":           "",
+		"5 great colors":            "",
+		"cable 2 m, 10 in.":         "2 m|10 in",
+		"board 12 x 18 in wide":     "12 x 18 in",
+		"holds 500 g of paper":      "",
+		"charger 5 v output, 10 v.": "10 v",
+	} {
+		_, tokens := maskProtected(text, nil)
+		if got := strings.Join(tokens, "|"); got != want {
+			t.Fatalf("maskProtected(%q) tokens %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestTranslateProtectsTokens(t *testing.T) {
+	var systems, users []string
+	drop := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		systems = append(systems, req.Messages[0].Content)
+		users = append(users, req.Messages[1].Content)
+		out := "中:" + req.Messages[1].Content
+		if drop {
+			out = "中:没有占位符"
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, out)
+	}))
+	defer ts.Close()
+
+	c := NewClient(0)
+	req := Request{Provider: "deepseek", Endpoint: ts.URL, APIKey: "k", UserPrompt: "BrandX box, 10 x 13 cm", Protect: true, ProtectedTerms: []string{"brandx"}}
+	resp, err := c.Translate(context.Background(), req)
+	if err != nil || resp.Text != "中:BrandX box, 10 x 13 cm" {
+		t.Fatalf("protected translate failed: %+v %v", resp, err)
+	}
+	if users[0] != "{{P0}} box, {{P1}}" || !strings.Contains(systems[0], "{{P0}}") {
+		t.Fatalf("placeholders should be sent with instruction: %q / %q", users[0], systems[0])
+	}
+
+	batch, err := c.TranslateBatch(context.Background(), Request{Provider: "mock", Protect: true, ProtectedTerms: []string{"BrandX"}}, []string{"BrandX 2 kg", "plain"})
+	if err != nil || batch.Texts[0] != "【模拟译文】BrandX 2 kg" || batch.Texts[1] != "【模拟译文】plain" {
+		t.Fatalf("protected batch failed: %+v %v", batch, err)
+	}
+
+	drop = true
+	if _, err := c.Translate(context.Background(), req); !errors.Is(err, ErrPlaceholderMissing) {
+		t.Fatalf("dropped placeholder should fail: %v", err)
+	}
+	req.Protect = false
+	if _, err := c.Translate(context.Background(), req); err != nil || users[len(users)-1] != req.UserPrompt {
+		t.Fatalf("unprotected request should send raw text: %v %q", err, users[len(users)-1])
+	}
+}